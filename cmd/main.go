@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -8,21 +10,29 @@ import (
 	"go-microservice-boilerplate/internal/config"
 	"go-microservice-boilerplate/internal/database"
 	"go-microservice-boilerplate/internal/services/gateway"
+	"go-microservice-boilerplate/internal/services/gateway/client"
+	"go-microservice-boilerplate/internal/services/order"
 	"go-microservice-boilerplate/internal/services/product"
 	"go-microservice-boilerplate/internal/services/user"
+	"go-microservice-boilerplate/internal/services/webhook"
 	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/pkg/money"
 )
 
 // @title Go Microservice Boilerplate API
 // @version 1.0
-// @description Microservice Boilerplate API for user and product management
+// @description Microservice Boilerplate API for user, product and order management and webhooks
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	// Parse command line arguments
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: go run cmd/main.go <service>")
-		fmt.Println("Available services: web, user, product")
+		fmt.Println("Available services: web, user, product, order, webhook")
+		fmt.Println("Available commands: reconcile-stock, backfill-ledger, migrate-prices [currency], import products --file <catalog.csv|json> [--dry-run]")
 		os.Exit(1)
 	}
 
@@ -58,9 +68,26 @@ func main() {
 		runUserService(cfg, *mongodb, *redisClient)
 	case "product":
 		runProductService(cfg, *mongodb, *redisClient)
+	case "order":
+		runOrderService(cfg, *mongodb, *redisClient)
+	case "webhook":
+		runWebhookService(cfg, *mongodb, *redisClient)
+	case "reconcile-stock":
+		runStockReconciliation(*mongodb)
+	case "backfill-ledger":
+		runLedgerBackfill(*mongodb)
+	case "migrate-prices":
+		currency := money.DefaultCurrency
+		if len(os.Args) > 2 {
+			currency = os.Args[2]
+		}
+		runPriceMigration(*mongodb, currency)
+	case "import":
+		runImport(cfg, os.Args[2:])
 	default:
 		fmt.Printf("Unknown service: %s\n", service)
-		fmt.Println("Available services: web, user, product")
+		fmt.Println("Available services: web, user, product, order, webhook")
+		fmt.Println("Available commands: reconcile-stock, backfill-ledger, migrate-prices [currency], import products --file <catalog.csv|json> [--dry-run]")
 		os.Exit(1)
 	}
 }
@@ -91,3 +118,81 @@ func runProductService(cfg *config.Config, mongodb database.MongoDB, redis datab
 		log.Fatal("Failed to start product service:", err)
 	}
 }
+
+func runOrderService(cfg *config.Config, mongodb database.MongoDB, redis database.Redis) {
+	logger.Info("Starting Order Service...")
+
+	orderServer := order.NewServer(cfg, &mongodb, &redis)
+	if err := orderServer.Start(); err != nil {
+		log.Fatal("Failed to start order service:", err)
+	}
+}
+
+func runWebhookService(cfg *config.Config, mongodb database.MongoDB, redis database.Redis) {
+	logger.Info("Starting Webhook Service...")
+
+	webhookServer := webhook.NewServer(cfg, &mongodb, &redis)
+	if err := webhookServer.Start(); err != nil {
+		log.Fatal("Failed to start webhook service:", err)
+	}
+}
+
+func runStockReconciliation(mongodb database.MongoDB) {
+	drifted, err := product.ReconcileStock(context.Background(), &mongodb, os.Stdout)
+	if err != nil {
+		log.Fatal("Failed to reconcile stock:", err)
+	}
+
+	// Exit non-zero so scheduled runs can alert on drift
+	if drifted > 0 {
+		mongodb.Disconnect()
+		os.Exit(2)
+	}
+}
+
+func runLedgerBackfill(mongodb database.MongoDB) {
+	if _, err := product.BackfillLedger(context.Background(), &mongodb, os.Stdout); err != nil {
+		log.Fatal("Failed to backfill the stock ledger:", err)
+	}
+}
+
+func runPriceMigration(mongodb database.MongoDB, currency string) {
+	if _, err := product.MigratePrices(context.Background(), &mongodb, currency, os.Stdout); err != nil {
+		log.Fatal("Failed to migrate prices:", err)
+	}
+}
+
+func runImport(cfg *config.Config, args []string) {
+	if len(args) == 0 || args[0] != "products" {
+		log.Fatal("Usage: go run cmd/main.go import products --file <catalog.csv|json> [--dry-run]")
+	}
+
+	flags := flag.NewFlagSet("import products", flag.ExitOnError)
+	file := flags.String("file", "", "CSV or JSON file of products to import")
+	dryRun := flags.Bool("dry-run", false, "validate every row without writing anything")
+	flags.Parse(args[1:])
+	if *file == "" {
+		log.Fatal("Usage: go run cmd/main.go import products --file <catalog.csv|json> [--dry-run]")
+	}
+
+	productClient, err := client.NewProductClient(cfg)
+	if err != nil {
+		log.Fatal("Failed to create product client:", err)
+	}
+	defer productClient.Close()
+
+	stream, err := productClient.ImportProducts(context.Background())
+	if err != nil {
+		log.Fatal("Failed to start import:", err)
+	}
+	failed, err := product.ImportProducts(stream, *file, *dryRun, os.Stdout)
+	if err != nil {
+		log.Fatal("Failed to import products:", err)
+	}
+
+	// Exit non-zero so scripted imports notice rejected rows
+	if failed > 0 {
+		productClient.Close()
+		os.Exit(2)
+	}
+}
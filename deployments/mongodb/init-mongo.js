@@ -4,17 +4,96 @@ db = db.getSiblingDB('microservices_db');
 // Create collections
 db.createCollection('users');
 db.createCollection('products');
+db.createCollection('categories');
+db.createCollection('stock_reservations');
+db.createCollection('stock_movements');
+db.createCollection('stock_alerts');
 
 // Create indexes for users collection
 db.users.createIndex({ "email": 1 }, { unique: true });
 db.users.createIndex({ "created_at": 1 });
-db.users.createIndex({ "name": "text", "email": "text" });
+db.users.createIndex({ "created_at": -1, "_id": -1 });
+db.users.createIndex(
+    { "name": "text", "email": "text" },
+    { name: "users_text", weights: { name: 5, email: 10 } }
+);
 
 // Create indexes for products collection
 db.products.createIndex({ "sku": 1 }, { unique: true });
 db.products.createIndex({ "category": 1 });
 db.products.createIndex({ "created_at": 1 });
-db.products.createIndex({ "name": "text", "description": "text" });
+db.products.createIndex({ "created_at": -1, "_id": -1 });
+db.products.createIndex({ "category": 1, "price.amount": 1, "_id": 1 });
+db.products.createIndex({ "price.amount": 1, "_id": 1 });
+db.products.createIndex({ "quantity": 1, "_id": 1 });
+db.products.createIndex({ "updated_at": -1, "_id": -1 });
+db.products.createIndex(
+    { "name": "text", "sku": "text", "description": "text" },
+    { name: "products_text", weights: { name: 10, sku: 10, description: 2 } }
+);
+db.products.createIndex(
+    { "variants.sku": 1 },
+    { unique: true, partialFilterExpression: { "variants.sku": { $exists: true } } }
+);
+db.products.createIndex({ "attributes.$**": 1 });
+db.products.createIndex({ "status": 1, "created_at": -1, "_id": -1 });
+db.products.createIndex(
+    { "publish_at": 1 },
+    { partialFilterExpression: { status: "draft", publish_at: { $exists: true } } }
+);
+db.products.createIndex(
+    { "unpublish_at": 1 },
+    { partialFilterExpression: { unpublish_at: { $exists: true } } }
+);
+db.products.createIndex(
+    { "media.thumbnail_status": 1 },
+    { partialFilterExpression: { "media.thumbnail_status": "pending" } }
+);
+
+// Create indexes for categories collection
+db.categories.createIndex({ "path": 1 }, { unique: true });
+db.categories.createIndex({ "parent_id": 1, "sort_order": 1, "name": 1 });
+db.categories.createIndex({ "depth": 1, "sort_order": 1, "name": 1 });
+
+// Create indexes for inventory collections
+db.stock_reservations.createIndex({ "status": 1, "expires_at": 1 });
+db.stock_movements.createIndex({ "product_id": 1, "created_at": -1 });
+db.stock_movements.createIndex({ "product_id": 1, "reason": 1, "created_at": -1 });
+db.stock_movements.createIndex({ "product_id": 1, "variant_id": 1, "created_at": -1 });
+db.stock_movements.createIndex({ "reservation_id": 1 }, { sparse: true });
+db.stock_movements.createIndex({ "correlation_id": 1 }, { sparse: true });
+db.stock_alerts.createIndex(
+    { "product_id": 1 },
+    { unique: true, partialFilterExpression: { status: "open" } }
+);
+db.stock_alerts.createIndex({ "status": 1, "created_at": -1 });
+db.products.createIndex(
+    { "reorder_threshold": 1 },
+    { partialFilterExpression: { reorder_threshold: { $gt: 0 } } }
+);
+
+// Create indexes for orders collection
+db.orders.createIndex({ "user_id": 1, "created_at": -1, "_id": -1 });
+db.orders.createIndex({ "status": 1, "created_at": -1, "_id": -1 });
+db.orders.createIndex({ "created_at": -1, "_id": -1 });
+
+// Create indexes for checkouts collection
+db.checkouts.createIndex({ "user_id": 1, "idempotency_key": 1 }, { unique: true });
+db.checkouts.createIndex({ "status": 1, "locked_until": 1 });
+
+// Create indexes for outbox collection; published events are kept for a week
+db.outbox.createIndex({ "published": 1, "_id": 1 });
+db.outbox.createIndex({ "published_at": 1 }, { expireAfterSeconds: 604800 });
+
+// Create indexes for webhook collections; the delivery log is kept for 30 days
+db.webhook_endpoints.createIndex({ "enabled": 1, "event_types": 1 });
+db.webhook_deliveries.createIndex(
+    { "endpoint_id": 1, "event.id": 1 },
+    { unique: true, partialFilterExpression: { "replay_of": { $exists: false } } }
+);
+db.webhook_deliveries.createIndex({ "endpoint_id": 1, "created_at": -1, "_id": -1 });
+db.webhook_deliveries.createIndex({ "status": 1, "next_attempt_at": 1 });
+db.webhook_deliveries.createIndex({ "created_at": 1 }, { expireAfterSeconds: 2592000 });
 
 // Insert sample data
 db.users.insertMany([
@@ -34,24 +113,73 @@ db.users.insertMany([
     }
 ]);
 
+const electronics = ObjectId();
+db.categories.insertMany([
+    {
+        _id: electronics,
+        name: "Electronics",
+        slug: "electronics",
+        path: "electronics",
+        depth: 0,
+        sort_order: 0,
+        attributes: [
+            { name: "weight", type: "number", required: false, unit: "kg" }
+        ],
+        created_at: new Date(),
+        updated_at: new Date()
+    },
+    {
+        name: "Laptops",
+        slug: "laptops",
+        parent_id: electronics,
+        path: "electronics/laptops",
+        depth: 1,
+        sort_order: 0,
+        attributes: [
+            { name: "ram", type: "number", required: true, unit: "GB" }
+        ],
+        created_at: new Date(),
+        updated_at: new Date()
+    },
+    {
+        name: "Accessories",
+        slug: "accessories",
+        parent_id: electronics,
+        path: "electronics/accessories",
+        depth: 1,
+        sort_order: 1,
+        attributes: [
+            { name: "connectivity", type: "enum", required: false, values: ["wired", "wireless"] }
+        ],
+        created_at: new Date(),
+        updated_at: new Date()
+    }
+]);
+
 db.products.insertMany([
     {
         name: "Laptop",
         description: "High-performance laptop for developers",
-        price: 1299.99,
+        price: { amount: 129999, currency: "USD" },
         quantity: 50,
-        category: "Electronics",
+        category: "electronics/laptops",
         sku: "LAP001",
+        attributes: { weight: 1.6, ram: 16 },
+        status: "published",
+        published_at: new Date(),
         created_at: new Date(),
         updated_at: new Date()
     },
     {
         name: "Mouse",
         description: "Wireless optical mouse",
-        price: 29.99,
+        price: { amount: 2999, currency: "USD" },
         quantity: 200,
-        category: "Electronics",
+        category: "electronics/accessories",
         sku: "MOU001",
+        attributes: { weight: 0.09, connectivity: "wireless" },
+        status: "published",
+        published_at: new Date(),
         created_at: new Date(),
         updated_at: new Date()
     }
@@ -15,6 +15,331 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/cart": {
+            "get": {
+                "description": "Get the cart of the caller with live product names, prices and stock. Items whose price changed since they were added have price_changed set; unpublished products and missing SKUs are flagged as unavailable. Anonymous callers identify their cart with the X-Cart-ID header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cart"
+                ],
+                "summary": "Get Cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of an anonymous cart",
+                        "name": "X-Cart-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Remove every item from the cart",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cart"
+                ],
+                "summary": "Clear Cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of an anonymous cart",
+                        "name": "X-Cart-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/cart/items": {
+            "post": {
+                "description": "Add a quantity of a product SKU, or of one of its variants, to the cart. The first item sets the cart currency. Anonymous callers without X-Cart-ID get a new cart whose token is returned in the X-Cart-ID response header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cart"
+                ],
+                "summary": "Add Cart Item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of an anonymous cart",
+                        "name": "X-Cart-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/cart/items/{product_id}/{sku}": {
+            "put": {
+                "description": "Set the quantity of a cart item; zero removes it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cart"
+                ],
+                "summary": "Update Cart Item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "SKU of the product or variant",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of an anonymous cart",
+                        "name": "X-Cart-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Remove an item from the cart",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cart"
+                ],
+                "summary": "Remove Cart Item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "SKU of the product or variant",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of an anonymous cart",
+                        "name": "X-Cart-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/cart/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move the items of the anonymous cart named by X-Cart-ID into the cart of the logged-in caller, typically right after login. Quantities of items in both carts add up and the anonymous cart is deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cart"
+                ],
+                "summary": "Merge Carts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of the anonymous cart",
+                        "name": "X-Cart-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "List the children of a category, or the top level, ordered by depth, sort order and name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "List Categories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Parent category ID, the top level when omitted",
+                        "name": "parent_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include every descendant instead of direct children only",
+                        "name": "recursive",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "description": "Create a category, optionally below a parent. The slug is derived from the name when omitted and the path joins the slugs of the category and its ancestors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Create Category",
+                "responses": {}
+            }
+        },
+        "/categories/{id}": {
+            "get": {
+                "description": "Get category by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Get Category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "description": "Rename, reorder or move a category. Changing the slug or parent rewrites the paths of its subcategories and products.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Update Category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Delete a category. Categories that still have subcategories or products cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Delete Category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/checkout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Buy the items of the body, or the whole cart of the caller when the body has none. Stock is reserved, a pending order created and the payment authorized, then every step is committed, or rolled back when one fails. Retrying with the same Idempotency-Key returns the same checkout instead of buying twice. Answers 201 when the checkout completed, 409 when it failed and 202 when it is still running; poll GET /checkout/{id} until it finishes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checkout"
+                ],
+                "summary": "Checkout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key of this purchase, chosen by the client",
+                        "name": "Idempotency-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/checkout/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a checkout of the caller, to follow one that was still running",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checkout"
+                ],
+                "summary": "Get Checkout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Checkout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
         "/health": {
             "get": {
                 "description": "Check the health status of the gateway service",
@@ -22,54 +347,1108 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Health"
+                ],
+                "summary": "Health Check",
+                "responses": {}
+            }
+        },
+        "/inventory/reservations": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Hold stock of one or more products for a checkout. The stock is released automatically once ttl_seconds have passed without a commit. Requires the catalog_manager or admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Reserve Stock",
+                "responses": {}
+            }
+        },
+        "/inventory/reservations/{id}/commit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Finalise an active reservation so its stock is never released. Requires the catalog_manager or admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Commit Reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/inventory/reservations/{id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Hand the stock held by an active reservation back to its products. Requires the catalog_manager or admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Release Reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the orders of the caller, newest first. Admins see every order unless they filter by user_id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "List Orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only orders of this user (admins only)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only orders with this status (pending, cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Orders per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_page_token of the previous page",
+                        "name": "page_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How to count the total: exact, estimated or none",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Place an order for the caller. Each item names a product and the SKU of the product or one of its variants; names and retail prices are copied into the order. Admins may order on behalf of another user with user_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Create Order",
+                "responses": {}
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an order of the caller, or any order for admins",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get Order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/orders/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel a pending order of the caller, or any pending order for admins",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Cancel Order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Get paginated list of products. Pass next_page_token back as page_token for keyset pagination. Only published products are listed unless the caller is a catalog manager. Pass ids instead to fetch up to 100 products by ID in one call, returned in the order requested with the IDs that were not found in missing_ids; only the price parameters apply then.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List Products",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page's next_page_token",
+                        "name": "page_token",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "estimated",
+                            "none"
+                        ],
+                        "type": "string",
+                        "description": "Total count mode",
+                        "name": "total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search over name, SKU and description",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated exact categories",
+                        "name": "categories",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category path including its subcategories, e.g. electronics",
+                        "name": "category_tree",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "relevance",
+                            "created_at",
+                            "updated_at",
+                            "name",
+                            "price",
+                            "quantity"
+                        ],
+                        "type": "string",
+                        "description": "Sort field, relevance by default when searching",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort direction",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Minimum price as a decimal amount, e.g. 19.99",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum price as a decimal amount",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "USD",
+                        "description": "Currency of the price filters, and of the products listed when sorting by price",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only products with stock",
+                        "name": "in_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time or unix seconds",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time or unix seconds",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "retail",
+                        "description": "Price list of the display prices",
+                        "name": "price_list",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency of the display prices",
+                        "name": "price_currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "draft",
+                            "published",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "description": "Lifecycle state, catalog managers only for draft and archived",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Products whose attribute equals the value, e.g. attr.color=red",
+                        "name": "attr.{name}",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Products whose numeric attribute is at least the value",
+                        "name": "attr.{name}.min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Products whose numeric attribute is at most the value",
+                        "name": "attr.{name}.max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated product IDs to fetch",
+                        "name": "ids",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "description": "Create a new product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Create Product",
+                "responses": {}
+            }
+        },
+        "/products/export": {
+            "get": {
+                "description": "Download every product matching the filters as a JSON array, NDJSON or CSV, selected by the format parameter or the Accept header. Takes the same filters as listing products. The CSV columns can be imported again.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Export Products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export format: json, ndjson or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search term for name or description",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by a category and all its subcategories",
+                        "name": "category_tree",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status, catalog managers only for unpublished products",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency to price products in",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/low-stock": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Products whose quantity has fallen below their reorder threshold, emptiest first. Requires the catalog_manager or admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "List Low Stock Products",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/search": {
+            "get": {
+                "description": "Search products and return facet counts for categories, price buckets and stock. Each facet ignores its own filter so the counts describe the alternatives.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Search Products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Full-text search over name, SKU and description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated exact categories",
+                        "name": "categories",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "relevance",
+                            "created_at",
+                            "updated_at",
+                            "name",
+                            "price",
+                            "quantity"
+                        ],
+                        "type": "string",
+                        "description": "Sort field, relevance by default when searching",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort direction",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Minimum price as a decimal amount, e.g. 19.99",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum price as a decimal amount",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "USD",
+                        "description": "Currency of the price filters and buckets, and of the products found when sorting by price",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only products with stock",
+                        "name": "in_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated ascending price bucket boundaries as decimal amounts",
+                        "name": "price_boundaries",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/suggest": {
+            "get": {
+                "description": "Typeahead suggestions of product names and SKUs starting with the query",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Suggest Products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name or SKU prefix",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Maximum suggestions",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/watch": {
+            "get": {
+                "description": "Stream changes of products as Server-Sent Events until the client disconnects. The first event, of type watching, confirms the watch; created, updated and deleted events follow with the product after the change as data. Every event id is a resume token: EventSource sends the last one as Last-Event-ID when it reconnects, and the stream continues after that change. Catalog managers also see drafts and archived products; everyone else gets a deleted event when a product is unpublished. A resume token too old to continue from is rejected with 409, after which clients should reload and watch again without it.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Watch Products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated IDs of the products to watch, at most 100",
+                        "name": "ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Watch products of this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Watch products of a category and all its subcategories",
+                        "name": "category_tree",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after the change with this token, instead of the Last-Event-ID header",
+                        "name": "resume_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/watch/ws": {
+            "get": {
+                "description": "Stream changes of products over a WebSocket until either side closes it. Every message is a JSON product change, starting with one of type watching; errors arrive as a message of type error right before the connection closes. Takes the same filters as watching products with Server-Sent Events; reconnecting clients pass the resume_token of the last change they received.",
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Watch Products over WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated IDs of the products to watch, at most 100",
+                        "name": "ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Watch products of this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Watch products of a category and all its subcategories",
+                        "name": "category_tree",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after the change with this token",
+                        "name": "resume_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Get product by ID. Pass price_list or price_currency to get a display_price, converted at the current exchange rate when no price is stored in that currency. Drafts and archived products are only found by catalog managers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get Product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "retail",
+                        "description": "Price list of the display price",
+                        "name": "price_list",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency of the display price",
+                        "name": "price_currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "description": "Update product information. Only catalog managers can change the status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Update Product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Delete product by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Delete Product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "patch": {
+                "description": "Partially update a product with a JSON merge patch. Only the fields in the body are written, and null or empty values clear them, e.g. {\"description\": null}. Attributes are merged one by one, a null attribute is removed; price, price lists and variants are replaced as a whole. Only catalog managers can change the status.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Patch Product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/{id}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Archive a product right away, or schedule it to be unpublished at a future time. Requires the catalog_manager or admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Archive Product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unpublish time (RFC3339 or unix seconds), now when omitted",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/{id}/media": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload one or more JPEG, PNG or GIF images of a product as multipart form data. The type is detected from the file content, not its name. Thumbnails are generated in the background. Requires the catalog_manager or admin role.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Upload Product Media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file, repeat the field to upload several",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/{id}/media/{media_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove an image from a product and delete its files. Requires the catalog_manager or admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Delete Product Media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Media ID",
+                        "name": "media_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/{id}/publish": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Publish a draft right away, or schedule it to be published at a future time. Archived products move back to draft before they can be published. Requires the catalog_manager or admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Publish Product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Publish time (RFC3339 or unix seconds), now when omitted",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/{id}/stock-movements": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ledger of every stock change of a product, newest first, with the delta, reason, actor, correlation ID and resulting balance. Requires the catalog_manager or admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "List Stock Movements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only movements of this variant",
+                        "name": "variant_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only movements at or after this time (RFC3339 or unix seconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only movements before this time (RFC3339 or unix seconds)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "initial",
+                            "sale",
+                            "restock",
+                            "adjustment",
+                            "reservation",
+                            "release"
+                        ],
+                        "type": "string",
+                        "description": "Filter by reason",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token from a previous page",
+                        "name": "page_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/products/{id}/stock/adjust": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Atomically add to or remove from the stock of a product. The reason is one of sale, restock or adjustment (the default). Removals fail with 409 when not enough stock is left. Pass variant_id to adjust the stock of a variant. Requires the catalog_manager or admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Adjust Product Stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Health Check",
                 "responses": {}
             }
         },
-        "/products": {
+        "/users": {
             "get": {
-                "description": "Get paginated list of products",
+                "description": "Get paginated list of users. Pass next_page_token back as page_token for keyset pagination. Pass ids instead to fetch up to 100 users by ID in one call, returned in the order requested with the IDs that were not found in missing_ids.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Users"
                 ],
-                "summary": "List Products",
+                "summary": "List Users",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 1,
                         "description": "Page number",
                         "name": "page",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "default": 10,
                         "description": "Items per page",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Search term",
+                        "description": "Cursor from a previous page's next_page_token",
+                        "name": "page_token",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "estimated",
+                            "none"
+                        ],
+                        "type": "string",
+                        "description": "Total count mode",
+                        "name": "total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search, ranked by relevance",
                         "name": "search",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Category filter",
-                        "name": "category",
+                        "description": "Comma-separated user IDs to fetch, ignoring the other parameters",
+                        "name": "ids",
                         "in": "query"
                     }
                 ],
                 "responses": {}
             },
             "post": {
-                "description": "Create a new product",
+                "description": "Create a new user account",
                 "consumes": [
                     "application/json"
                 ],
@@ -77,26 +1456,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Users"
                 ],
-                "summary": "Create Product",
+                "summary": "Create User",
                 "responses": {}
             }
         },
-        "/products/{id}": {
+        "/users/export": {
+            "get": {
+                "description": "Download every user matching the filters as a JSON array, NDJSON or CSV, selected by the format parameter or the Accept header. The response is streamed, so exports are not bound by the list limit.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Export Users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export format: json, ndjson or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search term for name or email",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/{id}": {
             "get": {
-                "description": "Get product by ID",
+                "description": "Get user by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Users"
                 ],
-                "summary": "Get Product",
+                "summary": "Get User",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -105,7 +1513,7 @@ const docTemplate = `{
                 "responses": {}
             },
             "put": {
-                "description": "Update product information",
+                "description": "Update user information",
                 "consumes": [
                     "application/json"
                 ],
@@ -113,13 +1521,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Users"
                 ],
-                "summary": "Update Product",
+                "summary": "Update User",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -128,60 +1536,73 @@ const docTemplate = `{
                 "responses": {}
             },
             "delete": {
-                "description": "Delete product by ID",
+                "description": "Delete user by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Users"
                 ],
-                "summary": "Delete Product",
+                "summary": "Delete User",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {}
-            }
-        },
-        "/users": {
-            "get": {
-                "description": "Get paginated list of users",
+            },
+            "patch": {
+                "description": "Partially update a user with a JSON merge patch. Only the fields in the body are written, and null or empty values clear them, e.g. {\"phone\": null}.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "List Users",
+                "summary": "Patch User",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page",
-                        "name": "limit",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Search term",
-                        "name": "search",
-                        "in": "query"
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
                     }
                 ],
+                "description": "List every registered webhook endpoint, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List Webhooks",
                 "responses": {}
             },
             "post": {
-                "description": "Create a new user account",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register an endpoint that receives user and product events. event_types lists event types such as product.updated, user.* or product.* for every event of an aggregate, or * for every event. A signing secret is generated unless one is given; it is only returned in this response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -189,26 +1610,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Webhooks"
                 ],
-                "summary": "Create User",
+                "summary": "Create Webhook",
                 "responses": {}
             }
         },
-        "/users/{id}": {
+        "/webhooks/{id}": {
             "get": {
-                "description": "Get user by ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a webhook endpoint, including whether it was disabled after failing deliveries",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Webhooks"
                 ],
-                "summary": "Get User",
+                "summary": "Get Webhook",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -216,8 +1642,38 @@ const docTemplate = `{
                 ],
                 "responses": {}
             },
-            "put": {
-                "description": "Update user information",
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a webhook endpoint. Its pending deliveries fail instead of being sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the url, secret, event_types, description or enabled flag of a webhook endpoint; fields left out are kept. Setting enabled to true re-enables an endpoint disabled after failing deliveries and clears its failures.",
                 "consumes": [
                     "application/json"
                 ],
@@ -225,41 +1681,119 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Webhooks"
                 ],
-                "summary": "Update User",
+                "summary": "Update Webhook",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {}
-            },
-            "delete": {
-                "description": "Delete user by ID",
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the delivery log of a webhook endpoint, newest first, with every attempt's status code and error",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Webhooks"
                 ],
-                "summary": "Delete User",
+                "summary": "List Webhook Deliveries",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only deliveries with this status (pending, delivered, failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Deliveries per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_page_token of the previous page",
+                        "name": "page_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How to count the total: exact, estimated or none",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery_id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send the event of a delivered or failed delivery again. The replay is a new delivery with replay_of set, retried like any other.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Replay Webhook Delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {}
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -270,7 +1804,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/api/v1",
 	Schemes:          []string{},
 	Title:            "Go Microservice Boilerplate API",
-	Description:      "Microservice Boilerplate API for user and product management",
+	Description:      "Microservice Boilerplate API for user, product and order management and webhooks",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,62 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"go-microservice-boilerplate/internal/config"
+)
+
+// LocalURLPrefix is where the gateway serves blobs of the local store when no
+// public URL is configured
+const LocalURLPrefix = "/media"
+
+// ErrNotFound is returned when no blob is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps binary objects, such as product images, under slash
+// separated keys
+type BlobStore interface {
+	// Put streams size bytes from r into the blob stored under key,
+	// replacing any previous blob
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob, deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+	// URL returns where clients download the blob
+	URL(key string) string
+}
+
+// New creates the blob store selected by the configuration
+func New(cfg config.BlobStoreConfig) (BlobStore, error) {
+	switch cfg.Provider {
+	case "local":
+		publicURL := cfg.PublicURL
+		if publicURL == "" {
+			publicURL = LocalURLPrefix
+		}
+		return NewLocalStore(cfg.LocalDir, publicURL)
+	case "s3":
+		return NewS3Store(cfg.S3, cfg.PublicURL, time.Duration(cfg.S3.Timeout)*time.Second)
+	default:
+		return nil, fmt.Errorf("unknown blob store provider %q", cfg.Provider)
+	}
+}
+
+// validKey rejects keys that are empty, absolute or escape their store
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
+
+// joinURL appends a key to a base URL
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
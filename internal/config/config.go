@@ -8,7 +8,7 @@ import (
 func Load() (*Config, error) {
 	return &Config{
 		MongoDB: MongoDBConfig{
-			URI:      getEnv("MONGODB_URI", "mongodb://localhost:27017"),
+			URI:      getEnv("MONGODB_URI", "mongodb://localhost:27017/?directConnection=true"),
 			Database: getEnv("MONGODB_DATABASE", "microservices_db"),
 			Timeout:  getEnvInt("MONGODB_TIMEOUT", 30),
 		},
@@ -29,6 +29,14 @@ func Load() (*Config, error) {
 				Port: getEnv("PRODUCT_SERVICE_PORT", "50052"),
 				Host: getEnv("PRODUCT_SERVICE_HOST", "localhost"),
 			},
+			Order: ServiceConfig{
+				Port: getEnv("ORDER_SERVICE_PORT", "50053"),
+				Host: getEnv("ORDER_SERVICE_HOST", "localhost"),
+			},
+			Webhook: ServiceConfig{
+				Port: getEnv("WEBHOOK_SERVICE_PORT", "50054"),
+				Host: getEnv("WEBHOOK_SERVICE_HOST", "localhost"),
+			},
 		},
 		Swagger: SwaggerConfig{ // Add swagger config
 			Enabled: getBoolEnv("SWAGGER_ENABLED", true),
@@ -41,6 +49,52 @@ func Load() (*Config, error) {
 			Version:     getEnv("SWAGGER_VERSION", "1.0.0"),
 			Description: getEnv("SWAGGER_DESCRIPTION", "Microservice API for user and product management"),
 		},
+		Notifier: NotifierConfig{
+			Type:          getEnv("NOTIFIER_TYPE", "log"),
+			WebhookURL:    getEnv("NOTIFIER_WEBHOOK_URL", ""),
+			WebhookSecret: getEnv("NOTIFIER_WEBHOOK_SECRET", ""),
+			Timeout:       getEnvInt("NOTIFIER_TIMEOUT", 5),
+		},
+		Exchange: ExchangeConfig{
+			Provider:  getEnv("EXCHANGE_RATES_PROVIDER", "file"),
+			RatesFile: getEnv("EXCHANGE_RATES_FILE", "configs/exchange_rates.json"),
+		},
+		BlobStore: BlobStoreConfig{
+			Provider:  getEnv("BLOB_STORE_PROVIDER", "local"),
+			LocalDir:  getEnv("BLOB_STORE_LOCAL_DIR", "data/media"),
+			PublicURL: getEnv("BLOB_STORE_PUBLIC_URL", ""),
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", "http://localhost:9000"),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				Bucket:    getEnv("S3_BUCKET", "media"),
+				AccessKey: getEnv("S3_ACCESS_KEY", ""),
+				SecretKey: getEnv("S3_SECRET_KEY", ""),
+				PathStyle: getBoolEnv("S3_PATH_STYLE", true),
+				Timeout:   getEnvInt("S3_TIMEOUT", 30),
+			},
+		},
+		Media: MediaConfig{
+			MaxUploadMB:   getEnvInt("MEDIA_MAX_UPLOAD_MB", 10),
+			ThumbnailSize: getEnvInt("MEDIA_THUMBNAIL_SIZE", 320),
+		},
+		Cart: CartConfig{
+			TTLHours: getEnvInt("CART_TTL_HOURS", 168),
+		},
+		Payment: PaymentConfig{
+			Provider:         getEnv("PAYMENT_PROVIDER", "fake"),
+			FakeDeclineAbove: int64(getEnvInt("PAYMENT_FAKE_DECLINE_ABOVE", 0)),
+		},
+		Events: EventsConfig{
+			Bus:          getEnv("EVENTS_BUS", "redis"),
+			Stream:       getEnv("EVENTS_STREAM", "events"),
+			StreamMaxLen: int64(getEnvInt("EVENTS_STREAM_MAX_LEN", 1000000)),
+			MaxAttempts:  getEnvInt("EVENTS_MAX_ATTEMPTS", 8),
+		},
+		Webhook: WebhookConfig{
+			Timeout:      getEnvInt("WEBHOOK_TIMEOUT", 10),
+			MaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			DisableAfter: getEnvInt("WEBHOOK_DISABLE_AFTER", 5),
+		},
 		LogLevel:  getEnv("LOG_LEVEL", "info"),
 		JWTSecret: getEnv("JWT_SECRET", "boilerplate@123"),
 	}, nil
@@ -5,6 +5,14 @@ type Config struct {
 	Redis     RedisConfig
 	Services  ServicesConfig
 	Swagger   SwaggerConfig
+	Notifier  NotifierConfig
+	Exchange  ExchangeConfig
+	BlobStore BlobStoreConfig
+	Media     MediaConfig
+	Cart      CartConfig
+	Payment   PaymentConfig
+	Events    EventsConfig
+	Webhook   WebhookConfig
 	LogLevel  string
 	JWTSecret string
 }
@@ -25,6 +33,8 @@ type ServicesConfig struct {
 	Gateway GatewayConfig
 	User    ServiceConfig
 	Product ServiceConfig
+	Order   ServiceConfig
+	Webhook ServiceConfig
 }
 
 type GatewayConfig struct {
@@ -49,3 +59,71 @@ type SwaggerAuth struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
 }
+
+type NotifierConfig struct {
+	Type          string
+	WebhookURL    string
+	WebhookSecret string
+	Timeout       int
+}
+
+type ExchangeConfig struct {
+	Provider  string
+	RatesFile string
+}
+
+type BlobStoreConfig struct {
+	Provider  string
+	LocalDir  string
+	PublicURL string
+	S3        S3Config
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+	Timeout   int
+}
+
+type MediaConfig struct {
+	MaxUploadMB   int
+	ThumbnailSize int
+}
+
+type PaymentConfig struct {
+	Provider string
+	// FakeDeclineAbove makes the fake provider decline amounts above this
+	// many minor units, zero approves everything
+	FakeDeclineAbove int64
+}
+
+type CartConfig struct {
+	// TTLHours is how long a cart is kept after it was last used
+	TTLHours int
+}
+
+type EventsConfig struct {
+	// Bus selects the event bus domain events are published to
+	Bus string
+	// Stream is the Redis stream events are appended to
+	Stream string
+	// StreamMaxLen trims the stream to about this many events
+	StreamMaxLen int64
+	// MaxAttempts is how often an event is handed to a failing handler
+	// before it is moved to the dead-letter stream
+	MaxAttempts int
+}
+
+type WebhookConfig struct {
+	// Timeout bounds each request to an endpoint, in seconds
+	Timeout int
+	// MaxAttempts is how often a delivery is attempted before it fails
+	MaxAttempts int
+	// DisableAfter disables an endpoint once this many deliveries in a row
+	// failed, zero never disables
+	DisableAfter int
+}
@@ -2,16 +2,27 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/utils/logger"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrTransactionsUnsupported is returned by WithTransaction on a standalone
+// server, which cannot run multi-document transactions
+var ErrTransactionsUnsupported = errors.New("MongoDB must run as a replica set or behind mongos to support transactions")
+
 type MongoDB struct {
 	Client   *mongo.Client
 	Database *mongo.Database
+	// transactions tells whether the deployment supports multi-document
+	// transactions, which standalone servers do not
+	transactions bool
 }
 
 func NewMongoDB(config config.MongoDBConfig) (*MongoDB, error) {
@@ -30,9 +41,23 @@ func NewMongoDB(config config.MongoDBConfig) (*MongoDB, error) {
 
 	database := client.Database(config.Database)
 
+	// Transactions need a replica set member or a mongos router
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return nil, err
+	}
+	transactions := hello.SetName != "" || hello.Msg == "isdbgrid"
+	if !transactions {
+		logger.Warn("MongoDB is a standalone server, writes that need a transaction will fail")
+	}
+
 	return &MongoDB{
-		Client:   client,
-		Database: database,
+		Client:       client,
+		Database:     database,
+		transactions: transactions,
 	}, nil
 }
 
@@ -43,3 +68,57 @@ func (m *MongoDB) Disconnect() error {
 func (m *MongoDB) Collection(name string) *mongo.Collection {
 	return m.Database.Collection(name)
 }
+
+// EnsureIndexes creates the indexes of a collection that do not exist yet.
+// Indexes that exist with the same keys and options are left alone, so
+// services call it at every start for the indexes their writes rely on.
+func (m *MongoDB) EnsureIndexes(ctx context.Context, collection string, indexes ...mongo.IndexModel) error {
+	if _, err := m.Collection(collection).Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("failed to create indexes of %s: %w", collection, err)
+	}
+	return nil
+}
+
+// EnablePreImages makes the change streams of a collection able to carry
+// the documents as they were before each change. It needs MongoDB 6.0 and
+// does nothing on a standalone server, which has no change streams.
+func (m *MongoDB) EnablePreImages(ctx context.Context, collection string) error {
+	if !m.transactions {
+		return nil
+	}
+	err := m.Database.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "changeStreamPreAndPostImages", Value: bson.M{"enabled": true}},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to enable pre-images of %s: %w", collection, err)
+	}
+	return nil
+}
+
+// WithTransaction runs fn in a transaction, committed when fn succeeds and
+// aborted otherwise. Operations join it by using the context fn is given.
+// Called with the context of a transaction, fn joins that one instead. A
+// transaction that hits a transient error, such as a write conflict with a
+// concurrent one, is retried with fn run again, so fn must make its writes
+// through ctx only. On a standalone server ErrTransactionsUnsupported is
+// returned and fn does not run.
+func (m *MongoDB) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	if !m.transactions {
+		return ErrTransactionsUnsupported
+	}
+
+	session, err := m.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(ctx, func(ctx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
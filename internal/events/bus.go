@@ -0,0 +1,153 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/utils/logger"
+)
+
+// ErrSubscribeUnsupported is returned by buses that only publish
+var ErrSubscribeUnsupported = errors.New("event bus does not support subscriptions")
+
+// Publisher hands events to an event bus. Events may be published more than
+// once, never fewer: a failed call is retried with the same events.
+type Publisher interface {
+	Publish(ctx context.Context, events ...*Event) error
+}
+
+// Subscriber delivers published events to subscriptions
+type Subscriber interface {
+	// Subscribe hands events to the handlers of the subscription until ctx
+	// is cancelled. Delivery is at least once: an event is acknowledged
+	// once its handler succeeds, retried with backoff while it fails, and
+	// moved to the dead-letter stream after RetryPolicy.MaxAttempts.
+	Subscribe(ctx context.Context, sub Subscription) error
+}
+
+// Bus publishes events and delivers them to subscriptions
+type Bus interface {
+	Publisher
+	Subscriber
+}
+
+// Handler processes an event. Returning an error delivers the event again
+// later.
+type Handler func(ctx context.Context, event *Event) error
+
+// Subscription declares which events a consumer group handles
+type Subscription struct {
+	// Group names the consumer group. Every group receives every event
+	// published after it first subscribed; the instances subscribed with
+	// the same group share its events.
+	Group string
+	// Handlers maps event types to their handler. Keys are event types,
+	// "<aggregate>.*" for every event of an aggregate, or "*". Events no
+	// handler matches are acknowledged untouched.
+	Handlers map[string]Handler
+}
+
+// handler returns the most specific handler for an event type, nil when
+// there is none
+func (s Subscription) handler(eventType string) Handler {
+	if handler, ok := s.Handlers[eventType]; ok {
+		return handler
+	}
+	if aggregate, _, ok := strings.Cut(eventType, "."); ok {
+		if handler, ok := s.Handlers[aggregate+".*"]; ok {
+			return handler
+		}
+	}
+	return s.Handlers["*"]
+}
+
+// On adapts a typed handler: the payload of every event is decoded into a
+// T before it is called
+func On[T any](handle func(ctx context.Context, event *Event, payload T) error) Handler {
+	return func(ctx context.Context, event *Event) error {
+		var payload T
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode %s payload: %w", event.Type, err)
+		}
+		return handle(ctx, event, payload)
+	}
+}
+
+// RetryPolicy tells how events that failed are delivered again
+type RetryPolicy struct {
+	// MaxAttempts is how often an event is handed to a handler before it
+	// is dead-lettered
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubling with every
+	// further attempt up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy retries for about half an hour
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 8,
+	Backoff:     10 * time.Second,
+	MaxBackoff:  10 * time.Minute,
+}
+
+// Delay returns how long an event waits after failing the given attempt
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+// DeadLetter is an event that kept failing in a consumer group
+type DeadLetter struct {
+	Event    *Event    `json:"event"`
+	Group    string    `json:"group"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// NewBus creates the bus selected by the configuration. Unknown buses fall
+// back to logging so events are never silently dropped.
+func NewBus(cfg config.EventsConfig, redis *database.Redis) Bus {
+	retry := DefaultRetryPolicy
+	if cfg.MaxAttempts > 0 {
+		retry.MaxAttempts = cfg.MaxAttempts
+	}
+
+	switch cfg.Bus {
+	case "redis":
+		return NewRedisBus(redis, cfg.Stream, cfg.StreamMaxLen, retry)
+	case "memory":
+		return NewMemoryBus(retry)
+	case "log":
+		return NewLogBus()
+	default:
+		logger.Errorf("Unknown event bus %q, logging events instead", cfg.Bus)
+		return NewLogBus()
+	}
+}
+
+// Run consumes the subscriptions until ctx is cancelled
+func Run(ctx context.Context, subscriber Subscriber, subs ...Subscription) {
+	var wg sync.WaitGroup
+	for _, sub := range subs {
+		wg.Add(1)
+		go func(sub Subscription) {
+			defer wg.Done()
+			if err := subscriber.Subscribe(ctx, sub); err != nil {
+				logger.Errorf("Event subscription %s stopped: %v", sub.Group, err)
+			}
+		}(sub)
+	}
+	wg.Wait()
+}
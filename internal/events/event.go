@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+// Version is the version of the envelope and payload format of the events
+// created now. Fields are only ever added within a version; consumers
+// should skip events of versions they do not know.
+const Version = 1
+
+// Domain event types, named <aggregate>.<change>
+const (
+	UserCreated    = "user.created"
+	UserUpdated    = "user.updated"
+	UserDeleted    = "user.deleted"
+	ProductCreated = "product.created"
+	ProductUpdated = "product.updated"
+	ProductDeleted = "product.deleted"
+)
+
+// Event is the envelope of a domain event: something that happened to an
+// aggregate, such as a user or a product, that other systems may react to
+type Event struct {
+	// ID is unique per event, so consumers can drop the duplicates that
+	// at-least-once delivery produces
+	ID          string    `bson:"id" json:"id"`
+	Version     int       `bson:"version" json:"version"`
+	Type        string    `bson:"type" json:"type"`
+	AggregateID string    `bson:"aggregate_id" json:"aggregate_id"`
+	OccurredAt  time.Time `bson:"occurred_at" json:"occurred_at"`
+	// Payload is the JSON state of the aggregate after the change, or what
+	// identifies it for deletions
+	Payload json.RawMessage `bson:"payload" json:"payload"`
+	Trace   Trace           `bson:"trace" json:"trace"`
+}
+
+// Trace ties an event to the request that caused it
+type Trace struct {
+	CorrelationID string `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
+	Actor         string `bson:"actor,omitempty" json:"actor,omitempty"`
+}
+
+// Deleted is the payload of the events of deleted aggregates
+type Deleted struct {
+	ID string `json:"id"`
+	// SKU is set for products
+	SKU string `json:"sku,omitempty"`
+}
+
+// New creates an event about the aggregate with the given ID, taking the
+// trace context from ctx
+func New(ctx context.Context, eventType, aggregateID string, payload interface{}) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return &Event{
+		ID:          primitive.NewObjectID().Hex(),
+		Version:     Version,
+		Type:        eventType,
+		AggregateID: aggregateID,
+		OccurredAt:  time.Now().UTC(),
+		Payload:     data,
+		Trace: Trace{
+			CorrelationID: requestmeta.CorrelationID(ctx),
+			Actor:         requestmeta.Actor(ctx),
+		},
+	}, nil
+}
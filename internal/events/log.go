@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"go-microservice-boilerplate/internal/utils/logger"
+)
+
+type logBus struct{}
+
+// NewLogBus creates a bus that writes events to the service log. Nothing
+// can subscribe to it.
+func NewLogBus() Bus {
+	return &logBus{}
+}
+
+func (b *logBus) Publish(ctx context.Context, events ...*Event) error {
+	for _, event := range events {
+		logger.WithFields(logrus.Fields{
+			"event_id":       event.ID,
+			"event":          event.Type,
+			"aggregate_id":   event.AggregateID,
+			"occurred_at":    event.OccurredAt,
+			"correlation_id": event.Trace.CorrelationID,
+		}).Info("Domain event")
+	}
+	return nil
+}
+
+func (b *logBus) Subscribe(ctx context.Context, sub Subscription) error {
+	return ErrSubscribeUnsupported
+}
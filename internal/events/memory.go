@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-microservice-boilerplate/internal/utils/logger"
+)
+
+// memoryQueueSize bounds how many events wait for a consumer group of the
+// memory bus before publishing blocks
+const memoryQueueSize = 1024
+
+// delivery is an event on its way to a consumer group
+type delivery struct {
+	event   *Event
+	attempt int
+}
+
+// MemoryBus is an in-process bus for tests and single-process setups. It
+// keeps nothing across restarts and delivers events only to the groups that
+// subscribed before they were published.
+type MemoryBus struct {
+	retry RetryPolicy
+
+	mu     sync.Mutex
+	groups map[string]chan delivery
+	dead   []DeadLetter
+}
+
+func NewMemoryBus(retry RetryPolicy) *MemoryBus {
+	return &MemoryBus{
+		retry:  retry,
+		groups: make(map[string]chan delivery),
+	}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, events ...*Event) error {
+	b.mu.Lock()
+	queues := make([]chan delivery, 0, len(b.groups))
+	for _, queue := range b.groups {
+		queues = append(queues, queue)
+	}
+	b.mu.Unlock()
+
+	for _, event := range events {
+		for _, queue := range queues {
+			select {
+			case queue <- delivery{event: event, attempt: 1}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(ctx context.Context, sub Subscription) error {
+	queue := b.queue(sub.Group)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d := <-queue:
+			b.process(ctx, sub, queue, d)
+		}
+	}
+}
+
+// DeadLetters returns the events that kept failing so far
+func (b *MemoryBus) DeadLetters() []DeadLetter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]DeadLetter(nil), b.dead...)
+}
+
+// queue returns the queue of a consumer group, creating it on first use
+func (b *MemoryBus) queue(group string) chan delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	queue, ok := b.groups[group]
+	if !ok {
+		queue = make(chan delivery, memoryQueueSize)
+		b.groups[group] = queue
+	}
+	return queue
+}
+
+func (b *MemoryBus) process(ctx context.Context, sub Subscription, queue chan delivery, d delivery) {
+	handler := sub.handler(d.event.Type)
+	if handler == nil {
+		return
+	}
+	err := handler(ctx, d.event)
+	if err == nil {
+		return
+	}
+
+	if d.attempt < b.retry.MaxAttempts {
+		logger.Warnf("Handler of %s failed for event %s (attempt %d), retrying in %s: %v", sub.Group, d.event.ID, d.attempt, b.retry.Delay(d.attempt), err)
+		retry := delivery{event: d.event, attempt: d.attempt + 1}
+		time.AfterFunc(b.retry.Delay(d.attempt), func() {
+			// A subscription that stopped meanwhile takes no more events;
+			// the memory bus keeps nothing for the next one anyway
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case queue <- retry:
+			case <-ctx.Done():
+			}
+		})
+		return
+	}
+
+	logger.Errorf("Moving event %s to the dead letters of %s after %d attempts: %v", d.event.ID, sub.Group, d.attempt, err)
+	b.mu.Lock()
+	b.dead = append(b.dead, DeadLetter{
+		Event:    d.event,
+		Group:    sub.Group,
+		Error:    err.Error(),
+		Attempts: d.attempt,
+		FailedAt: time.Now().UTC(),
+	})
+	b.mu.Unlock()
+}
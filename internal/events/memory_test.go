@@ -0,0 +1,277 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-microservice-boilerplate/internal/utils/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init("fatal")
+	os.Exit(m.Run())
+}
+
+// testRetry retries quickly so tests do not wait for backoff
+var testRetry = RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+
+func newTestEvent(t *testing.T, eventType string) *Event {
+	t.Helper()
+	event, err := New(context.Background(), eventType, "aggregate", map[string]string{"name": "test"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return event
+}
+
+// subscribe consumes sub on bus until the test ends, with its queue created
+// before the test publishes
+func subscribe(t *testing.T, bus *MemoryBus, sub Subscription) context.CancelFunc {
+	t.Helper()
+	bus.queue(sub.Group)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bus.Subscribe(ctx, sub)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return cancel
+}
+
+// waitFor polls until condition holds, failing the test after a second
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMemoryBusRetriesFailedEvents(t *testing.T) {
+	bus := NewMemoryBus(testRetry)
+
+	var attempts atomic.Int32
+	handled := make(chan struct{})
+	subscribe(t, bus, Subscription{
+		Group: "test",
+		Handlers: map[string]Handler{
+			UserCreated: func(ctx context.Context, event *Event) error {
+				if attempts.Add(1) < 3 {
+					return errors.New("temporary failure")
+				}
+				close(handled)
+				return nil
+			},
+		},
+	})
+
+	if err := bus.Publish(context.Background(), newTestEvent(t, UserCreated)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatalf("event was not handled, %d attempts", attempts.Load())
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+	if dead := bus.DeadLetters(); len(dead) != 0 {
+		t.Errorf("DeadLetters() = %v, want none", dead)
+	}
+}
+
+func TestMemoryBusDeadLettersAfterMaxAttempts(t *testing.T) {
+	bus := NewMemoryBus(testRetry)
+
+	var attempts atomic.Int32
+	subscribe(t, bus, Subscription{
+		Group: "test",
+		Handlers: map[string]Handler{
+			"user.*": func(ctx context.Context, event *Event) error {
+				attempts.Add(1)
+				return errors.New("permanent failure")
+			},
+		},
+	})
+
+	event := newTestEvent(t, UserDeleted)
+	if err := bus.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	waitFor(t, "a dead letter", func() bool { return len(bus.DeadLetters()) > 0 })
+
+	dead := bus.DeadLetters()
+	if len(dead) != 1 {
+		t.Fatalf("DeadLetters() = %v, want one", dead)
+	}
+	if dead[0].Event.ID != event.ID || dead[0].Group != "test" || dead[0].Attempts != testRetry.MaxAttempts ||
+		dead[0].Error != "permanent failure" {
+		t.Errorf("dead letter = %+v, want event %s of group test after %d attempts", dead[0], event.ID, testRetry.MaxAttempts)
+	}
+	if got := attempts.Load(); got != int32(testRetry.MaxAttempts) {
+		t.Errorf("attempts = %d, want %d", got, testRetry.MaxAttempts)
+	}
+}
+
+func TestMemoryBusStopsRetryingAfterCancel(t *testing.T) {
+	bus := NewMemoryBus(RetryPolicy{MaxAttempts: 3, Backoff: 20 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+
+	failed := make(chan struct{}, 1)
+	cancel := subscribe(t, bus, Subscription{
+		Group: "test",
+		Handlers: map[string]Handler{
+			"*": func(ctx context.Context, event *Event) error {
+				failed <- struct{}{}
+				return errors.New("failure")
+			},
+		},
+	})
+
+	if err := bus.Publish(context.Background(), newTestEvent(t, ProductUpdated)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("event was not handled")
+	}
+	cancel()
+
+	// Long enough for the retry to be due
+	time.Sleep(50 * time.Millisecond)
+	if queued := len(bus.queue("test")); queued != 0 {
+		t.Errorf("queue holds %d deliveries after the subscription stopped, want 0", queued)
+	}
+}
+
+func TestMemoryBusConsumerGroups(t *testing.T) {
+	bus := NewMemoryBus(testRetry)
+
+	var mu sync.Mutex
+	handled := make(map[string][]string)
+	handler := func(group string) Handler {
+		return func(ctx context.Context, event *Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			handled[group] = append(handled[group], event.ID)
+			return nil
+		}
+	}
+
+	// Two instances share the events of the search group, the audit group
+	// gets every event too
+	for i := 0; i < 2; i++ {
+		subscribe(t, bus, Subscription{Group: "search", Handlers: map[string]Handler{"product.*": handler("search")}})
+	}
+	subscribe(t, bus, Subscription{Group: "audit", Handlers: map[string]Handler{"*": handler("audit")}})
+
+	published := []*Event{
+		newTestEvent(t, ProductCreated),
+		newTestEvent(t, ProductUpdated),
+		newTestEvent(t, UserCreated),
+		newTestEvent(t, ProductDeleted),
+	}
+	if err := bus.Publish(context.Background(), published...); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	count := func(group string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled[group])
+	}
+	waitFor(t, "both groups", func() bool { return count("search") == 3 && count("audit") == 4 })
+
+	// Events no handler matches are skipped rather than retried, and the
+	// instances of a group never handle an event twice
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	seen := make(map[string]bool)
+	for _, id := range handled["search"] {
+		if seen[id] {
+			t.Errorf("search handled event %s twice", id)
+		}
+		seen[id] = true
+	}
+	if seen[published[2].ID] {
+		t.Errorf("search handled %s, which no handler of the group matches", UserCreated)
+	}
+	if len(handled["search"]) != 3 || len(handled["audit"]) != 4 {
+		t.Errorf("handled = %v, want 3 events in search and 4 in audit", handled)
+	}
+}
+
+func TestSubscriptionHandler(t *testing.T) {
+	// Each handler answers with its own error, telling which one was picked
+	answer := func(name string) Handler {
+		return func(ctx context.Context, event *Event) error { return errors.New(name) }
+	}
+	sub := Subscription{Handlers: map[string]Handler{
+		UserDeleted: answer("exact"),
+		"user.*":    answer("aggregate"),
+		"*":         answer("fallback"),
+	}}
+
+	tests := []struct {
+		eventType string
+		want      string
+	}{
+		{eventType: UserDeleted, want: "exact"},
+		{eventType: UserCreated, want: "aggregate"},
+		{eventType: ProductCreated, want: "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			handler := sub.handler(tt.eventType)
+			if handler == nil {
+				t.Fatal("handler() = nil")
+			}
+			if got := handler(context.Background(), nil).Error(); got != tt.want {
+				t.Errorf("handler(%q) picked %s, want %s", tt.eventType, got, tt.want)
+			}
+		})
+	}
+
+	if got := (Subscription{Handlers: map[string]Handler{"user.*": answer("aggregate")}}).handler(ProductCreated); got != nil {
+		t.Error("handler() matched an event of another aggregate")
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 5 * time.Second},
+		{attempt: 20, want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/utils/logger"
+)
+
+const (
+	// redisReadBlock is how long a consumer waits for new events before it
+	// looks for failed events due for a retry
+	redisReadBlock = 5 * time.Second
+	// redisReadCount bounds how many events one read returns
+	redisReadCount = 50
+	// redisErrorDelay is how long a consumer pauses after Redis failed
+	redisErrorDelay = time.Second
+)
+
+type redisBus struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+	retry  RetryPolicy
+	// consumer names this process within consumer groups
+	consumer string
+}
+
+// NewRedisBus creates a bus on a Redis stream. Consumer groups are Redis
+// consumer groups; events that failed stay pending in their group until
+// they are claimed again for a retry, and dead letters are appended to the
+// "<stream>:dead" stream.
+func NewRedisBus(redis *database.Redis, stream string, maxLen int64, retry RetryPolicy) Bus {
+	host, _ := os.Hostname()
+	return &redisBus{
+		client:   redis.Client,
+		stream:   stream,
+		maxLen:   maxLen,
+		retry:    retry,
+		consumer: fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}
+}
+
+func (b *redisBus) Publish(ctx context.Context, events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: b.stream,
+				MaxLen: b.maxLen,
+				Approx: true,
+				Values: map[string]interface{}{"type": event.Type, "event": data},
+			})
+		}
+		return nil
+	})
+	return err
+}
+
+func (b *redisBus) Subscribe(ctx context.Context, sub Subscription) error {
+	// A new group starts with the events published from now on
+	err := b.client.XGroupCreateMkStream(ctx, b.stream, sub.Group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", sub.Group, err)
+	}
+
+	for ctx.Err() == nil {
+		b.retryPending(ctx, sub)
+
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    sub.Group,
+			Consumer: b.consumer,
+			Streams:  []string{b.stream, ">"},
+			Count:    redisReadCount,
+			Block:    redisReadBlock,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.Errorf("Failed to read events for %s: %v", sub.Group, err)
+			sleep(ctx, redisErrorDelay)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				b.process(ctx, sub, message, 1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// retryPending claims the events of the group whose handler failed, or
+// whose consumer went away, once their backoff has passed, and hands them
+// to the handlers again
+func (b *redisBus) retryPending(ctx context.Context, sub Subscription) {
+	// Entries not due yet still fill pages, so the whole pending list is
+	// paged through rather than only its first page
+	start := "-"
+	for ctx.Err() == nil {
+		pending, err := b.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: b.stream,
+			Group:  sub.Group,
+			Idle:   b.retry.Backoff,
+			Start:  start,
+			End:    "+",
+			Count:  redisReadCount,
+		}).Result()
+		if err != nil {
+			logger.Errorf("Failed to list pending events for %s: %v", sub.Group, err)
+			return
+		}
+
+		b.retryDue(ctx, sub, pending)
+		if len(pending) < redisReadCount {
+			return
+		}
+		// Exclusive ranges continue after the last entry of the page
+		start = "(" + pending[len(pending)-1].ID
+	}
+}
+
+// retryDue retries the pending entries whose backoff has passed
+func (b *redisBus) retryDue(ctx context.Context, sub Subscription, pending []redis.XPendingExt) {
+	for _, entry := range pending {
+		attempts := int(entry.RetryCount)
+		backoff := b.retry.Delay(attempts)
+		if entry.Idle < backoff {
+			continue
+		}
+
+		// Claiming with the backoff as minimum idle time keeps two
+		// consumers from claiming the same event
+		messages, err := b.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   b.stream,
+			Group:    sub.Group,
+			Consumer: b.consumer,
+			MinIdle:  backoff,
+			Messages: []string{entry.ID},
+		}).Result()
+		if err != nil {
+			logger.Errorf("Failed to claim event %s for %s: %v", entry.ID, sub.Group, err)
+			continue
+		}
+		if len(messages) == 0 {
+			continue
+		}
+		// Events trimmed from the stream meanwhile cannot be retried
+		if messages[0].Values == nil {
+			b.ack(ctx, sub.Group, entry.ID)
+			continue
+		}
+
+		b.process(ctx, sub, messages[0], attempts+1)
+	}
+}
+
+// process hands a message to its handler, acknowledging it unless the
+// handler failed and may be retried
+func (b *redisBus) process(ctx context.Context, sub Subscription, message redis.XMessage, attempt int) {
+	data, _ := message.Values["event"].(string)
+	var event Event
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		logger.Errorf("Dropping malformed event %s: %v", message.ID, err)
+		b.ack(ctx, sub.Group, message.ID)
+		return
+	}
+
+	if handler := sub.handler(event.Type); handler != nil {
+		if err := handler(ctx, &event); err != nil {
+			if attempt < b.retry.MaxAttempts {
+				logger.Warnf("Handler of %s failed for event %s (attempt %d), retrying in %s: %v", sub.Group, event.ID, attempt, b.retry.Delay(attempt), err)
+				return
+			}
+			// An event that could not be dead-lettered stays pending
+			err = b.deadLetter(ctx, DeadLetter{
+				Event:    &event,
+				Group:    sub.Group,
+				Error:    err.Error(),
+				Attempts: attempt,
+				FailedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				logger.Errorf("Failed to dead-letter event %s of %s: %v", event.ID, sub.Group, err)
+				return
+			}
+		}
+	}
+
+	b.ack(ctx, sub.Group, message.ID)
+}
+
+// deadLetter appends an event that kept failing to the dead-letter stream
+func (b *redisBus) deadLetter(ctx context.Context, letter DeadLetter) error {
+	logger.Errorf("Moving event %s to the dead-letter stream of %s after %d attempts: %s", letter.Event.ID, letter.Group, letter.Attempts, letter.Error)
+
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	return b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.stream + ":dead",
+		MaxLen: b.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"group": letter.Group, "type": letter.Event.Type, "letter": data},
+	}).Err()
+}
+
+func (b *redisBus) ack(ctx context.Context, group, id string) {
+	if err := b.client.XAck(ctx, b.stream, group, id).Err(); err != nil {
+		logger.Errorf("Failed to acknowledge event %s for %s: %v", id, group, err)
+	}
+}
+
+// sleep waits for d or until ctx is cancelled
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
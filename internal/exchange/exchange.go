@@ -0,0 +1,44 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/utils/logger"
+)
+
+// ErrRateNotFound is returned when a provider has no rate between two currencies
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Rate converts one unit of From into Value units of To, as published at Timestamp
+type Rate struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Value     float64   `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Provider looks up exchange rates between ISO 4217 currencies
+type Provider interface {
+	Rate(ctx context.Context, from, to string) (*Rate, error)
+}
+
+// New creates the provider selected by the configuration. A provider that
+// cannot be set up leaves prices unconvertible rather than stopping the
+// service, so same-currency prices keep working.
+func New(cfg config.ExchangeConfig) Provider {
+	switch cfg.Provider {
+	case "file":
+		provider, err := NewFileProvider(cfg.RatesFile)
+		if err != nil {
+			logger.Errorf("Failed to load exchange rates, price conversion is disabled: %v", err)
+			return NewStaticProvider(Rates{})
+		}
+		return provider
+	default:
+		logger.Errorf("Unknown exchange rate provider %q, price conversion is disabled", cfg.Provider)
+		return NewStaticProvider(Rates{})
+	}
+}
@@ -0,0 +1,86 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Rates is a table of rates against a single base currency, the format of
+// the static rates file:
+//
+//	{"base": "USD", "timestamp": "2026-10-01T00:00:00Z", "rates": {"EUR": 0.92}}
+type Rates struct {
+	Base      string             `json:"base"`
+	Timestamp time.Time          `json:"timestamp"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+type staticProvider struct {
+	rates Rates
+}
+
+// NewStaticProvider serves fixed rates. Rates between two non-base
+// currencies are derived through the base currency.
+func NewStaticProvider(rates Rates) Provider {
+	normalized := Rates{
+		Base:      strings.ToUpper(rates.Base),
+		Timestamp: rates.Timestamp,
+		Rates:     make(map[string]float64, len(rates.Rates)+1),
+	}
+	for currency, value := range rates.Rates {
+		normalized.Rates[strings.ToUpper(currency)] = value
+	}
+	if normalized.Base != "" {
+		normalized.Rates[normalized.Base] = 1
+	}
+
+	return &staticProvider{rates: normalized}
+}
+
+// NewFileProvider serves the rates stored in a JSON file, for running
+// without access to a rates service
+func NewFileProvider(path string) (Provider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var rates Rates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file %s: %w", path, err)
+	}
+	if rates.Base == "" {
+		return nil, fmt.Errorf("rates file %s has no base currency", path)
+	}
+	for currency, value := range rates.Rates {
+		if value <= 0 {
+			return nil, fmt.Errorf("rates file %s has a non-positive rate for %s", path, currency)
+		}
+	}
+
+	return NewStaticProvider(rates), nil
+}
+
+func (p *staticProvider) Rate(ctx context.Context, from, to string) (*Rate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+
+	fromRate, ok := p.rates.Rates[from]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s to %s", ErrRateNotFound, from, to)
+	}
+	toRate, ok := p.rates.Rates[to]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s to %s", ErrRateNotFound, from, to)
+	}
+
+	return &Rate{
+		From:      from,
+		To:        to,
+		Value:     toRate / fromRate,
+		Timestamp: p.rates.Timestamp,
+	}, nil
+}
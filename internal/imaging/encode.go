@@ -0,0 +1,44 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+
+	// Register the decoders for the accepted upload formats
+	_ "image/gif"
+	_ "image/png"
+)
+
+// thumbnailQuality is the JPEG quality thumbnails are encoded with
+const thumbnailQuality = 80
+
+// MaxPixels bounds the dimensions of accepted images, so decoding one cannot
+// exhaust memory
+const MaxPixels = 40_000_000
+
+// Extensions maps the accepted image content types to the file extension
+// stored blobs get
+var Extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// JPEGThumbnail decodes a JPEG, PNG or GIF image from r and returns a JPEG
+// thumbnail of it fitting within size x size pixels, together with the
+// dimensions of the original
+func JPEGThumbnail(r io.Reader, size int) ([]byte, image.Point, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, image.Point{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, Thumbnail(img, size), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, image.Point{}, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), img.Bounds().Size(), nil
+}
@@ -2,78 +2,148 @@ package middleware
 
 import (
 	"crypto/subtle"
-	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// Roles carried in the roles claim of a token
+const (
+	RoleAdmin          = "admin"
+	RoleCatalogManager = "catalog_manager"
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID string   `json:"user_id"`
+	Email  string   `json:"email"`
+	Roles  []string `json:"roles"`
 	jwt.RegisteredClaims
 }
 
-//func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
-//	return func(c *gin.Context) {
-//		authHeader := c.GetHeader("Authorization")
-//		if authHeader == "" {
-//			response.Error(c, http.StatusUnauthorized, "Authorization header required", nil)
-//			c.Abort()
-//			return
-//		}
-//
-//		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-//		if tokenString == authHeader {
-//			response.Error(c, http.StatusUnauthorized, "Invalid authorization header format", nil)
-//			c.Abort()
-//			return
-//		}
-//
-//		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-//			return []byte(jwtSecret), nil
-//		})
-//
-//		if err != nil || !token.Valid {
-//			response.Error(c, http.StatusUnauthorized, "Invalid or expired token", nil)
-//			c.Abort()
-//			return
-//		}
-//
-//		if claims, ok := token.Claims.(*Claims); ok {
-//			c.Set("user_id", claims.UserID)
-//			c.Set("email", claims.Email)
-//		}
-//
-//		c.Next()
-//	}
-//}
-//
-//func OptionalAuth(jwtSecret string) gin.HandlerFunc {
-//	return func(c *gin.Context) {
-//		authHeader := c.GetHeader("Authorization")
-//		if authHeader == "" {
-//			c.Next()
-//			return
-//		}
-//
-//		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-//		if tokenString != authHeader {
-//			token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-//				return []byte(jwtSecret), nil
-//			})
-//
-//			if err == nil && token.Valid {
-//				if claims, ok := token.Claims.(*Claims); ok {
-//					c.Set("user_id", claims.UserID)
-//					c.Set("email", claims.Email)
-//				}
-//			}
-//		}
-//
-//		c.Next()
-//	}
-//}
+func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			response.Error(c, http.StatusUnauthorized, "Authorization header required", nil)
+			c.Abort()
+			return
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if tokenString == authHeader {
+			response.Error(c, http.StatusUnauthorized, "Invalid authorization header format", nil)
+			c.Abort()
+			return
+		}
+
+		claims, err := parseToken(tokenString, jwtSecret)
+		if err != nil {
+			response.Error(c, http.StatusUnauthorized, "Invalid or expired token", nil)
+			c.Abort()
+			return
+		}
+		setClaims(c, claims)
+
+		c.Next()
+	}
+}
+
+// OptionalAuth records the claims of a valid bearer token and lets requests
+// without one through anonymously
+func OptionalAuth(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
+			return
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if tokenString != authHeader {
+			if claims, err := parseToken(tokenString, jwtSecret); err == nil {
+				setClaims(c, claims)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAuth rejects requests without a valid token. It runs after
+// OptionalAuth.
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("user_id"); !ok {
+			response.Error(c, http.StatusUnauthorized, "Authorization required", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose token carries none of the given roles.
+// It runs after AuthMiddleware or OptionalAuth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("user_id"); !ok {
+			response.Error(c, http.StatusUnauthorized, "Authorization required", nil)
+			c.Abort()
+			return
+		}
+		if !HasRole(c, roles...) {
+			response.Error(c, http.StatusForbidden, "Insufficient permissions", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasRole reports whether the token of the request carries any of the roles
+func HasRole(c *gin.Context, roles ...string) bool {
+	granted := c.GetStringSlice("roles")
+	for _, role := range roles {
+		for _, g := range granted {
+			if g == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseToken(tokenString, jwtSecret string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func setClaims(c *gin.Context, claims *Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("email", claims.Email)
+	c.Set("roles", claims.Roles)
+}
 
 // SwaggerAuth provides basic authentication for Swagger UI
 func SwaggerAuth(username, password string) gin.HandlerFunc {
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+// RequestMeta attaches a correlation ID and the acting client to the request
+// context so they reach the backend services. A missing correlation ID is
+// generated and echoed back in the response headers.
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationID := c.GetHeader(requestmeta.CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = c.GetHeader("X-Request-ID")
+		}
+		if correlationID == "" {
+			correlationID = newCorrelationID()
+		}
+		c.Header(requestmeta.CorrelationIDHeader, correlationID)
+
+		actor := c.GetHeader(requestmeta.ActorHeader)
+		if actor == "" {
+			actor = "anonymous"
+		}
+
+		ctx := requestmeta.WithCorrelationID(c.Request.Context(), correlationID)
+		ctx = requestmeta.WithActor(ctx, actor)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+func newCorrelationID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,25 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"go-microservice-boilerplate/internal/utils/logger"
+)
+
+type logNotifier struct{}
+
+// NewLogNotifier creates a notifier that writes events to the service log
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, event Event) error {
+	logger.WithFields(logrus.Fields{
+		"event":       event.Type,
+		"occurred_at": event.OccurredAt,
+		"data":        event.Data,
+	}).Warn("Notification")
+	return nil
+}
@@ -0,0 +1,31 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"go-microservice-boilerplate/internal/config"
+)
+
+// Event is a notification about something that happened in a service
+type Event struct {
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Notifier delivers events to whoever needs to act on them
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// New creates the notifier selected by the configuration. Unknown types fall
+// back to logging so events are never silently dropped.
+func New(cfg config.NotifierConfig) Notifier {
+	switch cfg.Type {
+	case "webhook":
+		return NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookSecret, time.Duration(cfg.Timeout)*time.Second)
+	default:
+		return NewLogNotifier()
+	}
+}
@@ -0,0 +1,208 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/utils/logger"
+)
+
+const (
+	// relayInterval is how often new domain events are published
+	relayInterval = time.Second
+	// relayBatch bounds how many events one relay run publishes
+	relayBatch = 100
+	// relayLease is how long a relay holds the events it claimed before
+	// another relay may publish them
+	relayLease = 30 * time.Second
+)
+
+// Outbox records domain events together with the changes that raise them,
+// and relays them to the event bus afterwards. An event is stored if and
+// only if its change is, so other systems never miss a change nor hear of
+// one that was rolled back.
+type Outbox interface {
+	// Write runs change in a transaction and stores the events it returns
+	// in the same transaction. Writes made by change must use the context
+	// it is given.
+	Write(ctx context.Context, change func(ctx context.Context) ([]*events.Event, error)) error
+	// Relay publishes up to limit unpublished events in the order they were
+	// written, returning how many it published. Relays of every service
+	// share the outbox, so each claims its events before publishing them.
+	// Events are published at least once: a crash between publishing and
+	// marking them publishes them again once the claim lapses.
+	Relay(ctx context.Context, publisher events.Publisher, limit int) (int, error)
+}
+
+// record is an event waiting in the outbox, or published recently
+type record struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Event       *events.Event      `bson:"event"`
+	Published   bool               `bson:"published"`
+	PublishedAt *time.Time         `bson:"published_at,omitempty"`
+	// ClaimedBy identifies the relay run publishing the event, which other
+	// relays leave alone until ClaimedUntil
+	ClaimedBy    string     `bson:"claimed_by,omitempty"`
+	ClaimedUntil *time.Time `bson:"claimed_until,omitempty"`
+}
+
+type mongoOutbox struct {
+	db         *database.MongoDB
+	collection *mongo.Collection
+}
+
+func NewMongoOutbox(db *database.MongoDB) Outbox {
+	return &mongoOutbox{
+		db:         db,
+		collection: db.Collection("outbox"),
+	}
+}
+
+func (o *mongoOutbox) Write(ctx context.Context, change func(ctx context.Context) ([]*events.Event, error)) error {
+	return o.db.WithTransaction(ctx, func(ctx context.Context) error {
+		recorded, err := change(ctx)
+		if err != nil {
+			return err
+		}
+		if len(recorded) == 0 {
+			return nil
+		}
+
+		docs := make([]interface{}, len(recorded))
+		for i, event := range recorded {
+			// Event IDs are object IDs, so the outbox is ordered by them
+			id, err := primitive.ObjectIDFromHex(event.ID)
+			if err != nil {
+				return fmt.Errorf("invalid event id %q: %w", event.ID, err)
+			}
+			docs[i] = record{ID: id, Event: event}
+		}
+		if _, err := o.collection.InsertMany(ctx, docs); err != nil {
+			return fmt.Errorf("failed to write events to the outbox: %w", err)
+		}
+		return nil
+	})
+}
+
+func (o *mongoOutbox) Relay(ctx context.Context, publisher events.Publisher, limit int) (int, error) {
+	pending, err := o.claim(ctx, limit)
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+
+	batch := make([]*events.Event, len(pending))
+	ids := make([]primitive.ObjectID, len(pending))
+	for i, r := range pending {
+		batch[i] = r.Event
+		ids[i] = r.ID
+	}
+	if err := publisher.Publish(ctx, batch...); err != nil {
+		return 0, fmt.Errorf("failed to publish events: %w", err)
+	}
+
+	// Published events are kept for a while, then dropped by a TTL index
+	// on published_at
+	_, err = o.collection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		bson.M{
+			"$set":   bson.M{"published": true, "published_at": time.Now()},
+			"$unset": bson.M{"claimed_by": "", "claimed_until": ""},
+		},
+	)
+	if err != nil {
+		return len(pending), fmt.Errorf("failed to mark events as published: %w", err)
+	}
+
+	return len(pending), nil
+}
+
+// claim leases up to limit unpublished events no other relay holds to this
+// relay run and returns them in the order they were written
+func (o *mongoOutbox) claim(ctx context.Context, limit int) ([]*record, error) {
+	now := time.Now()
+	unclaimed := bson.M{
+		"published": false,
+		"$or": bson.A{
+			bson.M{"claimed_until": bson.M{"$exists": false}},
+			bson.M{"claimed_until": bson.M{"$lte": now}},
+		},
+	}
+
+	findOptions := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"_id": 1})
+	cursor, err := o.collection.Find(ctx, unclaimed, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*record
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(candidates))
+	for i, r := range candidates {
+		ids[i] = r.ID
+	}
+
+	// The update only takes events still unclaimed, so of relays racing for
+	// the same events each gets a different share
+	owner := primitive.NewObjectID().Hex()
+	_, err = o.collection.UpdateMany(ctx,
+		bson.M{"$and": bson.A{bson.M{"_id": bson.M{"$in": ids}}, unclaimed}},
+		bson.M{"$set": bson.M{"claimed_by": owner, "claimed_until": now.Add(relayLease)}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim events: %w", err)
+	}
+
+	cursor, err = o.collection.Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "claimed_by": owner},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var claimed []*record
+	if err := cursor.All(ctx, &claimed); err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// Run relays the events written to outbox to publisher until ctx is
+// cancelled
+func Run(ctx context.Context, outbox Outbox, publisher events.Publisher) {
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A full batch means more events are waiting
+			for {
+				published, err := outbox.Relay(ctx, publisher, relayBatch)
+				if err != nil {
+					logger.Errorf("Failed to relay domain events: %v", err)
+				}
+				if err != nil || published < relayBatch {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// Authorization states of the fake provider
+const (
+	fakeAuthorized = "authorized"
+	fakeCaptured   = "captured"
+	fakeVoided     = "voided"
+)
+
+type fakeProvider struct {
+	// declineAbove makes authorizations of larger amounts fail, zero
+	// accepts every amount
+	declineAbove int64
+
+	mu     sync.Mutex
+	states map[string]string
+}
+
+// NewFakeProvider creates an in-process provider that approves every payment
+// up to declineAbove minor units. It keeps no state across restarts, so it
+// settles authorizations it has not seen without complaint.
+func NewFakeProvider(declineAbove int64) Provider {
+	return &fakeProvider{
+		declineAbove: declineAbove,
+		states:       make(map[string]string),
+	}
+}
+
+func (p *fakeProvider) Authorize(ctx context.Context, reference, customerID string, amount money.Money) (*Authorization, error) {
+	if p.declineAbove > 0 && amount.Amount > p.declineAbove {
+		return nil, fmt.Errorf("%w: %s is over the limit", ErrDeclined, amount)
+	}
+
+	id := "fake_" + reference
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.states[id]; !ok {
+		p.states[id] = fakeAuthorized
+	}
+
+	return &Authorization{ID: id, Amount: amount}, nil
+}
+
+func (p *fakeProvider) Capture(ctx context.Context, authorizationID string) error {
+	return p.settle(authorizationID, fakeCaptured)
+}
+
+func (p *fakeProvider) Void(ctx context.Context, authorizationID string) error {
+	return p.settle(authorizationID, fakeVoided)
+}
+
+func (p *fakeProvider) settle(authorizationID, state string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if current, ok := p.states[authorizationID]; ok && current != fakeAuthorized && current != state {
+		return fmt.Errorf("%w: %s is %s", ErrAuthorizationClosed, authorizationID, current)
+	}
+	p.states[authorizationID] = state
+	return nil
+}
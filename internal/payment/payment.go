@@ -0,0 +1,48 @@
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+var (
+	// ErrDeclined is returned when the provider refuses to authorize a
+	// payment. Other errors are worth retrying.
+	ErrDeclined = errors.New("payment declined")
+	// ErrAuthorizationClosed is returned when an authorization was already
+	// captured and is voided, or the other way round
+	ErrAuthorizationClosed = errors.New("authorization is no longer open")
+)
+
+// Authorization holds funds of a customer until they are captured or voided
+type Authorization struct {
+	ID     string
+	Amount money.Money
+}
+
+// Provider authorizes and settles payments. Every call is idempotent:
+// authorizing the same reference again returns the same authorization, and
+// capturing or voiding twice succeeds.
+type Provider interface {
+	// Authorize holds the amount for the customer, keyed by a reference
+	// unique to the purchase
+	Authorize(ctx context.Context, reference, customerID string, amount money.Money) (*Authorization, error)
+	Capture(ctx context.Context, authorizationID string) error
+	Void(ctx context.Context, authorizationID string) error
+}
+
+// New creates the provider selected by the configuration. Unknown providers
+// fall back to the fake one so checkouts keep working in development.
+func New(cfg config.PaymentConfig) Provider {
+	switch cfg.Provider {
+	case "fake":
+		return NewFakeProvider(cfg.FakeDeclineAbove)
+	default:
+		logger.Errorf("Unknown payment provider %q, using the fake provider", cfg.Provider)
+		return NewFakeProvider(cfg.FakeDeclineAbove)
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/proto/common"
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+// CartClient talks to the cart service, which the order service hosts
+type CartClient struct {
+	conn   *grpc.ClientConn
+	client order.CartServiceClient
+}
+
+func NewCartClient(cfg *config.Config) (*CartClient, error) {
+	addr := fmt.Sprintf("%s:%s", cfg.Services.Order.Host, cfg.Services.Order.Port)
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(requestmeta.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(requestmeta.StreamClientInterceptor()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to cart service: %w", err)
+	}
+
+	client := order.NewCartServiceClient(conn)
+
+	return &CartClient{
+		conn:   conn,
+		client: client,
+	}, nil
+}
+
+func (c *CartClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *CartClient) GetCart(ctx context.Context, req *order.GetCartRequest) (*order.CartResponse, error) {
+	return c.client.GetCart(ctx, req)
+}
+
+func (c *CartClient) AddItem(ctx context.Context, req *order.AddCartItemRequest) (*order.CartResponse, error) {
+	return c.client.AddItem(ctx, req)
+}
+
+func (c *CartClient) UpdateQuantity(ctx context.Context, req *order.UpdateCartItemRequest) (*order.CartResponse, error) {
+	return c.client.UpdateQuantity(ctx, req)
+}
+
+func (c *CartClient) RemoveItem(ctx context.Context, req *order.RemoveCartItemRequest) (*order.CartResponse, error) {
+	return c.client.RemoveItem(ctx, req)
+}
+
+func (c *CartClient) MergeCarts(ctx context.Context, req *order.MergeCartsRequest) (*order.CartResponse, error) {
+	return c.client.MergeCarts(ctx, req)
+}
+
+func (c *CartClient) ClearCart(ctx context.Context, req *order.ClearCartRequest) (*common.StatusResponse, error) {
+	return c.client.ClearCart(ctx, req)
+}
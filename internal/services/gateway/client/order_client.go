@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+// OrderClient talks to the order service, including its checkouts
+type OrderClient struct {
+	conn     *grpc.ClientConn
+	client   order.OrderServiceClient
+	checkout order.CheckoutServiceClient
+}
+
+func NewOrderClient(cfg *config.Config) (*OrderClient, error) {
+	addr := fmt.Sprintf("%s:%s", cfg.Services.Order.Host, cfg.Services.Order.Port)
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(requestmeta.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(requestmeta.StreamClientInterceptor()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to order service: %w", err)
+	}
+
+	client := order.NewOrderServiceClient(conn)
+
+	return &OrderClient{
+		conn:     conn,
+		client:   client,
+		checkout: order.NewCheckoutServiceClient(conn),
+	}, nil
+}
+
+func (c *OrderClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *OrderClient) CreateOrder(ctx context.Context, req *order.CreateOrderRequest) (*order.OrderResponse, error) {
+	return c.client.CreateOrder(ctx, req)
+}
+
+func (c *OrderClient) GetOrder(ctx context.Context, req *order.GetOrderRequest) (*order.OrderResponse, error) {
+	return c.client.GetOrder(ctx, req)
+}
+
+func (c *OrderClient) ListOrders(ctx context.Context, req *order.ListOrdersRequest) (*order.ListOrdersResponse, error) {
+	return c.client.ListOrders(ctx, req)
+}
+
+func (c *OrderClient) CancelOrder(ctx context.Context, req *order.CancelOrderRequest) (*order.OrderResponse, error) {
+	return c.client.CancelOrder(ctx, req)
+}
+
+func (c *OrderClient) Checkout(ctx context.Context, req *order.CheckoutRequest) (*order.CheckoutResponse, error) {
+	return c.checkout.Checkout(ctx, req)
+}
+
+func (c *OrderClient) GetCheckout(ctx context.Context, req *order.GetCheckoutRequest) (*order.CheckoutResponse, error) {
+	return c.checkout.GetCheckout(ctx, req)
+}
@@ -10,6 +10,7 @@ import (
 	"go-microservice-boilerplate/internal/config"
 	"go-microservice-boilerplate/internal/proto/common"
 	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
 )
 
 type ProductClient struct {
@@ -20,7 +21,11 @@ type ProductClient struct {
 func NewProductClient(cfg *config.Config) (*ProductClient, error) {
 	addr := fmt.Sprintf("%s:%s", cfg.Services.Product.Host, cfg.Services.Product.Port)
 
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(requestmeta.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(requestmeta.StreamClientInterceptor()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to product service: %w", err)
 	}
@@ -49,6 +54,25 @@ func (c *ProductClient) UpdateProduct(ctx context.Context, req *product.UpdatePr
 	return c.client.UpdateProduct(ctx, req)
 }
 
+func (c *ProductClient) BatchGetProducts(ctx context.Context, req *product.BatchGetProductsRequest) (*product.BatchGetProductsResponse, error) {
+	return c.client.BatchGetProducts(ctx, req)
+}
+
+func (c *ProductClient) ExportProducts(ctx context.Context, req *product.ListProductsRequest) (grpc.ServerStreamingClient[product.Product], error) {
+	return c.client.ExportProducts(ctx, req)
+}
+
+// WatchProducts opens the stream product changes are received on until ctx
+// is cancelled
+func (c *ProductClient) WatchProducts(ctx context.Context, req *product.WatchProductsRequest) (grpc.ServerStreamingClient[product.ProductChange], error) {
+	return c.client.WatchProducts(ctx, req)
+}
+
+// ImportProducts opens the stream a bulk import sends its rows on
+func (c *ProductClient) ImportProducts(ctx context.Context) (grpc.ClientStreamingClient[product.ImportProductsRequest, product.ImportProductsResponse], error) {
+	return c.client.ImportProducts(ctx)
+}
+
 func (c *ProductClient) DeleteProduct(ctx context.Context, req *product.DeleteProductRequest) (*common.StatusResponse, error) {
 	return c.client.DeleteProduct(ctx, req)
 }
@@ -56,3 +80,71 @@ func (c *ProductClient) DeleteProduct(ctx context.Context, req *product.DeletePr
 func (c *ProductClient) ListProducts(ctx context.Context, req *product.ListProductsRequest) (*product.ListProductsResponse, error) {
 	return c.client.ListProducts(ctx, req)
 }
+
+func (c *ProductClient) SearchProducts(ctx context.Context, req *product.SearchProductsRequest) (*product.SearchProductsResponse, error) {
+	return c.client.SearchProducts(ctx, req)
+}
+
+func (c *ProductClient) SuggestProducts(ctx context.Context, req *product.SuggestProductsRequest) (*product.SuggestProductsResponse, error) {
+	return c.client.SuggestProducts(ctx, req)
+}
+
+func (c *ProductClient) PublishProduct(ctx context.Context, req *product.PublishProductRequest) (*product.ProductResponse, error) {
+	return c.client.PublishProduct(ctx, req)
+}
+
+func (c *ProductClient) ArchiveProduct(ctx context.Context, req *product.ArchiveProductRequest) (*product.ProductResponse, error) {
+	return c.client.ArchiveProduct(ctx, req)
+}
+
+func (c *ProductClient) AddProductMedia(ctx context.Context, req *product.AddProductMediaRequest) (*product.ProductResponse, error) {
+	return c.client.AddProductMedia(ctx, req)
+}
+
+func (c *ProductClient) DeleteProductMedia(ctx context.Context, req *product.DeleteProductMediaRequest) (*product.ProductResponse, error) {
+	return c.client.DeleteProductMedia(ctx, req)
+}
+
+func (c *ProductClient) AdjustStock(ctx context.Context, req *product.AdjustStockRequest) (*product.ProductResponse, error) {
+	return c.client.AdjustStock(ctx, req)
+}
+
+func (c *ProductClient) ReserveStock(ctx context.Context, req *product.ReserveStockRequest) (*product.ReservationResponse, error) {
+	return c.client.ReserveStock(ctx, req)
+}
+
+func (c *ProductClient) ReleaseStock(ctx context.Context, req *product.ReservationRequest) (*product.ReservationResponse, error) {
+	return c.client.ReleaseStock(ctx, req)
+}
+
+func (c *ProductClient) CommitReservation(ctx context.Context, req *product.ReservationRequest) (*product.ReservationResponse, error) {
+	return c.client.CommitReservation(ctx, req)
+}
+
+func (c *ProductClient) ListStockMovements(ctx context.Context, req *product.ListStockMovementsRequest) (*product.ListStockMovementsResponse, error) {
+	return c.client.ListStockMovements(ctx, req)
+}
+
+func (c *ProductClient) ListLowStockProducts(ctx context.Context, req *product.ListLowStockProductsRequest) (*product.ListProductsResponse, error) {
+	return c.client.ListLowStockProducts(ctx, req)
+}
+
+func (c *ProductClient) CreateCategory(ctx context.Context, req *product.CreateCategoryRequest) (*product.CategoryResponse, error) {
+	return c.client.CreateCategory(ctx, req)
+}
+
+func (c *ProductClient) GetCategory(ctx context.Context, req *product.GetCategoryRequest) (*product.CategoryResponse, error) {
+	return c.client.GetCategory(ctx, req)
+}
+
+func (c *ProductClient) UpdateCategory(ctx context.Context, req *product.UpdateCategoryRequest) (*product.CategoryResponse, error) {
+	return c.client.UpdateCategory(ctx, req)
+}
+
+func (c *ProductClient) DeleteCategory(ctx context.Context, req *product.DeleteCategoryRequest) (*common.StatusResponse, error) {
+	return c.client.DeleteCategory(ctx, req)
+}
+
+func (c *ProductClient) ListCategories(ctx context.Context, req *product.ListCategoriesRequest) (*product.ListCategoriesResponse, error) {
+	return c.client.ListCategories(ctx, req)
+}
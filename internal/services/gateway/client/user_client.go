@@ -10,6 +10,7 @@ import (
 	"go-microservice-boilerplate/internal/config"
 	"go-microservice-boilerplate/internal/proto/common"
 	"go-microservice-boilerplate/internal/proto/user"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
 )
 
 type UserClient struct {
@@ -20,7 +21,11 @@ type UserClient struct {
 func NewUserClient(cfg *config.Config) (*UserClient, error) {
 	addr := fmt.Sprintf("%s:%s", cfg.Services.User.Host, cfg.Services.User.Port)
 
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(requestmeta.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(requestmeta.StreamClientInterceptor()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to user service: %w", err)
 	}
@@ -56,3 +61,11 @@ func (c *UserClient) DeleteUser(ctx context.Context, req *user.DeleteUserRequest
 func (c *UserClient) ListUsers(ctx context.Context, req *user.ListUsersRequest) (*user.ListUsersResponse, error) {
 	return c.client.ListUsers(ctx, req)
 }
+
+func (c *UserClient) BatchGetUsers(ctx context.Context, req *user.BatchGetUsersRequest) (*user.BatchGetUsersResponse, error) {
+	return c.client.BatchGetUsers(ctx, req)
+}
+
+func (c *UserClient) ExportUsers(ctx context.Context, req *user.ListUsersRequest) (grpc.ServerStreamingClient[user.User], error) {
+	return c.client.ExportUsers(ctx, req)
+}
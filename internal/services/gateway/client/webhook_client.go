@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/proto/common"
+	"go-microservice-boilerplate/internal/proto/webhook"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+type WebhookClient struct {
+	conn   *grpc.ClientConn
+	client webhook.WebhookServiceClient
+}
+
+func NewWebhookClient(cfg *config.Config) (*WebhookClient, error) {
+	addr := fmt.Sprintf("%s:%s", cfg.Services.Webhook.Host, cfg.Services.Webhook.Port)
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(requestmeta.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(requestmeta.StreamClientInterceptor()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to webhook service: %w", err)
+	}
+
+	return &WebhookClient{
+		conn:   conn,
+		client: webhook.NewWebhookServiceClient(conn),
+	}, nil
+}
+
+func (c *WebhookClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *WebhookClient) CreateEndpoint(ctx context.Context, req *webhook.CreateEndpointRequest) (*webhook.EndpointResponse, error) {
+	return c.client.CreateEndpoint(ctx, req)
+}
+
+func (c *WebhookClient) GetEndpoint(ctx context.Context, req *webhook.GetEndpointRequest) (*webhook.EndpointResponse, error) {
+	return c.client.GetEndpoint(ctx, req)
+}
+
+func (c *WebhookClient) ListEndpoints(ctx context.Context, req *webhook.ListEndpointsRequest) (*webhook.ListEndpointsResponse, error) {
+	return c.client.ListEndpoints(ctx, req)
+}
+
+func (c *WebhookClient) UpdateEndpoint(ctx context.Context, req *webhook.UpdateEndpointRequest) (*webhook.EndpointResponse, error) {
+	return c.client.UpdateEndpoint(ctx, req)
+}
+
+func (c *WebhookClient) DeleteEndpoint(ctx context.Context, req *webhook.DeleteEndpointRequest) (*common.StatusResponse, error) {
+	return c.client.DeleteEndpoint(ctx, req)
+}
+
+func (c *WebhookClient) ListDeliveries(ctx context.Context, req *webhook.ListDeliveriesRequest) (*webhook.ListDeliveriesResponse, error) {
+	return c.client.ListDeliveries(ctx, req)
+}
+
+func (c *WebhookClient) ReplayDelivery(ctx context.Context, req *webhook.ReplayDeliveryRequest) (*webhook.DeliveryResponse, error) {
+	return c.client.ReplayDelivery(ctx, req)
+}
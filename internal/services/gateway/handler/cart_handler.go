@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// cartHeader carries the token of an anonymous cart, in requests and in the
+// response that created it
+const cartHeader = "X-Cart-ID"
+
+// cartTokenPattern matches the anonymous cart tokens handed out by the gateway
+var cartTokenPattern = regexp.MustCompile(`^[a-f0-9]{32}$`)
+
+// GetCart godoc
+// @Summary Get Cart
+// @Description Get the cart of the caller with live product names, prices and stock. Items whose price changed since they were added have price_changed set; unpublished products and missing SKUs are flagged as unavailable. Anonymous callers identify their cart with the X-Cart-ID header.
+// @Tags Cart
+// @Produce json
+// @Param X-Cart-ID header string false "Token of an anonymous cart"
+// @Router /cart [get]
+func (h *GatewayHandler) GetCart(c *gin.Context) {
+	cartID, ok := callerCart(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.cartClient.GetCart(c.Request.Context(), &order.GetCartRequest{CartId: cartID})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to get cart", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Cart)
+}
+
+// AddCartItem godoc
+// @Summary Add Cart Item
+// @Description Add a quantity of a product SKU, or of one of its variants, to the cart. The first item sets the cart currency. Anonymous callers without X-Cart-ID get a new cart whose token is returned in the X-Cart-ID response header.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param X-Cart-ID header string false "Token of an anonymous cart"
+// @Router /cart/items [post]
+func (h *GatewayHandler) AddCartItem(c *gin.Context) {
+	var req order.AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	cartID, ok := callerCart(c)
+	if !ok {
+		return
+	}
+	req.CartId = cartID
+
+	resp, err := h.cartClient.AddItem(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to add item to cart", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Cart)
+}
+
+// UpdateCartItem godoc
+// @Summary Update Cart Item
+// @Description Set the quantity of a cart item; zero removes it
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Param sku path string true "SKU of the product or variant"
+// @Param X-Cart-ID header string false "Token of an anonymous cart"
+// @Router /cart/items/{product_id}/{sku} [put]
+func (h *GatewayHandler) UpdateCartItem(c *gin.Context) {
+	var body struct {
+		Quantity *int32 `json:"quantity" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	cartID, ok := callerCart(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.cartClient.UpdateQuantity(c.Request.Context(), &order.UpdateCartItemRequest{
+		CartId:    cartID,
+		ProductId: c.Param("product_id"),
+		Sku:       c.Param("sku"),
+		Quantity:  *body.Quantity,
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to update cart item", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Cart)
+}
+
+// RemoveCartItem godoc
+// @Summary Remove Cart Item
+// @Description Remove an item from the cart
+// @Tags Cart
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Param sku path string true "SKU of the product or variant"
+// @Param X-Cart-ID header string false "Token of an anonymous cart"
+// @Router /cart/items/{product_id}/{sku} [delete]
+func (h *GatewayHandler) RemoveCartItem(c *gin.Context) {
+	cartID, ok := callerCart(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.cartClient.RemoveItem(c.Request.Context(), &order.RemoveCartItemRequest{
+		CartId:    cartID,
+		ProductId: c.Param("product_id"),
+		Sku:       c.Param("sku"),
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to remove cart item", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Cart)
+}
+
+// ClearCart godoc
+// @Summary Clear Cart
+// @Description Remove every item from the cart
+// @Tags Cart
+// @Produce json
+// @Param X-Cart-ID header string false "Token of an anonymous cart"
+// @Router /cart [delete]
+func (h *GatewayHandler) ClearCart(c *gin.Context) {
+	cartID, ok := callerCart(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.cartClient.ClearCart(c.Request.Context(), &order.ClearCartRequest{CartId: cartID})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to clear cart", err.Error())
+		return
+	}
+
+	if !resp.Success {
+		response.Error(c, int(resp.Code), resp.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Message, nil)
+}
+
+// MergeCarts godoc
+// @Summary Merge Carts
+// @Description Move the items of the anonymous cart named by X-Cart-ID into the cart of the logged-in caller, typically right after login. Quantities of items in both carts add up and the anonymous cart is deleted.
+// @Tags Cart
+// @Produce json
+// @Security BearerAuth
+// @Param X-Cart-ID header string true "Token of the anonymous cart"
+// @Router /cart/merge [post]
+func (h *GatewayHandler) MergeCarts(c *gin.Context) {
+	token := c.GetHeader(cartHeader)
+	if !cartTokenPattern.MatchString(token) {
+		response.Error(c, http.StatusBadRequest, "Invalid request", cartHeader+" must name an anonymous cart")
+		return
+	}
+
+	resp, err := h.cartClient.MergeCarts(c.Request.Context(), &order.MergeCartsRequest{
+		SourceCartId: "anon:" + token,
+		TargetCartId: "user:" + c.GetString("user_id"),
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to merge carts", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Cart)
+}
+
+// callerCart returns the cart of the caller: the cart of the user when logged in,
+// otherwise the anonymous cart named by X-Cart-ID. Anonymous callers without
+// one get a new token in the X-Cart-ID response header.
+func callerCart(c *gin.Context) (string, bool) {
+	if userID := c.GetString("user_id"); userID != "" {
+		return "user:" + userID, true
+	}
+
+	token := c.GetHeader(cartHeader)
+	if token == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			response.Error(c, http.StatusInternalServerError, "Failed to create cart", err.Error())
+			return "", false
+		}
+		token = hex.EncodeToString(buf)
+		c.Header(cartHeader, token)
+	}
+	if !cartTokenPattern.MatchString(token) {
+		response.Error(c, http.StatusBadRequest, "Invalid request", "invalid "+cartHeader)
+		return "", false
+	}
+
+	return "anon:" + token, true
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// CreateCategory godoc
+// @Summary Create Category
+// @Description Create a category, optionally below a parent. The slug is derived from the name when omitted and the path joins the slugs of the category and its ancestors.
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Router /categories [post]
+func (h *GatewayHandler) CreateCategory(c *gin.Context) {
+	var req product.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	resp, err := h.productClient.CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to create category", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, resp.Status.Message, resp.Category)
+}
+
+// GetCategory godoc
+// @Summary Get Category
+// @Description Get category by ID
+// @Tags Categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Router /categories/{id} [get]
+func (h *GatewayHandler) GetCategory(c *gin.Context) {
+	req := &product.GetCategoryRequest{Id: c.Param("id")}
+	resp, err := h.productClient.GetCategory(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to get category", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Category)
+}
+
+// UpdateCategory godoc
+// @Summary Update Category
+// @Description Rename, reorder or move a category. Changing the slug or parent rewrites the paths of its subcategories and products.
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Router /categories/{id} [put]
+func (h *GatewayHandler) UpdateCategory(c *gin.Context) {
+	var req product.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	req.Id = c.Param("id")
+	resp, err := h.productClient.UpdateCategory(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to update category", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete Category
+// @Description Delete a category. Categories that still have subcategories or products cannot be deleted.
+// @Tags Categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Router /categories/{id} [delete]
+func (h *GatewayHandler) DeleteCategory(c *gin.Context) {
+	req := &product.DeleteCategoryRequest{Id: c.Param("id")}
+	resp, err := h.productClient.DeleteCategory(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to delete category", err.Error())
+		return
+	}
+
+	if !resp.Success {
+		response.Error(c, int(resp.Code), resp.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Message, nil)
+}
+
+// ListCategories godoc
+// @Summary List Categories
+// @Description List the children of a category, or the top level, ordered by depth, sort order and name
+// @Tags Categories
+// @Produce json
+// @Param parent_id query string false "Parent category ID, the top level when omitted"
+// @Param recursive query bool false "Include every descendant instead of direct children only"
+// @Router /categories [get]
+func (h *GatewayHandler) ListCategories(c *gin.Context) {
+	req := &product.ListCategoriesRequest{ParentId: c.Query("parent_id")}
+	if value := c.Query("recursive"); value != "" {
+		recursive, err := strconv.ParseBool(value)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request", "recursive must be a boolean")
+			return
+		}
+		req.Recursive = recursive
+	}
+
+	resp, err := h.productClient.ListCategories(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to list categories", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Categories)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// idempotencyKeyHeader names the key that makes a checkout safe to retry
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// Checkout statuses reported by the order service
+const (
+	checkoutCompleted = "completed"
+	checkoutFailed    = "failed"
+)
+
+// Checkout godoc
+// @Summary Checkout
+// @Description Buy the items of the body, or the whole cart of the caller when the body has none. Stock is reserved, a pending order created and the payment authorized, then every step is committed, or rolled back when one fails. Retrying with the same Idempotency-Key returns the same checkout instead of buying twice. Answers 201 when the checkout completed, 409 when it failed and 202 when it is still running; poll GET /checkout/{id} until it finishes.
+// @Tags Checkout
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Idempotency-Key header string true "Unique key of this purchase, chosen by the client"
+// @Router /checkout [post]
+func (h *GatewayHandler) Checkout(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid request", idempotencyKeyHeader+" header is required")
+		return
+	}
+
+	// The body is optional, without items the cart is checked out
+	var body struct {
+		Items    []*order.OrderItemRequest `json:"items"`
+		Currency string                    `json:"currency"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+	}
+
+	resp, err := h.orderClient.Checkout(c.Request.Context(), &order.CheckoutRequest{
+		UserId:         c.GetString("user_id"),
+		IdempotencyKey: key,
+		Items:          body.Items,
+		Currency:       body.Currency,
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to check out", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	checkoutResult(c, resp.Checkout)
+}
+
+// GetCheckout godoc
+// @Summary Get Checkout
+// @Description Get a checkout of the caller, to follow one that was still running
+// @Tags Checkout
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Checkout ID"
+// @Router /checkout/{id} [get]
+func (h *GatewayHandler) GetCheckout(c *gin.Context) {
+	resp, err := h.orderClient.GetCheckout(c.Request.Context(), &order.GetCheckoutRequest{
+		Id:     c.Param("id"),
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to get checkout", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Checkout)
+}
+
+// checkoutResult answers a checkout request according to how far the
+// checkout got
+func checkoutResult(c *gin.Context, checkout *order.Checkout) {
+	switch checkout.Status {
+	case checkoutCompleted:
+		response.Success(c, http.StatusCreated, "Checkout completed", checkout)
+	case checkoutFailed:
+		response.Error(c, http.StatusConflict, "Checkout failed: "+checkout.Error, checkout)
+	default:
+		response.Success(c, http.StatusAccepted, "Checkout in progress", checkout)
+	}
+}
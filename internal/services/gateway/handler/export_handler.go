@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/proto/user"
+	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/response"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// Export formats and the content types that select them
+const (
+	exportJSON   = "json"
+	exportNDJSON = "ndjson"
+	exportCSV    = "csv"
+
+	mimeNDJSON = "application/x-ndjson"
+	mimeCSV    = "text/csv"
+)
+
+// exportFlushEvery is how many records are written between flushes of the
+// response
+const exportFlushEvery = 100
+
+var userExportColumns = []string{"id", "name", "email", "phone", "created_at", "updated_at"}
+
+// productExportColumns match the columns a product import reads, so an
+// export can be edited and imported again
+var productExportColumns = []string{
+	"id", "sku", "name", "description", "price", "currency", "quantity",
+	"category", "status", "reorder_threshold", "created_at", "updated_at",
+}
+
+// ExportUsers godoc
+// @Summary Export Users
+// @Description Download every user matching the filters as a JSON array, NDJSON or CSV, selected by the format parameter or the Accept header. The response is streamed, so exports are not bound by the list limit.
+// @Tags Users
+// @Produce json,text/csv,application/x-ndjson
+// @Param format query string false "Export format: json, ndjson or csv"
+// @Param search query string false "Search term for name or email"
+// @Router /users/export [get]
+func (h *GatewayHandler) ExportUsers(c *gin.Context) {
+	format, err := exportFormat(c)
+	if err != nil {
+		response.Error(c, http.StatusNotAcceptable, "Invalid request", err.Error())
+		return
+	}
+
+	req := &user.ListUsersRequest{Search: c.Query("search")}
+	stream, err := h.userClient.ExportUsers(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to export users", err.Error())
+		return
+	}
+
+	writeExport(c, "users", format, stream, userExportColumns, func(u *user.User) []string {
+		return []string{u.Id, u.Name, u.Email, u.Phone, exportTime(u.CreatedAt), exportTime(u.UpdatedAt)}
+	})
+}
+
+// ExportProducts godoc
+// @Summary Export Products
+// @Description Download every product matching the filters as a JSON array, NDJSON or CSV, selected by the format parameter or the Accept header. Takes the same filters as listing products. The CSV columns can be imported again.
+// @Tags Products
+// @Produce json,text/csv,application/x-ndjson
+// @Param format query string false "Export format: json, ndjson or csv"
+// @Param search query string false "Search term for name or description"
+// @Param category query string false "Filter by category"
+// @Param category_tree query string false "Filter by a category and all its subcategories"
+// @Param status query string false "Filter by status, catalog managers only for unpublished products"
+// @Param currency query string false "Currency to price products in"
+// @Router /products/export [get]
+func (h *GatewayHandler) ExportProducts(c *gin.Context) {
+	format, err := exportFormat(c)
+	if err != nil {
+		response.Error(c, http.StatusNotAcceptable, "Invalid request", err.Error())
+		return
+	}
+
+	req, ok := productListRequest(c)
+	if !ok {
+		return
+	}
+	stream, err := h.productClient.ExportProducts(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to export products", err.Error())
+		return
+	}
+
+	writeExport(c, "products", format, stream, productExportColumns, func(p *product.Product) []string {
+		var price, currency string
+		if p.Price != nil {
+			price = money.Money{Amount: p.Price.Amount, Currency: p.Price.Currency}.Decimal()
+			currency = p.Price.Currency
+		}
+		return []string{
+			p.Id, p.Sku, p.Name, p.Description, price, currency,
+			strconv.Itoa(int(p.Quantity)), p.Category, p.Status,
+			strconv.Itoa(int(p.ReorderThreshold)), exportTime(p.CreatedAt), exportTime(p.UpdatedAt),
+		}
+	})
+}
+
+// exportFormat picks the export format from the format parameter, falling
+// back to the Accept header and then JSON
+func exportFormat(c *gin.Context) (string, error) {
+	switch format := c.Query("format"); format {
+	case exportJSON, exportNDJSON, exportCSV:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected json, ndjson or csv", format)
+	}
+
+	switch c.NegotiateFormat(gin.MIMEJSON, mimeCSV, mimeNDJSON) {
+	case mimeCSV:
+		return exportCSV, nil
+	case mimeNDJSON:
+		return exportNDJSON, nil
+	default:
+		return exportJSON, nil
+	}
+}
+
+// writeExport streams the records of an export to the response as they
+// arrive. The first record is received before anything is written, so an
+// export the service rejects still gets a proper error response; failures
+// after that can only end the download early.
+func writeExport[T any](c *gin.Context, name, format string, stream grpc.ServerStreamingClient[T], columns []string, row func(*T) []string) {
+	first, err := stream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), fmt.Sprintf("Failed to export %s", name), err.Error())
+		return
+	}
+
+	contentType := map[string]string{exportJSON: gin.MIMEJSON, exportNDJSON: mimeNDJSON, exportCSV: mimeCSV}[format]
+	c.Header("Content-Type", contentType+"; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+	c.Status(http.StatusOK)
+
+	w := newExportWriter(c.Writer, format, columns, row)
+	for record, count := first, 1; record != nil; count++ {
+		if err := w.write(record); err != nil {
+			logger.Warnf("Failed to write %s export: %v", name, err)
+			return
+		}
+		if count%exportFlushEvery == 0 {
+			w.flush()
+			c.Writer.Flush()
+		}
+
+		record, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Errorf("Failed to export %s: %v", name, err)
+			w.flush()
+			return
+		}
+	}
+
+	if err := w.close(); err != nil {
+		logger.Warnf("Failed to write %s export: %v", name, err)
+	}
+	c.Writer.Flush()
+}
+
+// exportWriter encodes the records of an export in one format
+type exportWriter[T any] struct {
+	out     io.Writer
+	format  string
+	columns []string
+	row     func(*T) []string
+	csv     *csv.Writer
+	written int
+}
+
+func newExportWriter[T any](out io.Writer, format string, columns []string, row func(*T) []string) *exportWriter[T] {
+	w := &exportWriter[T]{out: out, format: format, columns: columns, row: row}
+	if format == exportCSV {
+		w.csv = csv.NewWriter(out)
+	}
+	return w
+}
+
+func (w *exportWriter[T]) write(record *T) error {
+	defer func() { w.written++ }()
+
+	switch w.format {
+	case exportCSV:
+		if w.written == 0 {
+			if err := w.csv.Write(w.columns); err != nil {
+				return err
+			}
+		}
+		return w.csv.Write(w.row(record))
+	case exportNDJSON:
+		return json.NewEncoder(w.out).Encode(record)
+	default:
+		separator := ","
+		if w.written == 0 {
+			separator = "["
+		}
+		if _, err := io.WriteString(w.out, separator); err != nil {
+			return err
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		_, err = w.out.Write(data)
+		return err
+	}
+}
+
+// flush writes out buffered CSV rows
+func (w *exportWriter[T]) flush() {
+	if w.csv != nil {
+		w.csv.Flush()
+	}
+}
+
+// close finishes the export, writing the CSV header or an empty array when
+// there were no records
+func (w *exportWriter[T]) close() error {
+	switch w.format {
+	case exportCSV:
+		if w.written == 0 {
+			if err := w.csv.Write(w.columns); err != nil {
+				return err
+			}
+		}
+		w.csv.Flush()
+		return w.csv.Error()
+	case exportJSON:
+		closing := "]\n"
+		if w.written == 0 {
+			closing = "[]\n"
+		}
+		_, err := io.WriteString(w.out, closing)
+		return err
+	}
+	return nil
+}
+
+// exportTime formats a Unix timestamp for CSV, empty when unset
+func exportTime(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
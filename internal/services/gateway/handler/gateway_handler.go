@@ -1,29 +1,64 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"go-microservice-boilerplate/internal/blobstore"
+	"go-microservice-boilerplate/internal/middleware"
+	"go-microservice-boilerplate/internal/proto/common"
 	"go-microservice-boilerplate/internal/proto/product"
 	"go-microservice-boilerplate/internal/proto/user"
 	"go-microservice-boilerplate/internal/services/gateway/client"
 	"go-microservice-boilerplate/internal/utils/response"
+	"go-microservice-boilerplate/pkg/money"
 )
 
 type GatewayHandler struct {
 	userClient    *client.UserClient
 	productClient *client.ProductClient
+	orderClient   *client.OrderClient
+	cartClient    *client.CartClient
+	webhookClient *client.WebhookClient
+	blobs         blobstore.BlobStore
+	// maxUploadSize is the largest media file accepted, in bytes
+	maxUploadSize int64
+	// streams is cancelled by CloseStreams to end long-lived watches
+	streams      context.Context
+	closeStreams context.CancelFunc
 }
 
-func NewGatewayHandler(userClient *client.UserClient, productClient *client.ProductClient) *GatewayHandler {
+func NewGatewayHandler(userClient *client.UserClient, productClient *client.ProductClient, orderClient *client.OrderClient, cartClient *client.CartClient, webhookClient *client.WebhookClient, blobs blobstore.BlobStore, maxUploadSize int64) *GatewayHandler {
+	streams, closeStreams := context.WithCancel(context.Background())
 	return &GatewayHandler{
 		userClient:    userClient,
 		productClient: productClient,
+		orderClient:   orderClient,
+		cartClient:    cartClient,
+		webhookClient: webhookClient,
+		blobs:         blobs,
+		maxUploadSize: maxUploadSize,
+		streams:       streams,
+		closeStreams:  closeStreams,
 	}
 }
 
+// CloseStreams ends every open product watch, which would otherwise hold
+// up a graceful shutdown until it times out
+func (h *GatewayHandler) CloseStreams() {
+	h.closeStreams()
+}
+
 func (h *GatewayHandler) RegisterRoutes(router *gin.Engine) {
 	api := router.Group("/api/v1")
 
@@ -34,8 +69,10 @@ func (h *GatewayHandler) RegisterRoutes(router *gin.Engine) {
 	users := api.Group("/users")
 	{
 		users.POST("", h.CreateUser)
+		users.GET("/export", h.ExportUsers)
 		users.GET("/:id", h.GetUser)
 		users.PUT("/:id", h.UpdateUser)
+		users.PATCH("/:id", h.PatchUser)
 		users.DELETE("/:id", h.DeleteUser)
 		users.GET("", h.ListUsers)
 	}
@@ -44,10 +81,80 @@ func (h *GatewayHandler) RegisterRoutes(router *gin.Engine) {
 	products := api.Group("/products")
 	{
 		products.POST("", h.CreateProduct)
+		products.GET("/search", h.SearchProducts)
+		products.GET("/suggest", h.SuggestProducts)
+		products.GET("/low-stock", middleware.RequireRole(catalogManagerRoles...), h.ListLowStockProducts)
+		products.GET("/export", h.ExportProducts)
+		products.GET("/watch", h.WatchProducts)
+		products.GET("/watch/ws", h.WatchProductsWebSocket)
 		products.GET("/:id", h.GetProduct)
 		products.PUT("/:id", h.UpdateProduct)
+		products.PATCH("/:id", h.PatchProduct)
 		products.DELETE("/:id", h.DeleteProduct)
 		products.GET("", h.ListProducts)
+		products.POST("/:id/stock/adjust", middleware.RequireRole(catalogManagerRoles...), h.AdjustStock)
+		products.GET("/:id/stock-movements", middleware.RequireRole(catalogManagerRoles...), h.ListStockMovements)
+		products.POST("/:id/publish", middleware.RequireRole(catalogManagerRoles...), h.PublishProduct)
+		products.POST("/:id/archive", middleware.RequireRole(catalogManagerRoles...), h.ArchiveProduct)
+		products.POST("/:id/media", middleware.RequireRole(catalogManagerRoles...), h.UploadProductMedia)
+		products.DELETE("/:id/media/:media_id", middleware.RequireRole(catalogManagerRoles...), h.DeleteProductMedia)
+	}
+
+	// Category routes
+	categories := api.Group("/categories")
+	{
+		categories.POST("", h.CreateCategory)
+		categories.GET("", h.ListCategories)
+		categories.GET("/:id", h.GetCategory)
+		categories.PUT("/:id", h.UpdateCategory)
+		categories.DELETE("/:id", h.DeleteCategory)
+	}
+
+	// Inventory routes
+	inventory := api.Group("/inventory", middleware.RequireRole(catalogManagerRoles...))
+	{
+		inventory.POST("/reservations", h.ReserveStock)
+		inventory.POST("/reservations/:id/release", h.ReleaseStock)
+		inventory.POST("/reservations/:id/commit", h.CommitReservation)
+	}
+
+	// Cart routes, for anonymous callers too
+	cart := api.Group("/cart")
+	{
+		cart.GET("", h.GetCart)
+		cart.DELETE("", h.ClearCart)
+		cart.POST("/items", h.AddCartItem)
+		cart.PUT("/items/:product_id/:sku", h.UpdateCartItem)
+		cart.DELETE("/items/:product_id/:sku", h.RemoveCartItem)
+		cart.POST("/merge", middleware.RequireAuth(), h.MergeCarts)
+	}
+
+	// Order routes
+	orders := api.Group("/orders", middleware.RequireAuth())
+	{
+		orders.POST("", h.CreateOrder)
+		orders.GET("", h.ListOrders)
+		orders.GET("/:id", h.GetOrder)
+		orders.POST("/:id/cancel", h.CancelOrder)
+	}
+
+	// Checkout routes
+	checkout := api.Group("/checkout", middleware.RequireAuth())
+	{
+		checkout.POST("", h.Checkout)
+		checkout.GET("/:id", h.GetCheckout)
+	}
+
+	// Webhook routes, for admins only
+	webhooks := api.Group("/webhooks", middleware.RequireRole(middleware.RoleAdmin))
+	{
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("", h.ListWebhooks)
+		webhooks.GET("/:id", h.GetWebhook)
+		webhooks.PATCH("/:id", h.UpdateWebhook)
+		webhooks.DELETE("/:id", h.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", h.ListWebhookDeliveries)
+		webhooks.POST("/:id/deliveries/:delivery_id/replay", h.ReplayWebhookDelivery)
 	}
 }
 
@@ -176,27 +283,44 @@ func (h *GatewayHandler) DeleteUser(c *gin.Context) {
 
 // ListUsers godoc
 // @Summary List Users
-// @Description Get paginated list of users
+// @Description Get paginated list of users. Pass next_page_token back as page_token for keyset pagination. Pass ids instead to fetch up to 100 users by ID in one call, returned in the order requested with the IDs that were not found in missing_ids.
 // @Tags Users
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Items per page"
-// @Param search query string false "Search term"
+// @Param page_token query string false "Cursor from a previous page's next_page_token"
+// @Param total query string false "Total count mode" Enums(exact, estimated, none)
+// @Param search query string false "Full-text search, ranked by relevance"
+// @Param ids query string false "Comma-separated user IDs to fetch, ignoring the other parameters"
 // @Router /users [get]
 func (h *GatewayHandler) ListUsers(c *gin.Context) {
+	if ids := idsQuery(c); len(ids) > 0 {
+		h.batchGetUsers(c, ids)
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	pageToken := c.Query("page_token")
 	search := c.Query("search")
 
+	totalMode, err := parseTotalMode(c.Query("total"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
 	req := &user.ListUsersRequest{
-		Page:   int32(page),
-		Limit:  int32(limit),
-		Search: search,
+		Page:      int32(page),
+		Limit:     int32(limit),
+		PageToken: pageToken,
+		TotalMode: totalMode,
+		Search:    search,
 	}
 
 	resp, err := h.userClient.ListUsers(c.Request.Context(), req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to list users", err.Error())
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to list users", err.Error())
 		return
 	}
 
@@ -206,13 +330,8 @@ func (h *GatewayHandler) ListUsers(c *gin.Context) {
 	}
 
 	result := gin.H{
-		"users": resp.Users,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       resp.Total,
-			"total_pages": (resp.Total + int32(limit) - 1) / int32(limit),
-		},
+		"users":      resp.Users,
+		"pagination": paginationResult(page, limit, pageToken, resp.Total, resp.TotalEstimated, resp.NextPageToken),
 	}
 
 	response.Success(c, http.StatusOK, resp.Status.Message, result)
@@ -231,10 +350,14 @@ func (h *GatewayHandler) CreateProduct(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
 		return
 	}
+	if req.Status != "" && req.Status != "draft" && !catalogManager(c) {
+		response.Error(c, http.StatusForbidden, "Only catalog managers can publish products", nil)
+		return
+	}
 
 	resp, err := h.productClient.CreateProduct(c.Request.Context(), &req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to create product", err.Error())
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to create product", err.Error())
 		return
 	}
 
@@ -248,18 +371,20 @@ func (h *GatewayHandler) CreateProduct(c *gin.Context) {
 
 // GetProduct godoc
 // @Summary Get Product
-// @Description Get product by ID
+// @Description Get product by ID. Pass price_list or price_currency to get a display_price, converted at the current exchange rate when no price is stored in that currency. Drafts and archived products are only found by catalog managers.
 // @Tags Products
 // @Produce json
 // @Param id path string true "Product ID"
+// @Param price_list query string false "Price list of the display price" default(retail)
+// @Param price_currency query string false "Currency of the display price"
 // @Router /products/{id} [get]
 func (h *GatewayHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
 
-	req := &product.GetProductRequest{Id: id}
+	req := &product.GetProductRequest{Id: id, Pricing: priceQuery(c), IncludeUnpublished: catalogManager(c)}
 	resp, err := h.productClient.GetProduct(c.Request.Context(), req)
 	if err != nil {
-		response.Error(c, http.StatusNotFound, "Product not found", err.Error())
+		response.Error(c, httpStatusFromError(err, http.StatusNotFound), "Product not found", err.Error())
 		return
 	}
 
@@ -273,7 +398,7 @@ func (h *GatewayHandler) GetProduct(c *gin.Context) {
 
 // UpdateProduct godoc
 // @Summary Update Product
-// @Description Update product information
+// @Description Update product information. Only catalog managers can change the status.
 // @Tags Products
 // @Accept json
 // @Produce json
@@ -288,10 +413,15 @@ func (h *GatewayHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	if changesStatus(&req, nil) && !catalogManager(c) {
+		response.Error(c, http.StatusForbidden, "Only catalog managers can change the product status", nil)
+		return
+	}
+
 	req.Id = id
 	resp, err := h.productClient.UpdateProduct(c.Request.Context(), &req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to update product", err.Error())
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to update product", err.Error())
 		return
 	}
 
@@ -330,30 +460,136 @@ func (h *GatewayHandler) DeleteProduct(c *gin.Context) {
 
 // ListProducts godoc
 // @Summary List Products
-// @Description Get paginated list of products
+// @Description Get paginated list of products. Pass next_page_token back as page_token for keyset pagination. Only published products are listed unless the caller is a catalog manager. Pass ids instead to fetch up to 100 products by ID in one call, returned in the order requested with the IDs that were not found in missing_ids; only the price parameters apply then.
 // @Tags Products
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
-// @Param search query string false "Search term"
-// @Param category query string false "Category filter"
+// @Param page_token query string false "Cursor from a previous page's next_page_token"
+// @Param total query string false "Total count mode" Enums(exact, estimated, none)
+// @Param search query string false "Full-text search over name, SKU and description"
+// @Param category query string false "Exact category"
+// @Param categories query string false "Comma-separated exact categories"
+// @Param category_tree query string false "Category path including its subcategories, e.g. electronics"
+// @Param sort_by query string false "Sort field, relevance by default when searching" Enums(relevance, created_at, updated_at, name, price, quantity)
+// @Param sort_order query string false "Sort direction" Enums(asc, desc)
+// @Param min_price query string false "Minimum price as a decimal amount, e.g. 19.99"
+// @Param max_price query string false "Maximum price as a decimal amount"
+// @Param currency query string false "Currency of the price filters, and of the products listed when sorting by price" default(USD)
+// @Param min_quantity query int false "Minimum quantity"
+// @Param max_quantity query int false "Maximum quantity"
+// @Param in_stock query bool false "Only products with stock"
+// @Param created_after query string false "RFC3339 time or unix seconds"
+// @Param created_before query string false "RFC3339 time or unix seconds"
+// @Param price_list query string false "Price list of the display prices" default(retail)
+// @Param price_currency query string false "Currency of the display prices"
+// @Param status query string false "Lifecycle state, catalog managers only for draft and archived" Enums(draft, published, archived)
+// @Param attr.{name} query string false "Products whose attribute equals the value, e.g. attr.color=red"
+// @Param attr.{name}.min query number false "Products whose numeric attribute is at least the value"
+// @Param attr.{name}.max query number false "Products whose numeric attribute is at most the value"
+// @Param ids query string false "Comma-separated product IDs to fetch"
 // @Router /products [get]
 func (h *GatewayHandler) ListProducts(c *gin.Context) {
+	if ids := idsQuery(c); len(ids) > 0 {
+		h.batchGetProducts(c, ids)
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	search := c.Query("search")
-	category := c.Query("category")
+	pageToken := c.Query("page_token")
 
-	req := &product.ListProductsRequest{
-		Page:     int32(page),
-		Limit:    int32(limit),
-		Search:   search,
-		Category: category,
+	totalMode, err := parseTotalMode(c.Query("total"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	req, ok := productListRequest(c)
+	if !ok {
+		return
 	}
+	req.Page = int32(page)
+	req.Limit = int32(limit)
+	req.PageToken = pageToken
+	req.TotalMode = totalMode
 
 	resp, err := h.productClient.ListProducts(c.Request.Context(), req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to list products", err.Error())
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to list products", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	result := gin.H{
+		"products":   resp.Products,
+		"pagination": paginationResult(page, limit, pageToken, resp.Total, resp.TotalEstimated, resp.NextPageToken),
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, result)
+}
+
+// SearchProducts godoc
+// @Summary Search Products
+// @Description Search products and return facet counts for categories, price buckets and stock. Each facet ignores its own filter so the counts describe the alternatives.
+// @Tags Products
+// @Produce json
+// @Param q query string false "Full-text search over name, SKU and description"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param categories query string false "Comma-separated exact categories"
+// @Param sort_by query string false "Sort field, relevance by default when searching" Enums(relevance, created_at, updated_at, name, price, quantity)
+// @Param sort_order query string false "Sort direction" Enums(asc, desc)
+// @Param min_price query string false "Minimum price as a decimal amount, e.g. 19.99"
+// @Param max_price query string false "Maximum price as a decimal amount"
+// @Param currency query string false "Currency of the price filters and buckets, and of the products found when sorting by price" default(USD)
+// @Param in_stock query bool false "Only products with stock"
+// @Param price_boundaries query string false "Comma-separated ascending price bucket boundaries as decimal amounts"
+// @Router /products/search [get]
+func (h *GatewayHandler) SearchProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	// Reuse the listing parser for the filters both endpoints share
+	filters := &product.ListProductsRequest{}
+	if err := bindProductFilters(c, filters); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	req := &product.SearchProductsRequest{
+		Query:      c.Query("q"),
+		Page:       int32(page),
+		Limit:      int32(limit),
+		SortBy:     filters.SortBy,
+		SortOrder:  filters.SortOrder,
+		Categories: filters.Categories,
+		MinPrice:   filters.MinPrice,
+		MaxPrice:   filters.MaxPrice,
+		Currency:   filters.Currency,
+		InStock:    filters.InStock,
+
+		IncludeUnpublished: catalogManager(c),
+	}
+
+	if value := c.Query("price_boundaries"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			boundary, err := money.Parse(part, filters.Currency)
+			if err != nil {
+				response.Error(c, http.StatusBadRequest, "Invalid request", "price_boundaries must be decimal amounts")
+				return
+			}
+			req.PriceBoundaries = append(req.PriceBoundaries, boundary.Amount)
+		}
+	}
+
+	resp, err := h.productClient.SearchProducts(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to search products", err.Error())
 		return
 	}
 
@@ -363,14 +599,338 @@ func (h *GatewayHandler) ListProducts(c *gin.Context) {
 	}
 
 	result := gin.H{
-		"products": resp.Products,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       resp.Total,
-			"total_pages": (resp.Total + int32(limit) - 1) / int32(limit),
-		},
+		"products":   resp.Products,
+		"facets":     resp.Facets,
+		"pagination": paginationResult(page, limit, "", &resp.Total, false, ""),
 	}
 
 	response.Success(c, http.StatusOK, resp.Status.Message, result)
 }
+
+// SuggestProducts godoc
+// @Summary Suggest Products
+// @Description Typeahead suggestions of product names and SKUs starting with the query
+// @Tags Products
+// @Produce json
+// @Param q query string true "Name or SKU prefix"
+// @Param limit query int false "Maximum suggestions" default(10)
+// @Router /products/suggest [get]
+func (h *GatewayHandler) SuggestProducts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	req := &product.SuggestProductsRequest{
+		Query: c.Query("q"),
+		Limit: int32(limit),
+	}
+
+	resp, err := h.productClient.SuggestProducts(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to suggest products", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Suggestions)
+}
+
+// parseTotalMode maps the total query parameter onto the proto enum
+// batchGetUsers responds with the users of ids, fetched in one call
+func (h *GatewayHandler) batchGetUsers(c *gin.Context, ids []string) {
+	resp, err := h.userClient.BatchGetUsers(c.Request.Context(), &user.BatchGetUsersRequest{Ids: ids})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to get users", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, gin.H{
+		"users":       resp.Users,
+		"missing_ids": resp.MissingIds,
+	})
+}
+
+// batchGetProducts responds with the products of ids, fetched in one call
+func (h *GatewayHandler) batchGetProducts(c *gin.Context, ids []string) {
+	req := &product.BatchGetProductsRequest{Ids: ids, Pricing: priceQuery(c), IncludeUnpublished: catalogManager(c)}
+	resp, err := h.productClient.BatchGetProducts(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to get products", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, gin.H{
+		"products":    resp.Products,
+		"missing_ids": resp.MissingIds,
+	})
+}
+
+// idsQuery reads the ids parameter, given comma-separated or repeated
+func idsQuery(c *gin.Context) []string {
+	var ids []string
+	for _, value := range c.QueryArray("ids") {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// productListRequest reads the product filters shared by listing and
+// exporting, responding with an error when they are invalid
+func productListRequest(c *gin.Context) (*product.ListProductsRequest, bool) {
+	req := &product.ListProductsRequest{
+		Search:   c.Query("search"),
+		Category: c.Query("category"),
+		Pricing:  priceQuery(c),
+
+		CategoryTree: c.Query("category_tree"),
+		Attributes:   attributeFilters(c),
+
+		Status:             c.Query("status"),
+		IncludeUnpublished: catalogManager(c),
+	}
+	if req.Status != "" && req.Status != "published" && !req.IncludeUnpublished {
+		response.Error(c, http.StatusForbidden, "Only catalog managers can list unpublished products", nil)
+		return nil, false
+	}
+
+	if err := bindProductFilters(c, req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return nil, false
+	}
+
+	return req, true
+}
+
+func parseTotalMode(value string) (common.TotalMode, error) {
+	switch value {
+	case "":
+		return common.TotalMode_TOTAL_MODE_UNSPECIFIED, nil
+	case "exact":
+		return common.TotalMode_TOTAL_MODE_EXACT, nil
+	case "estimated":
+		return common.TotalMode_TOTAL_MODE_ESTIMATED, nil
+	case "none":
+		return common.TotalMode_TOTAL_MODE_NONE, nil
+	default:
+		return 0, fmt.Errorf("unsupported total mode %q", value)
+	}
+}
+
+// bindProductFilters parses the sorting and filtering query parameters of
+// the product listing into req
+func bindProductFilters(c *gin.Context, req *product.ListProductsRequest) error {
+	req.SortBy = c.Query("sort_by")
+	req.SortOrder = c.Query("sort_order")
+	if req.SortOrder != "" && req.SortOrder != "asc" && req.SortOrder != "desc" {
+		return fmt.Errorf("sort_order must be asc or desc")
+	}
+
+	for _, value := range c.QueryArray("categories") {
+		for _, category := range strings.Split(value, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				req.Categories = append(req.Categories, category)
+			}
+		}
+	}
+
+	req.Currency = money.NormalizeCurrency(c.DefaultQuery("currency", money.DefaultCurrency))
+	if !money.Supported(req.Currency) {
+		return fmt.Errorf("unsupported currency %q", req.Currency)
+	}
+
+	var err error
+	if req.MinPrice, err = queryMoney(c, "min_price", req.Currency); err != nil {
+		return err
+	}
+	if req.MaxPrice, err = queryMoney(c, "max_price", req.Currency); err != nil {
+		return err
+	}
+	if req.MinQuantity, err = queryInt32(c, "min_quantity"); err != nil {
+		return err
+	}
+	if req.MaxQuantity, err = queryInt32(c, "max_quantity"); err != nil {
+		return err
+	}
+	if value := c.Query("in_stock"); value != "" {
+		if req.InStock, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("in_stock must be a boolean")
+		}
+	}
+	if req.CreatedAfter, err = queryUnixTime(c, "created_after"); err != nil {
+		return err
+	}
+	if req.CreatedBefore, err = queryUnixTime(c, "created_before"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// priceQuery reads the optional price_list and price_currency parameters
+func priceQuery(c *gin.Context) *product.PriceQuery {
+	list, currency := c.Query("price_list"), c.Query("price_currency")
+	if list == "" && currency == "" {
+		return nil
+	}
+	return &product.PriceQuery{List: list, Currency: currency}
+}
+
+// attributeFilters reads attr.<name>, attr.<name>.min and attr.<name>.max
+// query parameters, in a stable order
+func attributeFilters(c *gin.Context) []*product.AttributeFilter {
+	query := c.Request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if strings.HasPrefix(key, "attr.") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var filters []*product.AttributeFilter
+	for _, key := range keys {
+		name, op := strings.TrimPrefix(key, "attr."), "eq"
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name, op = name[:i], name[i+1:]
+		}
+		for _, value := range query[key] {
+			filters = append(filters, &product.AttributeFilter{Name: name, Op: op, Value: value})
+		}
+	}
+	return filters
+}
+
+// queryMoney parses an optional decimal amount such as 19.99 into minor
+// units of currency
+func queryMoney(c *gin.Context, key, currency string) (*int64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := money.Parse(value, currency)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a decimal amount in %s", key, currency)
+	}
+	return &parsed.Amount, nil
+}
+
+// queryInt32 parses an optional integer query parameter
+func queryInt32(c *gin.Context, key string) (*int32, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+	result := int32(parsed)
+	return &result, nil
+}
+
+// queryUnixTime parses an optional RFC3339 or unix seconds query parameter
+func queryUnixTime(c *gin.Context, key string) (int64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return seconds, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an RFC3339 time or unix seconds", key)
+	}
+	return parsed.Unix(), nil
+}
+
+// paginationResult builds the pagination block shared by list endpoints.
+// Page numbers are only meaningful for offset pagination, and the total is
+// omitted when the backend skipped counting.
+func paginationResult(page, limit int, pageToken string, total *int32, estimated bool, nextPageToken string) gin.H {
+	result := gin.H{
+		"limit":           limit,
+		"next_page_token": nextPageToken,
+	}
+
+	if pageToken == "" {
+		result["page"] = page
+	}
+
+	if total != nil {
+		result["total"] = *total
+		result["total_estimated"] = estimated
+		if limit > 0 {
+			result["total_pages"] = (*total + int32(limit) - 1) / int32(limit)
+		}
+	}
+
+	return result
+}
+
+// catalogManagerRoles may see and change unpublished products
+var catalogManagerRoles = []string{middleware.RoleAdmin, middleware.RoleCatalogManager}
+
+// catalogManager reports whether the caller may see unpublished products
+func catalogManager(c *gin.Context) bool {
+	return middleware.HasRole(c, catalogManagerRoles...)
+}
+
+// changesStatus reports whether a product update names the status, through
+// its value, a member of the patch or a path of the field mask. Names are
+// matched in any case, like JSON members are decoded.
+func changesStatus(req *product.UpdateProductRequest, fields map[string]json.RawMessage) bool {
+	if req.Status != "" {
+		return true
+	}
+	for field := range fields {
+		if strings.EqualFold(field, "status") {
+			return true
+		}
+	}
+	for _, path := range req.GetUpdateMask().GetPaths() {
+		if strings.EqualFold(path, "status") {
+			return true
+		}
+	}
+	return false
+}
+
+// httpStatusFromError translates the gRPC status of err into an HTTP status,
+// falling back to the given status for codes without a closer match
+func httpStatusFromError(err error, fallback int) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.FailedPrecondition, codes.Aborted:
+		return http.StatusConflict
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return fallback
+	}
+}
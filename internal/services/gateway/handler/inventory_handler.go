@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// AdjustStock godoc
+// @Summary Adjust Product Stock
+// @Description Atomically add to or remove from the stock of a product. The reason is one of sale, restock or adjustment (the default). Removals fail with 409 when not enough stock is left. Pass variant_id to adjust the stock of a variant. Requires the catalog_manager or admin role.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Security BearerAuth
+// @Router /products/{id}/stock/adjust [post]
+func (h *GatewayHandler) AdjustStock(c *gin.Context) {
+	var req product.AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	req.Id = c.Param("id")
+	resp, err := h.productClient.AdjustStock(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to adjust stock", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Product)
+}
+
+// ListStockMovements godoc
+// @Summary List Stock Movements
+// @Description Ledger of every stock change of a product, newest first, with the delta, reason, actor, correlation ID and resulting balance. Requires the catalog_manager or admin role.
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variant_id query string false "Only movements of this variant"
+// @Param from query string false "Only movements at or after this time (RFC3339 or unix seconds)"
+// @Param to query string false "Only movements before this time (RFC3339 or unix seconds)"
+// @Param reason query string false "Filter by reason" Enums(initial, sale, restock, adjustment, reservation, release)
+// @Param limit query int false "Items per page" default(50)
+// @Param page_token query string false "Token from a previous page"
+// @Security BearerAuth
+// @Router /products/{id}/stock-movements [get]
+func (h *GatewayHandler) ListStockMovements(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	req := &product.ListStockMovementsRequest{
+		ProductId: c.Param("id"),
+		VariantId: c.Query("variant_id"),
+		Reason:    c.Query("reason"),
+		Limit:     int32(limit),
+		PageToken: c.Query("page_token"),
+	}
+
+	var err error
+	if req.From, err = queryUnixTime(c, "from"); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	if req.To, err = queryUnixTime(c, "to"); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	resp, err := h.productClient.ListStockMovements(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to list stock movements", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, gin.H{
+		"movements":       resp.Movements,
+		"next_page_token": resp.NextPageToken,
+	})
+}
+
+// ListLowStockProducts godoc
+// @Summary List Low Stock Products
+// @Description Products whose quantity has fallen below their reorder threshold, emptiest first. Requires the catalog_manager or admin role.
+// @Tags Inventory
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Security BearerAuth
+// @Router /products/low-stock [get]
+func (h *GatewayHandler) ListLowStockProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	req := &product.ListLowStockProductsRequest{
+		Page:  int32(page),
+		Limit: int32(limit),
+	}
+
+	resp, err := h.productClient.ListLowStockProducts(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to list low stock products", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, gin.H{
+		"products":   resp.Products,
+		"pagination": paginationResult(page, limit, "", resp.Total, false, ""),
+	})
+}
+
+// ReserveStock godoc
+// @Summary Reserve Stock
+// @Description Hold stock of one or more products for a checkout. The stock is released automatically once ttl_seconds have passed without a commit. Requires the catalog_manager or admin role.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Router /inventory/reservations [post]
+func (h *GatewayHandler) ReserveStock(c *gin.Context) {
+	var req product.ReserveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	resp, err := h.productClient.ReserveStock(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to reserve stock", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, resp.Status.Message, resp.Reservation)
+}
+
+// ReleaseStock godoc
+// @Summary Release Reservation
+// @Description Hand the stock held by an active reservation back to its products. Requires the catalog_manager or admin role.
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Security BearerAuth
+// @Router /inventory/reservations/{id}/release [post]
+func (h *GatewayHandler) ReleaseStock(c *gin.Context) {
+	req := &product.ReservationRequest{Id: c.Param("id")}
+
+	resp, err := h.productClient.ReleaseStock(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to release reservation", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Reservation)
+}
+
+// CommitReservation godoc
+// @Summary Commit Reservation
+// @Description Finalise an active reservation so its stock is never released. Requires the catalog_manager or admin role.
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Security BearerAuth
+// @Router /inventory/reservations/{id}/commit [post]
+func (h *GatewayHandler) CommitReservation(c *gin.Context) {
+	req := &product.ReservationRequest{Id: c.Param("id")}
+
+	resp, err := h.productClient.CommitReservation(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to commit reservation", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Reservation)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// PublishProduct godoc
+// @Summary Publish Product
+// @Description Publish a draft right away, or schedule it to be published at a future time. Archived products move back to draft before they can be published. Requires the catalog_manager or admin role.
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param at query string false "Publish time (RFC3339 or unix seconds), now when omitted"
+// @Security BearerAuth
+// @Router /products/{id}/publish [post]
+func (h *GatewayHandler) PublishProduct(c *gin.Context) {
+	at, err := queryUnixTime(c, "at")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	req := &product.PublishProductRequest{Id: c.Param("id"), PublishAt: at}
+	resp, err := h.productClient.PublishProduct(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to publish product", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Product)
+}
+
+// ArchiveProduct godoc
+// @Summary Archive Product
+// @Description Archive a product right away, or schedule it to be unpublished at a future time. Requires the catalog_manager or admin role.
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param at query string false "Unpublish time (RFC3339 or unix seconds), now when omitted"
+// @Security BearerAuth
+// @Router /products/{id}/archive [post]
+func (h *GatewayHandler) ArchiveProduct(c *gin.Context) {
+	at, err := queryUnixTime(c, "at")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	req := &product.ArchiveProductRequest{Id: c.Param("id"), UnpublishAt: at}
+	resp, err := h.productClient.ArchiveProduct(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to archive product", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Product)
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/imaging"
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+const (
+	// mediaFormField is the multipart field files are uploaded in
+	mediaFormField = "file"
+	// maxMediaFiles bounds how many files one upload may carry
+	maxMediaFiles = 10
+)
+
+// mediaUpload is an uploaded file that passed validation
+type mediaUpload struct {
+	header      *multipart.FileHeader
+	file        multipart.File
+	contentType string
+	extension   string
+	width       int
+	height      int
+}
+
+// UploadProductMedia godoc
+// @Summary Upload Product Media
+// @Description Upload one or more JPEG, PNG or GIF images of a product as multipart form data. The type is detected from the file content, not its name. Thumbnails are generated in the background. Requires the catalog_manager or admin role.
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param file formData file true "Image file, repeat the field to upload several"
+// @Security BearerAuth
+// @Router /products/{id}/media [post]
+func (h *GatewayHandler) UploadProductMedia(c *gin.Context) {
+	productID := c.Param("id")
+	if !primitive.IsValidObjectID(productID) {
+		response.Error(c, http.StatusBadRequest, "Invalid request", "invalid product ID")
+		return
+	}
+
+	// Leave room for the multipart framing around the files
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMediaFiles*h.maxUploadSize+1<<20)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, http.StatusRequestEntityTooLarge, "Upload too large", err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	defer form.RemoveAll()
+
+	headers := form.File[mediaFormField]
+	if len(headers) == 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid request", fmt.Sprintf("no files in the %q field", mediaFormField))
+		return
+	}
+	if len(headers) > maxMediaFiles {
+		response.Error(c, http.StatusBadRequest, "Invalid request", fmt.Sprintf("at most %d files can be uploaded at once", maxMediaFiles))
+		return
+	}
+
+	// Validate every file before storing any
+	uploads := make([]*mediaUpload, 0, len(headers))
+	for _, header := range headers {
+		upload, code, err := h.inspectUpload(header)
+		if err != nil {
+			response.Error(c, code, "Invalid media", fmt.Sprintf("%s: %v", header.Filename, err))
+			return
+		}
+		defer upload.file.Close()
+		uploads = append(uploads, upload)
+	}
+
+	var resp *product.ProductResponse
+	for _, upload := range uploads {
+		resp, err = h.storeMedia(c.Request.Context(), productID, upload)
+		if err != nil {
+			response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to upload media", err.Error())
+			return
+		}
+		if !resp.Status.Success {
+			response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusCreated, resp.Status.Message, resp.Product)
+}
+
+// DeleteProductMedia godoc
+// @Summary Delete Product Media
+// @Description Remove an image from a product and delete its files. Requires the catalog_manager or admin role.
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param media_id path string true "Media ID"
+// @Security BearerAuth
+// @Router /products/{id}/media/{media_id} [delete]
+func (h *GatewayHandler) DeleteProductMedia(c *gin.Context) {
+	req := &product.DeleteProductMediaRequest{ProductId: c.Param("id"), MediaId: c.Param("media_id")}
+	resp, err := h.productClient.DeleteProductMedia(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to delete media", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Product)
+}
+
+// inspectUpload checks the size, detected type and dimensions of an
+// uploaded file, returning the HTTP status to reject it with
+func (h *GatewayHandler) inspectUpload(header *multipart.FileHeader) (*mediaUpload, int, error) {
+	if header.Size > h.maxUploadSize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("file exceeds the %d MB limit", h.maxUploadSize>>20)
+	}
+	if header.Size == 0 {
+		return nil, http.StatusBadRequest, errors.New("file is empty")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("failed to read file: %w", err)
+	}
+	upload, code, err := inspectImage(file)
+	if err != nil {
+		file.Close()
+		return nil, code, err
+	}
+	upload.header = header
+	return upload, 0, nil
+}
+
+// inspectImage detects the type of file from its content and reads its
+// dimensions, rewinding it afterwards
+func inspectImage(file multipart.File) (*mediaUpload, int, error) {
+	detected, err := mimetype.DetectReader(file)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("failed to detect file type: %w", err)
+	}
+	contentType := detected.String()
+	extension, ok := imaging.Extensions[contentType]
+	if !ok {
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported file type %s", contentType)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > imaging.MaxPixels {
+		return nil, http.StatusBadRequest, fmt.Errorf("image dimensions %dx%d are not supported", config.Width, config.Height)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &mediaUpload{
+		file:        file,
+		contentType: contentType,
+		extension:   extension,
+		width:       config.Width,
+		height:      config.Height,
+	}, 0, nil
+}
+
+// storeMedia streams an upload into the blob store and attaches it to the
+// product, deleting the blob again when the product service rejects it
+func (h *GatewayHandler) storeMedia(ctx context.Context, productID string, upload *mediaUpload) (*product.ProductResponse, error) {
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return nil, fmt.Errorf("failed to name media: %w", err)
+	}
+	key := fmt.Sprintf("products/%s/%s%s", productID, hex.EncodeToString(name), upload.extension)
+
+	checksum := sha256.New()
+	if err := h.blobs.Put(ctx, key, io.TeeReader(upload.file, checksum), upload.header.Size, upload.contentType); err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+
+	resp, err := h.productClient.AddProductMedia(ctx, &product.AddProductMediaRequest{
+		ProductId: productID,
+		Media: &product.Media{
+			Key:         key,
+			ContentType: upload.contentType,
+			Size:        upload.header.Size,
+			Width:       int32(upload.width),
+			Height:      int32(upload.height),
+			Checksum:    hex.EncodeToString(checksum.Sum(nil)),
+		},
+	})
+	if err != nil || !resp.Status.Success {
+		if deleteErr := h.blobs.Delete(context.WithoutCancel(ctx), key); deleteErr != nil {
+			logger.Warnf("Failed to delete rejected media %s: %v", key, deleteErr)
+		}
+	}
+	return resp, err
+}
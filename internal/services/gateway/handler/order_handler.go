@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-microservice-boilerplate/internal/middleware"
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// CreateOrder godoc
+// @Summary Create Order
+// @Description Place an order for the caller. Each item names a product and the SKU of the product or one of its variants; names and retail prices are copied into the order. Admins may order on behalf of another user with user_id.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Router /orders [post]
+func (h *GatewayHandler) CreateOrder(c *gin.Context) {
+	var req order.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	userID, ok := orderScope(c, req.UserId)
+	if !ok {
+		return
+	}
+	if userID == "" {
+		userID = c.GetString("user_id")
+	}
+	req.UserId = userID
+
+	resp, err := h.orderClient.CreateOrder(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to create order", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, resp.Status.Message, resp.Order)
+}
+
+// GetOrder godoc
+// @Summary Get Order
+// @Description Get an order of the caller, or any order for admins
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Router /orders/{id} [get]
+func (h *GatewayHandler) GetOrder(c *gin.Context) {
+	userID, _ := orderScope(c, "")
+
+	resp, err := h.orderClient.GetOrder(c.Request.Context(), &order.GetOrderRequest{
+		Id:     c.Param("id"),
+		UserId: userID,
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to get order", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Order)
+}
+
+// ListOrders godoc
+// @Summary List Orders
+// @Description List the orders of the caller, newest first. Admins see every order unless they filter by user_id.
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query string false "Only orders of this user (admins only)"
+// @Param status query string false "Only orders with this status (pending, cancelled)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Orders per page"
+// @Param page_token query string false "Cursor from next_page_token of the previous page"
+// @Param total query string false "How to count the total: exact, estimated or none"
+// @Router /orders [get]
+func (h *GatewayHandler) ListOrders(c *gin.Context) {
+	userID, ok := orderScope(c, c.Query("user_id"))
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	pageToken := c.Query("page_token")
+
+	totalMode, err := parseTotalMode(c.Query("total"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	resp, err := h.orderClient.ListOrders(c.Request.Context(), &order.ListOrdersRequest{
+		UserId:    userID,
+		Status:    c.Query("status"),
+		Page:      int32(page),
+		Limit:     int32(limit),
+		PageToken: pageToken,
+		TotalMode: totalMode,
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to list orders", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, gin.H{
+		"orders":     resp.Orders,
+		"pagination": paginationResult(page, limit, pageToken, resp.Total, resp.TotalEstimated, resp.NextPageToken),
+	})
+}
+
+// CancelOrder godoc
+// @Summary Cancel Order
+// @Description Cancel a pending order of the caller, or any pending order for admins
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Router /orders/{id}/cancel [post]
+func (h *GatewayHandler) CancelOrder(c *gin.Context) {
+	// The body with a reason is optional
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+	}
+
+	userID, _ := orderScope(c, "")
+
+	resp, err := h.orderClient.CancelOrder(c.Request.Context(), &order.CancelOrderRequest{
+		Id:     c.Param("id"),
+		UserId: userID,
+		Reason: body.Reason,
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to cancel order", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Order)
+}
+
+// orderScope returns the user whose orders the caller works with. Admins get
+// the requested user, which may be empty for every user; anyone else gets
+// their own, and a 403 is written when they asked for someone else.
+func orderScope(c *gin.Context, requested string) (string, bool) {
+	if middleware.HasRole(c, middleware.RoleAdmin) {
+		return requested, true
+	}
+
+	own := c.GetString("user_id")
+	if requested != "" && requested != own {
+		response.Error(c, http.StatusForbidden, "Only admins can access the orders of other users", nil)
+		return "", false
+	}
+	return own, true
+}
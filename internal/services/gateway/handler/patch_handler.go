@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/proto/user"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// mimeMergePatch is the content type of a JSON merge patch (RFC 7396)
+const mimeMergePatch = "application/merge-patch+json"
+
+// PatchUser godoc
+// @Summary Patch User
+// @Description Partially update a user with a JSON merge patch. Only the fields in the body are written, and null or empty values clear them, e.g. {"phone": null}.
+// @Tags Users
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "User ID"
+// @Router /users/{id} [patch]
+func (h *GatewayHandler) PatchUser(c *gin.Context) {
+	var req user.UpdateUserRequest
+	fields, ok := bindMergePatch(c, &req)
+	if !ok {
+		return
+	}
+
+	req.Id = c.Param("id")
+	req.UpdateMask = updateMask(fields, nil)
+	resp, err := h.userClient.UpdateUser(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to update user", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.User)
+}
+
+// PatchProduct godoc
+// @Summary Patch Product
+// @Description Partially update a product with a JSON merge patch. Only the fields in the body are written, and null or empty values clear them, e.g. {"description": null}. Attributes are merged one by one, a null attribute is removed; price, price lists and variants are replaced as a whole. Only catalog managers can change the status.
+// @Tags Products
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Router /products/{id} [patch]
+func (h *GatewayHandler) PatchProduct(c *gin.Context) {
+	var req product.UpdateProductRequest
+	fields, ok := bindMergePatch(c, &req)
+	if !ok {
+		return
+	}
+
+	if changesStatus(&req, fields) && !catalogManager(c) {
+		response.Error(c, http.StatusForbidden, "Only catalog managers can change the product status", nil)
+		return
+	}
+
+	// A patched attributes object is merged into the stored attributes
+	attributes, err := attributePaths(fields["attributes"])
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	for name, value := range req.Attributes {
+		if value == nil {
+			delete(req.Attributes, name)
+		}
+	}
+	if len(attributes) == 0 {
+		req.Attributes = nil
+	}
+
+	req.Id = c.Param("id")
+	req.UpdateMask = updateMask(fields, attributes)
+	resp, err := h.productClient.UpdateProduct(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to update product", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Product)
+}
+
+// bindMergePatch decodes a merge patch body into req and returns its
+// top-level members, responding with an error when the body is not a JSON
+// object
+func bindMergePatch(c *gin.Context, req any) (map[string]json.RawMessage, bool) {
+	if contentType := c.ContentType(); contentType != mimeMergePatch && contentType != gin.MIMEJSON {
+		response.Error(c, http.StatusUnsupportedMediaType, "Invalid request", fmt.Sprintf("expected %s", mimeMergePatch))
+		return nil, false
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return nil, false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", "a merge patch must be a JSON object")
+		return nil, false
+	}
+	if err := json.Unmarshal(body, req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return nil, false
+	}
+
+	return fields, true
+}
+
+// attributePaths returns the mask paths of a patched attributes object, one
+// per attribute, or nil when the attributes are replaced or not patched
+func attributePaths(raw json.RawMessage) ([]string, error) {
+	if raw == nil || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &attributes); err != nil {
+		return nil, fmt.Errorf("attributes must be an object")
+	}
+
+	// An empty object changes nothing, but must not clear the attributes
+	paths := make([]string, 0, len(attributes))
+	for name := range attributes {
+		paths = append(paths, "attributes."+name)
+	}
+	return paths, nil
+}
+
+// updateMask builds the update mask of a merge patch from its members,
+// replacing a patched attributes object with the paths of its attributes
+func updateMask(fields map[string]json.RawMessage, attributes []string) *fieldmaskpb.FieldMask {
+	mask := &fieldmaskpb.FieldMask{}
+	for field := range fields {
+		if field == "attributes" && attributes != nil {
+			continue
+		}
+		mask.Paths = append(mask.Paths, field)
+	}
+	mask.Paths = append(mask.Paths, attributes...)
+	sort.Strings(mask.Paths)
+	return mask
+}
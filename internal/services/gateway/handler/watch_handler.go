@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// watchHeartbeatInterval is how often an idle watch is kept alive, so
+// proxies do not close it
+const watchHeartbeatInterval = 15 * time.Second
+
+// productWatchPing sends a WebSocket ping frame
+var productWatchPing = websocket.Codec{Marshal: func(any) ([]byte, byte, error) {
+	return nil, websocket.PingFrame, nil
+}}
+
+// WatchProducts godoc
+// @Summary Watch Products
+// @Description Stream changes of products as Server-Sent Events until the client disconnects. The first event, of type watching, confirms the watch; created, updated and deleted events follow with the product after the change as data. Every event id is a resume token: EventSource sends the last one as Last-Event-ID when it reconnects, and the stream continues after that change. Catalog managers also see drafts and archived products; everyone else gets a deleted event when a product is unpublished. A resume token too old to continue from is rejected with 409, after which clients should reload and watch again without it.
+// @Tags Products
+// @Produce text/event-stream
+// @Param ids query string false "Comma-separated IDs of the products to watch, at most 100"
+// @Param category query string false "Watch products of this category"
+// @Param category_tree query string false "Watch products of a category and all its subcategories"
+// @Param resume_token query string false "Resume after the change with this token, instead of the Last-Event-ID header"
+// @Router /products/watch [get]
+func (h *GatewayHandler) WatchProducts(c *gin.Context) {
+	ctx, cancel := h.watchContext(c.Request.Context())
+	defer cancel()
+
+	stream, first, ok := h.openProductWatch(ctx, c, c.GetHeader("Last-Event-ID"))
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	err := relayProductChanges(ctx, stream, first, func(change *product.ProductChange) error {
+		data, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		return writeEvent(c, fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", change.ResumeToken, change.Type, data))
+	}, func() error {
+		return writeEvent(c, ": heartbeat\n\n")
+	})
+	if err != nil {
+		logger.Warnf("Product watch ended: %v", err)
+		data, _ := json.Marshal(gin.H{"message": "Product watch interrupted"})
+		writeEvent(c, fmt.Sprintf("event: error\ndata: %s\n\n", data))
+	}
+}
+
+// WatchProductsWebSocket godoc
+// @Summary Watch Products over WebSocket
+// @Description Stream changes of products over a WebSocket until either side closes it. Every message is a JSON product change, starting with one of type watching; errors arrive as a message of type error right before the connection closes. Takes the same filters as watching products with Server-Sent Events; reconnecting clients pass the resume_token of the last change they received.
+// @Tags Products
+// @Param ids query string false "Comma-separated IDs of the products to watch, at most 100"
+// @Param category query string false "Watch products of this category"
+// @Param category_tree query string false "Watch products of a category and all its subcategories"
+// @Param resume_token query string false "Resume after the change with this token"
+// @Router /products/watch/ws [get]
+func (h *GatewayHandler) WatchProductsWebSocket(c *gin.Context) {
+	ctx, cancel := h.watchContext(c.Request.Context())
+	defer cancel()
+
+	stream, first, ok := h.openProductWatch(ctx, c, "")
+	if !ok {
+		return
+	}
+
+	server := websocket.Server{
+		// Origins are not checked, like CORS allows every origin; watches
+		// are authorized by their bearer token
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			// Reading notices the client closing the connection; messages
+			// from clients carry nothing
+			go func() {
+				defer cancel()
+				for {
+					var discard []byte
+					if err := websocket.Message.Receive(conn, &discard); err != nil {
+						return
+					}
+				}
+			}()
+
+			err := relayProductChanges(ctx, stream, first, func(change *product.ProductChange) error {
+				return websocket.JSON.Send(conn, change)
+			}, func() error {
+				return productWatchPing.Send(conn, nil)
+			})
+			if err != nil {
+				logger.Warnf("Product watch ended: %v", err)
+				websocket.JSON.Send(conn, gin.H{"type": "error", "message": "Product watch interrupted"})
+			}
+			conn.Close()
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// watchContext derives the context of a watch, which also ends when the
+// gateway shuts down
+func (h *GatewayHandler) watchContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(h.streams, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// openProductWatch starts watching the products selected by the query and
+// receives the first change before anything is written, so a watch the
+// service rejects still gets a proper error response
+func (h *GatewayHandler) openProductWatch(ctx context.Context, c *gin.Context, lastEventID string) (grpc.ServerStreamingClient[product.ProductChange], *product.ProductChange, bool) {
+	req := &product.WatchProductsRequest{
+		ProductIds:         idsQuery(c),
+		Category:           c.Query("category"),
+		CategoryTree:       c.Query("category_tree"),
+		IncludeUnpublished: catalogManager(c),
+		ResumeToken:        c.DefaultQuery("resume_token", lastEventID),
+	}
+
+	stream, err := h.productClient.WatchProducts(ctx, req)
+	if err == nil {
+		var first *product.ProductChange
+		if first, err = stream.Recv(); err == nil {
+			return stream, first, true
+		}
+	}
+	if err == io.EOF {
+		err = fmt.Errorf("product watch closed before it started")
+	}
+	response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to watch products", err.Error())
+	return nil, nil, false
+}
+
+// relayProductChanges sends the changes of a watch, starting with first,
+// and a heartbeat whenever the watch was idle for watchHeartbeatInterval.
+// It returns nil once ctx is cancelled.
+func relayProductChanges(ctx context.Context, stream grpc.ServerStreamingClient[product.ProductChange], first *product.ProductChange,
+	send func(*product.ProductChange) error, heartbeat func() error) error {
+	changes := make(chan *product.ProductChange)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			change, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case changes <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := send(first); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(watchHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		case change := <-changes:
+			if err := send(change); err != nil {
+				return err
+			}
+			ticker.Reset(watchHeartbeatInterval)
+		case <-ticker.C:
+			if err := heartbeat(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeEvent writes a Server-Sent Event and flushes it to the client
+func writeEvent(c *gin.Context, event string) error {
+	if _, err := io.WriteString(c.Writer, event); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
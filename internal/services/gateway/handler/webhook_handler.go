@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-microservice-boilerplate/internal/proto/webhook"
+	"go-microservice-boilerplate/internal/utils/response"
+)
+
+// CreateWebhook godoc
+// @Summary Create Webhook
+// @Description Register an endpoint that receives user and product events. event_types lists event types such as product.updated, user.* or product.* for every event of an aggregate, or * for every event. A signing secret is generated unless one is given; it is only returned in this response.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h *GatewayHandler) CreateWebhook(c *gin.Context) {
+	var req webhook.CreateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	resp, err := h.webhookClient.CreateEndpoint(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to create webhook", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, resp.Status.Message, resp.Endpoint)
+}
+
+// ListWebhooks godoc
+// @Summary List Webhooks
+// @Description List every registered webhook endpoint, oldest first
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h *GatewayHandler) ListWebhooks(c *gin.Context) {
+	resp, err := h.webhookClient.ListEndpoints(c.Request.Context(), &webhook.ListEndpointsRequest{})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to list webhooks", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Endpoints)
+}
+
+// GetWebhook godoc
+// @Summary Get Webhook
+// @Description Get a webhook endpoint, including whether it was disabled after failing deliveries
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Router /webhooks/{id} [get]
+func (h *GatewayHandler) GetWebhook(c *gin.Context) {
+	resp, err := h.webhookClient.GetEndpoint(c.Request.Context(), &webhook.GetEndpointRequest{Id: c.Param("id")})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to get webhook", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Endpoint)
+}
+
+// UpdateWebhook godoc
+// @Summary Update Webhook
+// @Description Change the url, secret, event_types, description or enabled flag of a webhook endpoint; fields left out are kept. Setting enabled to true re-enables an endpoint disabled after failing deliveries and clears its failures.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Router /webhooks/{id} [patch]
+func (h *GatewayHandler) UpdateWebhook(c *gin.Context) {
+	var req webhook.UpdateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	req.Id = c.Param("id")
+
+	resp, err := h.webhookClient.UpdateEndpoint(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to update webhook", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, resp.Endpoint)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete Webhook
+// @Description Delete a webhook endpoint. Its pending deliveries fail instead of being sent.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Router /webhooks/{id} [delete]
+func (h *GatewayHandler) DeleteWebhook(c *gin.Context) {
+	resp, err := h.webhookClient.DeleteEndpoint(c.Request.Context(), &webhook.DeleteEndpointRequest{Id: c.Param("id")})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to delete webhook", err.Error())
+		return
+	}
+
+	if !resp.Success {
+		response.Error(c, int(resp.Code), resp.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Message, nil)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List Webhook Deliveries
+// @Description List the delivery log of a webhook endpoint, newest first, with every attempt's status code and error
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Only deliveries with this status (pending, delivered, failed)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Deliveries per page"
+// @Param page_token query string false "Cursor from next_page_token of the previous page"
+// @Param total query string false "How to count the total: exact, estimated or none"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *GatewayHandler) ListWebhookDeliveries(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	pageToken := c.Query("page_token")
+
+	totalMode, err := parseTotalMode(c.Query("total"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	resp, err := h.webhookClient.ListDeliveries(c.Request.Context(), &webhook.ListDeliveriesRequest{
+		EndpointId: c.Param("id"),
+		Status:     c.Query("status"),
+		Page:       int32(page),
+		Limit:      int32(limit),
+		PageToken:  pageToken,
+		TotalMode:  totalMode,
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to list webhook deliveries", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp.Status.Message, gin.H{
+		"deliveries": resp.Deliveries,
+		"pagination": paginationResult(page, limit, pageToken, resp.Total, resp.TotalEstimated, resp.NextPageToken),
+	})
+}
+
+// ReplayWebhookDelivery godoc
+// @Summary Replay Webhook Delivery
+// @Description Send the event of a delivered or failed delivery again. The replay is a new delivery with replay_of set, retried like any other.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Router /webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func (h *GatewayHandler) ReplayWebhookDelivery(c *gin.Context) {
+	resp, err := h.webhookClient.ReplayDelivery(c.Request.Context(), &webhook.ReplayDeliveryRequest{
+		EndpointId: c.Param("id"),
+		DeliveryId: c.Param("delivery_id"),
+	})
+	if err != nil {
+		response.Error(c, httpStatusFromError(err, http.StatusInternalServerError), "Failed to replay webhook delivery", err.Error())
+		return
+	}
+
+	if !resp.Status.Success {
+		response.Error(c, int(resp.Status.Code), resp.Status.Message, nil)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, resp.Status.Message, resp.Delivery)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/swaggo/gin-swagger"
 	_ "go-microservice-boilerplate/docs"
 
+	"go-microservice-boilerplate/internal/blobstore"
 	"go-microservice-boilerplate/internal/config"
 	"go-microservice-boilerplate/internal/middleware"
 	"go-microservice-boilerplate/internal/services/gateway/client"
@@ -26,6 +27,9 @@ type Server struct {
 	httpServer    *http.Server
 	userClient    *client.UserClient
 	productClient *client.ProductClient
+	orderClient   *client.OrderClient
+	cartClient    *client.CartClient
+	webhookClient *client.WebhookClient
 }
 
 func NewServer(cfg *config.Config) *Server {
@@ -40,11 +44,33 @@ func NewServer(cfg *config.Config) *Server {
 		logger.Fatalf("Failed to create product client: %v", err)
 	}
 
+	orderClient, err := client.NewOrderClient(cfg)
+	if err != nil {
+		logger.Fatalf("Failed to create order client: %v", err)
+	}
+
+	cartClient, err := client.NewCartClient(cfg)
+	if err != nil {
+		logger.Fatalf("Failed to create cart client: %v", err)
+	}
+
+	webhookClient, err := client.NewWebhookClient(cfg)
+	if err != nil {
+		logger.Fatalf("Failed to create webhook client: %v", err)
+	}
+
+	blobs, err := blobstore.New(cfg.BlobStore)
+	if err != nil {
+		logger.Fatalf("Failed to create blob store: %v", err)
+	}
+
 	// Initialize Gin router
 	router := gin.New()
 
 	// Add middleware
 	router.Use(middleware.Logger())
+	router.Use(middleware.RequestMeta())
+	router.Use(middleware.OptionalAuth(cfg.JWTSecret))
 	router.Use(middleware.CORS())
 	router.Use(gin.Recovery())
 
@@ -61,8 +87,14 @@ func NewServer(cfg *config.Config) *Server {
 		swaggerGroup.GET("/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
+	// Files of the local blob store are served by the gateway itself unless
+	// a public URL points elsewhere
+	if cfg.BlobStore.Provider == "local" && cfg.BlobStore.PublicURL == "" {
+		router.Static(blobstore.LocalURLPrefix, cfg.BlobStore.LocalDir)
+	}
+
 	// Initialize handlers
-	gatewayHandler := handler.NewGatewayHandler(userClient, productClient)
+	gatewayHandler := handler.NewGatewayHandler(userClient, productClient, orderClient, cartClient, webhookClient, blobs, int64(cfg.Media.MaxUploadMB)<<20)
 	gatewayHandler.RegisterRoutes(router)
 
 	// Create HTTP server
@@ -70,12 +102,16 @@ func NewServer(cfg *config.Config) *Server {
 		Addr:    ":" + cfg.Services.Gateway.Port,
 		Handler: router,
 	}
+	httpServer.RegisterOnShutdown(gatewayHandler.CloseStreams)
 
 	return &Server{
 		config:        cfg,
 		httpServer:    httpServer,
 		userClient:    userClient,
 		productClient: productClient,
+		orderClient:   orderClient,
+		cartClient:    cartClient,
+		webhookClient: webhookClient,
 	}
 }
 
@@ -108,6 +144,9 @@ func (s *Server) Start() error {
 	// Close gRPC clients
 	s.userClient.Close()
 	s.productClient.Close()
+	s.orderClient.Close()
+	s.cartClient.Close()
+	s.webhookClient.Close()
 
 	logger.Info("Gateway server exited")
 	return nil
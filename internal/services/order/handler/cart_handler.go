@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go-microservice-boilerplate/internal/proto/common"
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/services/order/service"
+)
+
+type CartGRPCHandler struct {
+	order.UnimplementedCartServiceServer
+	cartService service.CartService
+}
+
+func NewCartGRPCHandler(cartService service.CartService) *CartGRPCHandler {
+	return &CartGRPCHandler{
+		cartService: cartService,
+	}
+}
+
+func (h *CartGRPCHandler) GetCart(ctx context.Context, req *order.GetCartRequest) (*order.CartResponse, error) {
+	cart, err := h.cartService.GetCart(ctx, req.CartId)
+	return cartResponse(cart, err, "Cart retrieved successfully")
+}
+
+func (h *CartGRPCHandler) AddItem(ctx context.Context, req *order.AddCartItemRequest) (*order.CartResponse, error) {
+	cart, err := h.cartService.AddItem(ctx, &model.AddCartItemRequest{
+		CartID:    req.CartId,
+		ProductID: req.ProductId,
+		SKU:       req.Sku,
+		Quantity:  req.Quantity,
+		Currency:  req.Currency,
+	})
+	return cartResponse(cart, err, "Item added to cart")
+}
+
+func (h *CartGRPCHandler) UpdateQuantity(ctx context.Context, req *order.UpdateCartItemRequest) (*order.CartResponse, error) {
+	cart, err := h.cartService.UpdateQuantity(ctx, req.CartId, req.ProductId, req.Sku, req.Quantity)
+	return cartResponse(cart, err, "Cart item updated")
+}
+
+func (h *CartGRPCHandler) RemoveItem(ctx context.Context, req *order.RemoveCartItemRequest) (*order.CartResponse, error) {
+	cart, err := h.cartService.RemoveItem(ctx, req.CartId, req.ProductId, req.Sku)
+	return cartResponse(cart, err, "Item removed from cart")
+}
+
+func (h *CartGRPCHandler) MergeCarts(ctx context.Context, req *order.MergeCartsRequest) (*order.CartResponse, error) {
+	cart, err := h.cartService.MergeCarts(ctx, req.SourceCartId, req.TargetCartId)
+	return cartResponse(cart, err, "Carts merged successfully")
+}
+
+func (h *CartGRPCHandler) ClearCart(ctx context.Context, req *order.ClearCartRequest) (*common.StatusResponse, error) {
+	if err := h.cartService.ClearCart(ctx, req.CartId); err != nil {
+		code := grpcCode(err)
+		return &common.StatusResponse{
+			Code:    int32(code),
+			Message: err.Error(),
+			Success: false,
+		}, status.Error(code, err.Error())
+	}
+
+	return &common.StatusResponse{
+		Code:    int32(codes.OK),
+		Message: "Cart cleared successfully",
+		Success: true,
+	}, nil
+}
+
+// cartResponse builds the response of the RPCs that return a cart
+func cartResponse(cart *model.CartView, err error, message string) (*order.CartResponse, error) {
+	if err != nil {
+		code := grpcCode(err)
+		return &order.CartResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &order.CartResponse{
+		Cart: cartToProto(cart),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: message,
+			Success: true,
+		},
+	}, nil
+}
+
+func cartToProto(cart *model.CartView) *order.Cart {
+	items := make([]*order.CartItem, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = &order.CartItem{
+			ProductId:    item.ProductID,
+			Sku:          item.SKU,
+			VariantId:    item.VariantID,
+			Name:         item.Name,
+			Quantity:     item.Quantity,
+			AddedPrice:   moneyToProto(item.AddedPrice),
+			PriceChanged: item.PriceChanged,
+			Stock:        item.Stock,
+			InStock:      item.InStock,
+			Available:    item.Available,
+			AddedAt:      item.AddedAt.Unix(),
+		}
+		if item.Available {
+			items[i].UnitPrice = moneyToProto(item.UnitPrice)
+			items[i].Total = moneyToProto(item.Total)
+		}
+	}
+
+	protoCart := &order.Cart{
+		Id:        cart.ID,
+		Currency:  cart.Currency,
+		Items:     items,
+		Subtotal:  moneyToProto(cart.Subtotal),
+		ItemCount: cart.ItemCount,
+	}
+	if !cart.ExpiresAt.IsZero() {
+		protoCart.ExpiresAt = cart.ExpiresAt.Unix()
+	}
+
+	return protoCart
+}
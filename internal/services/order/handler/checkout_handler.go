@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go-microservice-boilerplate/internal/proto/common"
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/services/order/service"
+)
+
+type CheckoutGRPCHandler struct {
+	order.UnimplementedCheckoutServiceServer
+	checkoutService service.CheckoutService
+}
+
+func NewCheckoutGRPCHandler(checkoutService service.CheckoutService) *CheckoutGRPCHandler {
+	return &CheckoutGRPCHandler{
+		checkoutService: checkoutService,
+	}
+}
+
+func (h *CheckoutGRPCHandler) Checkout(ctx context.Context, req *order.CheckoutRequest) (*order.CheckoutResponse, error) {
+	checkoutReq := &model.CheckoutRequest{
+		UserID:         req.UserId,
+		IdempotencyKey: req.IdempotencyKey,
+		Items:          make([]model.OrderItemRequest, len(req.Items)),
+		Currency:       req.Currency,
+	}
+	for i, item := range req.Items {
+		checkoutReq.Items[i] = model.OrderItemRequest{
+			ProductID: item.ProductId,
+			SKU:       item.Sku,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	checkout, err := h.checkoutService.Checkout(ctx, checkoutReq)
+	return checkoutResponse(checkout, err, "Checkout processed")
+}
+
+func (h *CheckoutGRPCHandler) GetCheckout(ctx context.Context, req *order.GetCheckoutRequest) (*order.CheckoutResponse, error) {
+	checkout, err := h.checkoutService.GetCheckout(ctx, req.Id, req.UserId)
+	return checkoutResponse(checkout, err, "Checkout retrieved successfully")
+}
+
+// checkoutResponse wraps the result of a checkout operation. A checkout
+// that failed is still a successful answer: its status and error tell why.
+func checkoutResponse(checkout *model.Checkout, err error, message string) (*order.CheckoutResponse, error) {
+	if err != nil {
+		code := grpcCode(err)
+		return &order.CheckoutResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &order.CheckoutResponse{
+		Checkout: checkoutToProto(checkout),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: message,
+			Success: true,
+		},
+	}, nil
+}
+
+func checkoutToProto(c *model.Checkout) *order.Checkout {
+	return &order.Checkout{
+		Id:            c.ID.Hex(),
+		UserId:        c.UserID,
+		Status:        c.Status,
+		OrderId:       c.OrderID.Hex(),
+		Items:         orderItemsToProto(c.Items),
+		Total:         moneyToProto(c.Total),
+		ReservationId: c.ReservationID,
+		PaymentId:     c.PaymentID,
+		Error:         c.Error,
+		CreatedAt:     c.CreatedAt.Unix(),
+		UpdatedAt:     c.UpdatedAt.Unix(),
+	}
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go-microservice-boilerplate/internal/proto/common"
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/services/order/service"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+type OrderGRPCHandler struct {
+	order.UnimplementedOrderServiceServer
+	orderService service.OrderService
+}
+
+func NewOrderGRPCHandler(orderService service.OrderService) *OrderGRPCHandler {
+	return &OrderGRPCHandler{
+		orderService: orderService,
+	}
+}
+
+func (h *OrderGRPCHandler) CreateOrder(ctx context.Context, req *order.CreateOrderRequest) (*order.OrderResponse, error) {
+	createReq := &model.CreateOrderRequest{
+		UserID:   req.UserId,
+		Items:    make([]model.OrderItemRequest, len(req.Items)),
+		Currency: req.Currency,
+	}
+	for i, item := range req.Items {
+		createReq.Items[i] = model.OrderItemRequest{
+			ProductID: item.ProductId,
+			SKU:       item.Sku,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	orderModel, err := h.orderService.CreateOrder(ctx, createReq)
+	if err != nil {
+		code := grpcCode(err)
+		return &order.OrderResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &order.OrderResponse{
+		Order: h.modelToProto(orderModel),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Order created successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+func (h *OrderGRPCHandler) GetOrder(ctx context.Context, req *order.GetOrderRequest) (*order.OrderResponse, error) {
+	orderModel, err := h.orderService.GetOrder(ctx, req.Id, req.UserId)
+	if err != nil {
+		code := grpcCode(err)
+		return &order.OrderResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &order.OrderResponse{
+		Order: h.modelToProto(orderModel),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Order retrieved successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+func (h *OrderGRPCHandler) ListOrders(ctx context.Context, req *order.ListOrdersRequest) (*order.ListOrdersResponse, error) {
+	orders, pageInfo, err := h.orderService.ListOrders(ctx, &model.ListOrdersParams{
+		UserID:    req.UserId,
+		Status:    req.Status,
+		Page:      int(req.Page),
+		Limit:     int(req.Limit),
+		PageToken: req.PageToken,
+		TotalMode: pagination.TotalMode(req.TotalMode),
+	})
+	if err != nil {
+		code := grpcCode(err)
+		return &order.ListOrdersResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoOrders := make([]*order.Order, len(orders))
+	for i, o := range orders {
+		protoOrders[i] = h.modelToProto(o)
+	}
+
+	resp := &order.ListOrdersResponse{
+		Orders:         protoOrders,
+		NextPageToken:  pageInfo.NextPageToken,
+		TotalEstimated: pageInfo.TotalEstimated,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Orders retrieved successfully",
+			Success: true,
+		},
+	}
+	if pageInfo.Total != nil {
+		total := int32(*pageInfo.Total)
+		resp.Total = &total
+	}
+
+	return resp, nil
+}
+
+func (h *OrderGRPCHandler) CancelOrder(ctx context.Context, req *order.CancelOrderRequest) (*order.OrderResponse, error) {
+	orderModel, err := h.orderService.CancelOrder(ctx, req.Id, req.UserId, req.Reason)
+	if err != nil {
+		code := grpcCode(err)
+		return &order.OrderResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &order.OrderResponse{
+		Order: h.modelToProto(orderModel),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Order cancelled successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+func (h *OrderGRPCHandler) modelToProto(o *model.Order) *order.Order {
+	protoOrder := &order.Order{
+		Id:           o.ID.Hex(),
+		UserId:       o.UserID,
+		Items:        orderItemsToProto(o.Items),
+		Total:        moneyToProto(o.Total),
+		Status:       o.Status,
+		CancelReason: o.CancelReason,
+		CreatedAt:    o.CreatedAt.Unix(),
+		UpdatedAt:    o.UpdatedAt.Unix(),
+		CheckoutId:   o.CheckoutID,
+		PaymentId:    o.PaymentID,
+	}
+	if o.CancelledAt != nil {
+		protoOrder.CancelledAt = o.CancelledAt.Unix()
+	}
+	if o.ConfirmedAt != nil {
+		protoOrder.ConfirmedAt = o.ConfirmedAt.Unix()
+	}
+
+	return protoOrder
+}
+
+func orderItemsToProto(items []model.OrderItem) []*order.OrderItem {
+	protoItems := make([]*order.OrderItem, len(items))
+	for i, item := range items {
+		protoItems[i] = &order.OrderItem{
+			ProductId: item.ProductID,
+			Sku:       item.SKU,
+			VariantId: item.VariantID,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: moneyToProto(item.UnitPrice),
+			Total:     moneyToProto(item.Total),
+		}
+	}
+	return protoItems
+}
+
+func moneyToProto(m money.Money) *common.Money {
+	return &common.Money{
+		Amount:   m.Amount,
+		Currency: m.Currency,
+	}
+}
+
+// grpcCode maps service errors onto gRPC status codes
+func grpcCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, pagination.ErrInvalidPageToken), errors.Is(err, money.ErrOverflow):
+		return codes.InvalidArgument
+	case errors.Is(err, model.ErrOrderNotFound), errors.Is(err, model.ErrUserNotFound), errors.Is(err, model.ErrCartItemNotFound),
+		errors.Is(err, model.ErrCheckoutNotFound):
+		return codes.NotFound
+	case errors.Is(err, model.ErrProductUnavailable), errors.Is(err, model.ErrInsufficientStock),
+		errors.Is(err, model.ErrInvalidTransition), errors.Is(err, model.ErrCartFull), errors.Is(err, model.ErrCurrencyMismatch),
+		errors.Is(err, model.ErrIdempotencyKeyReused):
+		return codes.FailedPrecondition
+	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Code {
+		case http.StatusBadRequest:
+			return codes.InvalidArgument
+		case http.StatusNotFound:
+			return codes.NotFound
+		case http.StatusConflict:
+			return codes.AlreadyExists
+		}
+	}
+
+	// Failures of the user and product services keep their code, so an
+	// unavailable dependency is not reported as an internal error
+	if s, ok := status.FromError(err); ok && s.Code() != codes.OK {
+		return s.Code()
+	}
+
+	return codes.Internal
+}
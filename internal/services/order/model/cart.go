@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go-microservice-boilerplate/pkg/money"
+)
+
+var (
+	// ErrCartItemNotFound is returned when a cart has no item for a product
+	// and SKU
+	ErrCartItemNotFound = errors.New("cart item not found")
+	// ErrCartFull is returned when an item would take a cart past MaxItems
+	ErrCartFull = errors.New("cart is full")
+	// ErrCurrencyMismatch is returned when an item is added in another
+	// currency than the cart is in
+	ErrCurrencyMismatch = errors.New("currency does not match the cart")
+)
+
+// Cart is the stored state of a shopping cart
+type Cart struct {
+	ID       string
+	Currency string
+	Items    []CartItem
+	// ExpiresAt is when the cart is dropped unless it is used again
+	ExpiresAt time.Time
+}
+
+// CartItem is a stored line of a cart. The price is the one the customer saw
+// when adding the item, to tell them when it changed since.
+type CartItem struct {
+	ProductID  string      `json:"product_id"`
+	SKU        string      `json:"sku"`
+	Quantity   int32       `json:"quantity"`
+	AddedPrice money.Money `json:"added_price"`
+	AddedAt    time.Time   `json:"added_at"`
+}
+
+// Key identifies the item within its cart
+func (i CartItem) Key() string {
+	return CartItemKey(i.ProductID, i.SKU)
+}
+
+// CartItemKey identifies the item of a product and SKU within a cart
+func CartItemKey(productID, sku string) string {
+	return productID + "/" + sku
+}
+
+// CartView is a cart with its items checked against the live catalog
+type CartView struct {
+	ID        string
+	Currency  string
+	Items     []CartItemView
+	Subtotal  money.Money
+	ItemCount int32
+	ExpiresAt time.Time
+}
+
+// CartItemView is a cart item with the current product details
+type CartItemView struct {
+	CartItem
+	VariantID    string
+	Name         string
+	UnitPrice    money.Money
+	Total        money.Money
+	PriceChanged bool
+	Stock        int32
+	InStock      bool
+	// Available is false once the product is unpublished or deleted, or no
+	// longer has the SKU
+	Available bool
+}
+
+type AddCartItemRequest struct {
+	CartID    string
+	ProductID string
+	SKU       string
+	Quantity  int32
+	// Currency of a new cart, defaults to money.DefaultCurrency
+	Currency string
+}
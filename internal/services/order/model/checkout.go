@@ -0,0 +1,90 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// Checkout statuses. A checkout moves forward through started, reserved,
+// ordered, authorized and committed to completed, or from any status before
+// committed through compensating to failed.
+const (
+	CheckoutStarted      = "started"
+	CheckoutReserved     = "reserved"
+	CheckoutOrdered      = "ordered"
+	CheckoutAuthorized   = "authorized"
+	CheckoutCommitted    = "committed"
+	CheckoutCompleted    = "completed"
+	CheckoutCompensating = "compensating"
+	CheckoutFailed       = "failed"
+)
+
+// MaxIdempotencyKeyLength bounds the idempotency keys clients choose
+const MaxIdempotencyKeyLength = 255
+
+var (
+	// ErrCheckoutNotFound is returned for unknown checkout IDs, and for
+	// checkouts of another user
+	ErrCheckoutNotFound = errors.New("checkout not found")
+	// ErrCheckoutExists is returned when a checkout is stored with the
+	// idempotency key of an existing one
+	ErrCheckoutExists = errors.New("checkout already exists")
+	// ErrIdempotencyKeyReused is returned when an idempotency key comes back
+	// with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different checkout")
+	// ErrCheckoutLeaseLost is returned when a checkout is saved by someone
+	// whose lease ran out and was taken over
+	ErrCheckoutLeaseLost = errors.New("checkout lease was lost")
+)
+
+// Checkout is the persisted state of the checkout saga. Every step is saved
+// before the next one starts, so a checkout interrupted by a crash is
+// resumed, or rolled back, from the last saved step.
+type Checkout struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID         string             `bson:"user_id" json:"user_id"`
+	IdempotencyKey string             `bson:"idempotency_key" json:"idempotency_key"`
+	// Fingerprint tells a retry of the same request from a reuse of its key
+	Fingerprint string `bson:"fingerprint" json:"-"`
+	// CartID is set when the items came from a cart, which is emptied once
+	// the checkout completes
+	CartID string `bson:"cart_id,omitempty" json:"cart_id,omitempty"`
+	// OrderID is chosen up front so creating the order can be retried
+	OrderID primitive.ObjectID `bson:"order_id" json:"order_id"`
+	Items   []OrderItem        `bson:"items" json:"items"`
+	Total   money.Money        `bson:"total" json:"total"`
+	Status  string             `bson:"status" json:"status"`
+	// ReservationID is chosen up front too, so reserving can be retried
+	ReservationID string `bson:"reservation_id,omitempty" json:"reservation_id,omitempty"`
+	PaymentID     string `bson:"payment_id,omitempty" json:"payment_id,omitempty"`
+	// Error is why the checkout failed, or the last error of a step that
+	// is being retried
+	Error    string `bson:"error,omitempty" json:"error,omitempty"`
+	Attempts int    `bson:"attempts" json:"attempts"`
+	// LockedUntil is the end of the lease of whoever is driving the saga
+	LockedUntil time.Time `bson:"locked_until" json:"-"`
+	// Version grows with every save and every lease taken, so a save by
+	// someone who lost the lease is refused
+	Version   int64     `bson:"version" json:"-"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Done reports whether the checkout reached a final status
+func (c *Checkout) Done() bool {
+	return c.Status == CheckoutCompleted || c.Status == CheckoutFailed
+}
+
+type CheckoutRequest struct {
+	UserID         string
+	IdempotencyKey string
+	// Items to buy, the cart of the user when empty
+	Items []OrderItemRequest
+	// Currency of the order, defaults to the cart currency or
+	// money.DefaultCurrency
+	Currency string
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/utils/pagination"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// Order statuses
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusCancelled = "cancelled"
+)
+
+// MaxItems bounds how many lines one order may have
+const MaxItems = 100
+
+var (
+	// ErrOrderNotFound is returned for unknown order IDs, and for orders of
+	// another user
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderExists is returned when an order is created with the ID of an
+	// existing order
+	ErrOrderExists = errors.New("order already exists")
+	// ErrUserNotFound is returned when an order is placed for an unknown user
+	ErrUserNotFound = errors.New("user not found")
+	// ErrProductUnavailable is returned when an ordered product does not
+	// exist or is not published
+	ErrProductUnavailable = errors.New("product is not available")
+	// ErrInsufficientStock is returned when a product has less stock than
+	// ordered
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrInvalidTransition is returned when an order cannot move from its
+	// current status to the requested one
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// ValidStatus reports whether status is an order status
+func ValidStatus(status string) bool {
+	return status == StatusPending || status == StatusConfirmed || status == StatusCancelled
+}
+
+// Order is a purchase by a user. Items keep the name and price of each
+// product as they were when the order was placed.
+type Order struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID       string             `bson:"user_id" json:"user_id"`
+	Items        []OrderItem        `bson:"items" json:"items"`
+	Total        money.Money        `bson:"total" json:"total"`
+	Status       string             `bson:"status" json:"status"`
+	CancelReason string             `bson:"cancel_reason,omitempty" json:"cancel_reason,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	CancelledAt  *time.Time         `bson:"cancelled_at,omitempty" json:"cancelled_at,omitempty"`
+	ConfirmedAt  *time.Time         `bson:"confirmed_at,omitempty" json:"confirmed_at,omitempty"`
+	// CheckoutID is set on orders placed through a checkout, which confirms
+	// or cancels them itself
+	CheckoutID string `bson:"checkout_id,omitempty" json:"checkout_id,omitempty"`
+	// PaymentID is the payment authorization of a confirmed order
+	PaymentID string `bson:"payment_id,omitempty" json:"payment_id,omitempty"`
+}
+
+// OrderItem is one line of an order
+type OrderItem struct {
+	ProductID string `bson:"product_id" json:"product_id"`
+	SKU       string `bson:"sku" json:"sku"`
+	// VariantID is set when the SKU is a variant of the product
+	VariantID string      `bson:"variant_id,omitempty" json:"variant_id,omitempty"`
+	Name      string      `bson:"name" json:"name"`
+	Quantity  int32       `bson:"quantity" json:"quantity"`
+	UnitPrice money.Money `bson:"unit_price" json:"unit_price"`
+	Total     money.Money `bson:"total" json:"total"`
+}
+
+type CreateOrderRequest struct {
+	UserID string             `json:"user_id"`
+	Items  []OrderItemRequest `json:"items" binding:"required,dive"`
+	// Currency the order is priced in, defaults to money.DefaultCurrency
+	Currency string `json:"currency"`
+}
+
+// OrderItemRequest orders a quantity of a product, or of one of its
+// variants, by SKU
+type OrderItemRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	SKU       string `json:"sku" binding:"required"`
+	Quantity  int32  `json:"quantity" binding:"required,gt=0"`
+}
+
+type ListOrdersParams struct {
+	// UserID restricts the listing to the orders of one user
+	UserID    string
+	Status    string
+	Page      int
+	Limit     int
+	PageToken string
+	TotalMode pagination.TotalMode
+}
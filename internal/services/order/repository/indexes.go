@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+)
+
+// EnsureIndexes creates the indexes the order repositories rely on for
+// correctness rather than speed. deployments/mongodb/init-mongo.js creates
+// them too, but only runs on an empty data directory.
+func EnsureIndexes(ctx context.Context, db *database.MongoDB) error {
+	// A retried checkout finds the one started with the same key
+	return db.EnsureIndexes(ctx, "checkouts", mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "idempotency_key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+}
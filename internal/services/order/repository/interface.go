@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
+)
+
+type OrderRepository interface {
+	// Create stores a new order, keeping its ID when one is set. It returns
+	// model.ErrOrderExists when an order has that ID already.
+	Create(ctx context.Context, order *model.Order) error
+	// GetByID returns nil when no order has the ID
+	GetByID(ctx context.Context, id string) (*model.Order, error)
+	List(ctx context.Context, params *model.ListOrdersParams) ([]*model.Order, *pagination.PageInfo, error)
+	// Cancel moves an order from one of the given statuses to cancelled,
+	// returning nil when it was not in any of them
+	Cancel(ctx context.Context, id string, from []string, reason string) (*model.Order, error)
+	// Confirm moves a pending order to confirmed, returning nil when it was
+	// not pending
+	Confirm(ctx context.Context, id string, paymentID string) (*model.Order, error)
+}
+
+// CartRepository stores carts. Every read or write of a cart restarts its
+// time to live.
+type CartRepository interface {
+	// Get returns the cart with the ID, empty when it does not exist
+	Get(ctx context.Context, cartID string) (*model.Cart, error)
+	// Update changes a cart without losing concurrent changes: change gets
+	// the current cart and returns the items to store, replacing those with
+	// the same key, and cart.Currency is stored with them. change runs again
+	// when the cart changed in the meantime.
+	Update(ctx context.Context, cartID string, change func(cart *model.Cart) ([]model.CartItem, error)) error
+	// Merge stores the items merge returns into the target cart and deletes
+	// the source cart in one transaction, running merge again when either
+	// cart changed in the meantime
+	Merge(ctx context.Context, sourceID, targetID string, merge func(source, target *model.Cart) ([]model.CartItem, error)) error
+	// RemoveItem deletes an item, reporting whether the cart had it
+	RemoveItem(ctx context.Context, cartID, key string) (bool, error)
+	Delete(ctx context.Context, cartID string) error
+}
+
+// CheckoutRepository persists checkout sagas
+type CheckoutRepository interface {
+	// Create stores a new checkout, returning model.ErrCheckoutExists when
+	// the user already has one with the idempotency key
+	Create(ctx context.Context, checkout *model.Checkout) error
+	// GetByID returns nil when no checkout has the ID
+	GetByID(ctx context.Context, id string) (*model.Checkout, error)
+	// GetByKey returns nil when the user has no checkout with the key
+	GetByKey(ctx context.Context, userID, key string) (*model.Checkout, error)
+	// Acquire takes the lease of an unfinished checkout until the lease
+	// expires, returning nil when it is finished or someone else holds it
+	Acquire(ctx context.Context, id string, lease time.Duration) (*model.Checkout, error)
+	// Save stores the state of a checkout, returning
+	// model.ErrCheckoutLeaseLost when it changed since it was loaded
+	Save(ctx context.Context, checkout *model.Checkout) error
+	// ListStalled returns unfinished checkouts that nobody holds the lease of
+	ListStalled(ctx context.Context, limit int) ([]*model.Checkout, error)
+}
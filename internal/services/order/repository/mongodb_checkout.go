@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/order/model"
+)
+
+// unfinishedCheckouts matches checkouts that still have steps to run
+var unfinishedCheckouts = bson.M{"$nin": []string{model.CheckoutCompleted, model.CheckoutFailed}}
+
+type mongoCheckoutRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoCheckoutRepository(db *database.MongoDB) CheckoutRepository {
+	return &mongoCheckoutRepository{
+		collection: db.Collection("checkouts"),
+	}
+}
+
+func (r *mongoCheckoutRepository) Create(ctx context.Context, checkout *model.Checkout) error {
+	checkout.ID = primitive.NewObjectID()
+	checkout.CreatedAt = time.Now()
+	checkout.UpdatedAt = checkout.CreatedAt
+
+	// A unique index on user_id and idempotency_key settles concurrent
+	// requests with the same key
+	_, err := r.collection.InsertOne(ctx, checkout)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrCheckoutExists
+	}
+	return err
+}
+
+func (r *mongoCheckoutRepository) GetByID(ctx context.Context, id string) (*model.Checkout, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.findOne(ctx, bson.M{"_id": objectID})
+}
+
+func (r *mongoCheckoutRepository) GetByKey(ctx context.Context, userID, key string) (*model.Checkout, error) {
+	return r.findOne(ctx, bson.M{"user_id": userID, "idempotency_key": key})
+}
+
+func (r *mongoCheckoutRepository) Acquire(ctx context.Context, id string, lease time.Duration) (*model.Checkout, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":          objectID,
+		"status":       unfinishedCheckouts,
+		"locked_until": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"locked_until": now.Add(lease)},
+		"$inc": bson.M{"version": 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var checkout model.Checkout
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&checkout)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &checkout, nil
+}
+
+func (r *mongoCheckoutRepository) Save(ctx context.Context, checkout *model.Checkout) error {
+	saved := *checkout
+	saved.UpdatedAt = time.Now()
+	saved.Version++
+
+	// Only the version that was loaded is replaced; after a save or a lease
+	// taken by someone else it no longer matches
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": checkout.ID, "version": checkout.Version}, &saved)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrCheckoutLeaseLost
+	}
+
+	*checkout = saved
+	return nil
+}
+
+func (r *mongoCheckoutRepository) ListStalled(ctx context.Context, limit int) ([]*model.Checkout, error) {
+	filter := bson.M{
+		"status":       unfinishedCheckouts,
+		"locked_until": bson.M{"$lte": time.Now()},
+	}
+	findOptions := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "updated_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var checkouts []*model.Checkout
+	if err := cursor.All(ctx, &checkouts); err != nil {
+		return nil, err
+	}
+
+	return checkouts, nil
+}
+
+func (r *mongoCheckoutRepository) findOne(ctx context.Context, filter bson.M) (*model.Checkout, error) {
+	var checkout model.Checkout
+	err := r.collection.FindOne(ctx, filter).Decode(&checkout)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &checkout, nil
+}
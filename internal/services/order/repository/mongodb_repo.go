@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
+)
+
+type mongoOrderRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoOrderRepository(db *database.MongoDB) OrderRepository {
+	return &mongoOrderRepository{
+		collection: db.Collection("orders"),
+	}
+}
+
+func (r *mongoOrderRepository) Create(ctx context.Context, order *model.Order) error {
+	if order.ID.IsZero() {
+		order.ID = primitive.NewObjectID()
+	}
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, order)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrOrderExists
+	}
+	return err
+}
+
+func (r *mongoOrderRepository) GetByID(ctx context.Context, id string) (*model.Order, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var order model.Order
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (r *mongoOrderRepository) List(ctx context.Context, params *model.ListOrdersParams) ([]*model.Order, *pagination.PageInfo, error) {
+	filter := bson.M{}
+	if params.UserID != "" {
+		filter["user_id"] = params.UserID
+	}
+	if params.Status != "" {
+		filter["status"] = params.Status
+	}
+
+	var cursor *pagination.Cursor
+	if params.PageToken != "" {
+		var err error
+		if cursor, err = pagination.DecodeCursor(params.PageToken, "created_at:desc"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	total, estimated, err := pagination.Count(ctx, r.collection, filter, params.TotalMode, cursor != nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Fetch one extra document to learn whether another page follows
+	findOptions := options.Find().
+		SetLimit(int64(params.Limit + 1)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	query := filter
+	if cursor != nil {
+		query = bson.M{"$and": []bson.M{filter, cursor.After("created_at", true)}}
+	} else {
+		findOptions.SetSkip(int64((params.Page - 1) * params.Limit))
+	}
+
+	dbCursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer dbCursor.Close(ctx)
+
+	var orders []*model.Order
+	if err = dbCursor.All(ctx, &orders); err != nil {
+		return nil, nil, err
+	}
+
+	pageInfo := &pagination.PageInfo{
+		Total:          total,
+		TotalEstimated: estimated,
+	}
+
+	if len(orders) > params.Limit {
+		orders = orders[:params.Limit]
+		last := orders[len(orders)-1]
+		if pageInfo.NextPageToken, err = pagination.EncodeCursor("created_at:desc", last.CreatedAt, last.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return orders, pageInfo, nil
+}
+
+func (r *mongoOrderRepository) Cancel(ctx context.Context, id string, from []string, reason string) (*model.Order, error) {
+	now := time.Now()
+	set := bson.M{
+		"status":       model.StatusCancelled,
+		"cancelled_at": now,
+		"updated_at":   now,
+	}
+	if reason != "" {
+		set["cancel_reason"] = reason
+	}
+
+	return r.transition(ctx, id, from, set)
+}
+
+func (r *mongoOrderRepository) Confirm(ctx context.Context, id string, paymentID string) (*model.Order, error) {
+	now := time.Now()
+	return r.transition(ctx, id, []string{model.StatusPending}, bson.M{
+		"status":       model.StatusConfirmed,
+		"payment_id":   paymentID,
+		"confirmed_at": now,
+		"updated_at":   now,
+	})
+}
+
+// transition applies set to an order in one of the from statuses, returning
+// nil when it was in none of them
+func (r *mongoOrderRepository) transition(ctx context.Context, id string, from []string, set bson.M) (*model.Order, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The status guard makes concurrent transitions of the same order
+	// fail instead of overwriting each other
+	filter := bson.M{"_id": objectID, "status": bson.M{"$in": from}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var order model.Order
+	err = r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/order/model"
+)
+
+const (
+	// cartKeyPrefix prefixes the hash of each cart. The hash holds the cart
+	// currency under cartCurrencyField and every item as JSON under
+	// cartItemPrefix plus the item key.
+	cartKeyPrefix     = "cart:"
+	cartCurrencyField = "currency"
+	cartItemPrefix    = "item:"
+)
+
+type redisCartRepository struct {
+	client *database.Redis
+	ttl    time.Duration
+}
+
+// NewRedisCartRepository creates a Redis backed cart store whose carts
+// expire after ttl without use
+func NewRedisCartRepository(redis *database.Redis, ttl time.Duration) CartRepository {
+	return &redisCartRepository{
+		client: redis,
+		ttl:    ttl,
+	}
+}
+
+// cartUpdateAttempts bounds how often a cart update is retried after the
+// cart changed under it
+const cartUpdateAttempts = 10
+
+func (r *redisCartRepository) Get(ctx context.Context, cartID string) (*model.Cart, error) {
+	key := cartKeyPrefix + cartID
+
+	var fields *redis.StringStringMapCmd
+	_, err := r.client.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		fields = pipe.HGetAll(ctx, key)
+		pipe.Expire(ctx, key, r.ttl)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	return r.decode(cartID, fields.Val()), nil
+}
+
+func (r *redisCartRepository) Update(ctx context.Context, cartID string, change func(cart *model.Cart) ([]model.CartItem, error)) error {
+	key := cartKeyPrefix + cartID
+
+	return r.watch(ctx, func(tx *redis.Tx) error {
+		cart, err := r.read(ctx, tx, cartID)
+		if err != nil {
+			return err
+		}
+		items, err := change(cart)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return r.store(ctx, pipe, key, cart.Currency, items)
+		})
+		return err
+	}, key)
+}
+
+func (r *redisCartRepository) Merge(ctx context.Context, sourceID, targetID string, merge func(source, target *model.Cart) ([]model.CartItem, error)) error {
+	sourceKey := cartKeyPrefix + sourceID
+	targetKey := cartKeyPrefix + targetID
+
+	return r.watch(ctx, func(tx *redis.Tx) error {
+		source, err := r.read(ctx, tx, sourceID)
+		if err != nil {
+			return err
+		}
+		target, err := r.read(ctx, tx, targetID)
+		if err != nil {
+			return err
+		}
+		items, err := merge(source, target)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if err := r.store(ctx, pipe, targetKey, target.Currency, items); err != nil {
+				return err
+			}
+			pipe.Del(ctx, sourceKey)
+			return nil
+		})
+		return err
+	}, sourceKey, targetKey)
+}
+
+// watch runs fn in a transaction that fails when one of keys changes before
+// fn writes, retrying it a few times before giving up
+func (r *redisCartRepository) watch(ctx context.Context, fn func(tx *redis.Tx) error, keys ...string) error {
+	for attempt := 0; attempt < cartUpdateAttempts; attempt++ {
+		err := r.client.Client.Watch(ctx, fn, keys...)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to update cart: it kept changing after %d attempts", cartUpdateAttempts)
+}
+
+// read loads a cart within a watching transaction
+func (r *redisCartRepository) read(ctx context.Context, tx *redis.Tx, cartID string) (*model.Cart, error) {
+	fields, err := tx.HGetAll(ctx, cartKeyPrefix+cartID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	return r.decode(cartID, fields), nil
+}
+
+// store queues writing items and the currency of a cart, replacing items
+// with the same key, and restarts its time to live. Without items nothing
+// is written, so no cart is left holding only a currency.
+func (r *redisCartRepository) store(ctx context.Context, pipe redis.Pipeliner, key, currency string, items []model.CartItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, 0, 2*len(items)+2)
+	values = append(values, cartCurrencyField, currency)
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cart item: %w", err)
+		}
+		values = append(values, cartItemPrefix+item.Key(), data)
+	}
+
+	pipe.HSet(ctx, key, values...)
+	pipe.Expire(ctx, key, r.ttl)
+	return nil
+}
+
+// decode builds a cart from the fields of its hash
+func (r *redisCartRepository) decode(cartID string, fields map[string]string) *model.Cart {
+	cart := &model.Cart{ID: cartID}
+	for field, value := range fields {
+		if field == cartCurrencyField {
+			cart.Currency = value
+			continue
+		}
+		if !strings.HasPrefix(field, cartItemPrefix) {
+			continue
+		}
+		var item model.CartItem
+		// An item that no longer decodes is dropped from the cart
+		if err := json.Unmarshal([]byte(value), &item); err != nil {
+			continue
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	if len(cart.Items) > 0 {
+		cart.ExpiresAt = time.Now().Add(r.ttl)
+	}
+
+	// Items keep the order they were added in
+	sort.Slice(cart.Items, func(i, j int) bool {
+		if !cart.Items[i].AddedAt.Equal(cart.Items[j].AddedAt) {
+			return cart.Items[i].AddedAt.Before(cart.Items[j].AddedAt)
+		}
+		return cart.Items[i].Key() < cart.Items[j].Key()
+	})
+
+	return cart
+}
+
+func (r *redisCartRepository) RemoveItem(ctx context.Context, cartID, itemKey string) (bool, error) {
+	key := cartKeyPrefix + cartID
+	field := cartItemPrefix + itemKey
+
+	removed := false
+	err := r.watch(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.HExists(ctx, key, field).Result()
+		if err != nil {
+			return fmt.Errorf("failed to remove cart item: %w", err)
+		}
+		if !exists {
+			removed = false
+			return nil
+		}
+		length, err := tx.HLen(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to remove cart item: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			// Only the currency would be left, so the cart is empty
+			if length <= 2 {
+				pipe.Del(ctx, key)
+				return nil
+			}
+			pipe.HDel(ctx, key, field)
+			pipe.Expire(ctx, key, r.ttl)
+			return nil
+		})
+		removed = err == nil
+		return err
+	}, key)
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+func (r *redisCartRepository) Delete(ctx context.Context, cartID string) error {
+	if err := r.client.Client.Del(ctx, cartKeyPrefix+cartID).Err(); err != nil {
+		return fmt.Errorf("failed to delete cart: %w", err)
+	}
+	return nil
+}
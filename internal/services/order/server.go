@@ -0,0 +1,178 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+
+	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/payment"
+	"go-microservice-boilerplate/internal/proto/order"
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/proto/user"
+	"go-microservice-boilerplate/internal/services/order/handler"
+	"go-microservice-boilerplate/internal/services/order/repository"
+	"go-microservice-boilerplate/internal/services/order/service"
+	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+const (
+	// checkoutSweepInterval is how often interrupted checkouts are resumed
+	checkoutSweepInterval = 15 * time.Second
+	// eventGroup is the consumer group the order service reads events in
+	eventGroup = "order-service"
+)
+
+type Server struct {
+	config          *config.Config
+	grpcServer      *grpc.Server
+	conns           []*grpc.ClientConn
+	cartService     service.CartService
+	checkoutService service.CheckoutService
+	bus             events.Bus
+	stopWorkers     context.CancelFunc
+}
+
+func NewServer(cfg *config.Config, mongodb *database.MongoDB, redis *database.Redis) *Server {
+	indexCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.MongoDB.Timeout)*time.Second)
+	defer cancel()
+	if err := repository.EnsureIndexes(indexCtx, mongodb); err != nil {
+		logger.Fatalf("Failed to create order indexes: %v", err)
+	}
+
+	// Connect to the services orders are checked against
+	userConn, err := dial(cfg.Services.User)
+	if err != nil {
+		logger.Fatalf("Failed to connect to user service: %v", err)
+	}
+	productConn, err := dial(cfg.Services.Product)
+	if err != nil {
+		logger.Fatalf("Failed to connect to product service: %v", err)
+	}
+
+	users := user.NewUserServiceClient(userConn)
+	products := product.NewProductServiceClient(productConn)
+
+	// Initialize repositories
+	orderRepo := repository.NewMongoOrderRepository(mongodb)
+	cartRepo := repository.NewRedisCartRepository(redis, time.Duration(cfg.Cart.TTLHours)*time.Hour)
+	checkoutRepo := repository.NewMongoCheckoutRepository(mongodb)
+
+	// Initialize services
+	orderService := service.NewOrderService(orderRepo, users, products)
+	cartService := service.NewCartService(cartRepo, products)
+	checkoutService := service.NewCheckoutService(checkoutRepo, orderRepo, cartRepo, users, products, payment.New(cfg.Payment))
+
+	// Initialize gRPC server
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestmeta.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(requestmeta.StreamServerInterceptor()),
+	)
+
+	// Register handlers
+	orderHandler := handler.NewOrderGRPCHandler(orderService)
+	order.RegisterOrderServiceServer(grpcServer, orderHandler)
+	cartHandler := handler.NewCartGRPCHandler(cartService)
+	order.RegisterCartServiceServer(grpcServer, cartHandler)
+	checkoutHandler := handler.NewCheckoutGRPCHandler(checkoutService)
+	order.RegisterCheckoutServiceServer(grpcServer, checkoutHandler)
+
+	// Enable reflection for grpcurl/grpc clients
+	reflection.Register(grpcServer)
+
+	return &Server{
+		config:          cfg,
+		grpcServer:      grpcServer,
+		conns:           []*grpc.ClientConn{userConn, productConn},
+		cartService:     cartService,
+		checkoutService: checkoutService,
+		bus:             events.NewBus(cfg.Events, redis),
+	}
+}
+
+// dial connects to another service, passing the request metadata along
+func dial(svc config.ServiceConfig) (*grpc.ClientConn, error) {
+	return grpc.Dial(fmt.Sprintf("%s:%s", svc.Host, svc.Port),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(requestmeta.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(requestmeta.StreamClientInterceptor()),
+	)
+}
+
+func (s *Server) Start() error {
+	port := s.config.Services.Order.Port
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", port, err)
+	}
+
+	logger.Infof("Order service starting on port %s", port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWorkers = cancel
+	go s.resumeCheckouts(requestmeta.WithActor(ctx, "system:checkout-recovery"))
+	go events.Run(requestmeta.WithActor(ctx, "system:events"), s.bus, s.subscriptions()...)
+
+	if err := s.grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("failed to serve gRPC server: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) Stop() {
+	logger.Info("Shutting down Order service...")
+	if s.stopWorkers != nil {
+		s.stopWorkers()
+	}
+	s.grpcServer.GracefulStop()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// resumeCheckouts finishes, or rolls back, checkouts interrupted by a crash
+// or by errors worth retrying until ctx is cancelled
+func (s *Server) resumeCheckouts(ctx context.Context) {
+	ticker := time.NewTicker(checkoutSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			finished, err := s.checkoutService.ResumeCheckouts(ctx)
+			if err != nil {
+				logger.Errorf("Failed to resume checkouts: %v", err)
+			}
+			if finished > 0 {
+				logger.Infof("Finished %d interrupted checkouts", finished)
+			}
+		}
+	}
+}
+
+// subscriptions declares the domain events the order service reacts to
+func (s *Server) subscriptions() []events.Subscription {
+	return []events.Subscription{
+		{
+			Group: eventGroup,
+			Handlers: map[string]events.Handler{
+				// Carts of deleted users would otherwise linger until
+				// they expire
+				events.UserDeleted: events.On(func(ctx context.Context, event *events.Event, user events.Deleted) error {
+					return s.cartService.ClearCart(ctx, "user:"+user.ID)
+				}),
+			},
+		},
+	}
+}
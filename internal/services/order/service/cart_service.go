@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/services/order/repository"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// cartIDPattern limits cart IDs to what is safe inside a Redis key
+var cartIDPattern = regexp.MustCompile(`^[A-Za-z0-9:_-]{1,128}$`)
+
+type cartService struct {
+	repo     repository.CartRepository
+	products product.ProductServiceClient
+}
+
+// NewCartService creates a cart service that prices carts with the product
+// service
+func NewCartService(repo repository.CartRepository, products product.ProductServiceClient) CartService {
+	return &cartService{
+		repo:     repo,
+		products: products,
+	}
+}
+
+func (s *cartService) GetCart(ctx context.Context, cartID string) (*model.CartView, error) {
+	if err := validateCartID(cartID); err != nil {
+		return nil, err
+	}
+
+	cart, err := s.repo.Get(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.view(ctx, cart)
+}
+
+// AddItem puts a quantity of a SKU into a cart, adding to the quantity of an
+// item already there. The item remembers the price it was added at.
+func (s *cartService) AddItem(ctx context.Context, req *model.AddCartItemRequest) (*model.CartView, error) {
+	if err := validateCartID(req.CartID); err != nil {
+		return nil, err
+	}
+	sku := strings.TrimSpace(req.SKU)
+	if err := validateCartItem(req.ProductID, sku); err != nil {
+		return nil, err
+	}
+	if req.Quantity <= 0 {
+		return nil, apperrors.ErrInvalidInput("quantity must be positive")
+	}
+	currency := money.NormalizeCurrency(req.Currency)
+	if currency != "" && !money.Supported(currency) {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("unsupported currency %q", req.Currency))
+	}
+
+	// The item is priced in the currency the cart has now; the update
+	// fails should the cart change to another one before it is stored
+	cart, err := s.repo.Get(ctx, req.CartID)
+	if err != nil {
+		return nil, err
+	}
+	pricedIn, err := cartCurrency(cart, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.products.GetProduct(ctx, &product.GetProductRequest{
+		Id:      req.ProductID,
+		Pricing: &product.PriceQuery{Currency: pricedIn},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: %s", model.ErrProductUnavailable, req.ProductID)
+		}
+		return nil, fmt.Errorf("failed to look up product: %w", err)
+	}
+	offer, err := offerFor(resp.Product, req.ProductID, sku, pricedIn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Update(ctx, req.CartID, func(cart *model.Cart) ([]model.CartItem, error) {
+		current, err := cartCurrency(cart, currency)
+		if err != nil {
+			return nil, err
+		}
+		if current != pricedIn {
+			return nil, fmt.Errorf("%w: the cart is in %s", model.ErrCurrencyMismatch, current)
+		}
+		cart.Currency = current
+
+		// Adding an item again shows the customer the current price, so it
+		// becomes the price to compare against
+		item := model.CartItem{
+			ProductID:  req.ProductID,
+			SKU:        sku,
+			Quantity:   req.Quantity,
+			AddedPrice: offer.Price,
+			AddedAt:    time.Now(),
+		}
+		if i := findCartItem(cart, item.Key()); i >= 0 {
+			item.Quantity += cart.Items[i].Quantity
+			item.AddedAt = cart.Items[i].AddedAt
+		} else if len(cart.Items) >= model.MaxItems {
+			return nil, fmt.Errorf("%w: a cart can have at most %d items", model.ErrCartFull, model.MaxItems)
+		}
+		return []model.CartItem{item}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, req.CartID)
+}
+
+// UpdateQuantity sets the quantity of an item, removing it at zero
+func (s *cartService) UpdateQuantity(ctx context.Context, cartID, productID, sku string, quantity int32) (*model.CartView, error) {
+	if quantity < 0 {
+		return nil, apperrors.ErrInvalidInput("quantity cannot be negative")
+	}
+	if quantity == 0 {
+		return s.RemoveItem(ctx, cartID, productID, sku)
+	}
+	if err := validateCartID(cartID); err != nil {
+		return nil, err
+	}
+	sku = strings.TrimSpace(sku)
+	if err := validateCartItem(productID, sku); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.Update(ctx, cartID, func(cart *model.Cart) ([]model.CartItem, error) {
+		i := findCartItem(cart, model.CartItemKey(productID, sku))
+		if i < 0 {
+			return nil, model.ErrCartItemNotFound
+		}
+		cart.Items[i].Quantity = quantity
+		return cart.Items[i : i+1], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, cartID)
+}
+
+func (s *cartService) RemoveItem(ctx context.Context, cartID, productID, sku string) (*model.CartView, error) {
+	if err := validateCartID(cartID); err != nil {
+		return nil, err
+	}
+	sku = strings.TrimSpace(sku)
+	if err := validateCartItem(productID, sku); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.repo.RemoveItem(ctx, cartID, model.CartItemKey(productID, sku))
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, model.ErrCartItemNotFound
+	}
+
+	return s.GetCart(ctx, cartID)
+}
+
+// MergeCarts moves the items of the source cart into the target cart and
+// deletes the source. Items in both carts add up their quantities; the
+// target keeps its currency unless it was empty. Items moving to another
+// currency take their current price in it as the price they were added at.
+func (s *cartService) MergeCarts(ctx context.Context, sourceID, targetID string) (*model.CartView, error) {
+	if err := validateCartID(sourceID); err != nil {
+		return nil, err
+	}
+	if err := validateCartID(targetID); err != nil {
+		return nil, err
+	}
+	if sourceID == targetID {
+		return nil, apperrors.ErrInvalidInput("cannot merge a cart into itself")
+	}
+
+	source, err := s.repo.Get(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repo.Get(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if len(source.Items) == 0 {
+		return s.view(ctx, target)
+	}
+
+	// Prices are looked up before the merge, which fails should the target
+	// change to another currency before it is stored
+	var prices map[string]money.Money
+	pricedIn := target.Currency
+	if len(target.Items) > 0 && target.Currency != source.Currency {
+		if prices, err = s.currentPrices(ctx, source.Items, target.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.repo.Merge(ctx, sourceID, targetID, func(source, target *model.Cart) ([]model.CartItem, error) {
+		if len(target.Items) == 0 {
+			target.Currency = source.Currency
+		}
+		convert := target.Currency != source.Currency
+		if convert && target.Currency != pricedIn {
+			return nil, fmt.Errorf("%w: the cart is in %s", model.ErrCurrencyMismatch, target.Currency)
+		}
+
+		changed := make([]model.CartItem, 0, len(source.Items))
+		added := 0
+		for _, item := range source.Items {
+			if convert {
+				// Items without a price in the currency are unavailable there
+				// and keep no price to compare against
+				item.AddedPrice = prices[item.Key()]
+			}
+			if i := findCartItem(target, item.Key()); i >= 0 {
+				target.Items[i].Quantity += item.Quantity
+				changed = append(changed, target.Items[i])
+				continue
+			}
+			added++
+			changed = append(changed, item)
+		}
+		if len(target.Items)+added > model.MaxItems {
+			return nil, fmt.Errorf("%w: a cart can have at most %d items", model.ErrCartFull, model.MaxItems)
+		}
+		return changed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, targetID)
+}
+
+// currentPrices prices items in currency, keyed by item. Items that are not
+// available in the currency are left out.
+func (s *cartService) currentPrices(ctx context.Context, items []model.CartItem, currency string) (map[string]money.Money, error) {
+	products, err := s.lookUpProducts(ctx, items, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]money.Money, len(items))
+	for _, item := range items {
+		if offer, err := offerFor(products[item.ProductID], item.ProductID, item.SKU, currency); err == nil {
+			prices[item.Key()] = offer.Price
+		}
+	}
+	return prices, nil
+}
+
+func (s *cartService) ClearCart(ctx context.Context, cartID string) error {
+	if err := validateCartID(cartID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, cartID)
+}
+
+// view checks the items of a cart against the live catalog in one call,
+// pricing them in the cart currency
+func (s *cartService) view(ctx context.Context, cart *model.Cart) (*model.CartView, error) {
+	currency := cart.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	view := &model.CartView{
+		ID:        cart.ID,
+		Currency:  currency,
+		Items:     make([]model.CartItemView, len(cart.Items)),
+		Subtotal:  money.New(0, currency),
+		ExpiresAt: cart.ExpiresAt,
+	}
+	if len(cart.Items) == 0 {
+		return view, nil
+	}
+
+	products, err := s.lookUpProducts(ctx, cart.Items, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range cart.Items {
+		line := model.CartItemView{CartItem: item}
+		view.ItemCount += item.Quantity
+
+		p := products[item.ProductID]
+		if p != nil {
+			line.Name = p.Name
+		}
+		// Unpublished or deleted products, and SKUs that went away, stay in
+		// the cart flagged as unavailable
+		if offer, err := offerFor(p, item.ProductID, item.SKU, currency); err == nil {
+			line.Available = true
+			line.VariantID = offer.VariantID
+			line.UnitPrice = offer.Price
+			if line.Total, err = offer.Price.Multiply(int64(item.Quantity)); err != nil {
+				return nil, err
+			}
+			line.PriceChanged = offer.Price != item.AddedPrice
+			line.Stock = offer.Stock
+			line.InStock = offer.Stock >= item.Quantity
+
+			if view.Subtotal, err = view.Subtotal.Add(line.Total); err != nil {
+				return nil, err
+			}
+		}
+		view.Items[i] = line
+	}
+
+	return view, nil
+}
+
+// lookUpProducts fetches the products of items in one call, priced in
+// currency and keyed by ID
+func (s *cartService) lookUpProducts(ctx context.Context, items []model.CartItem, currency string) (map[string]*product.Product, error) {
+	ids := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			ids = append(ids, item.ProductID)
+		}
+	}
+	resp, err := s.products.BatchGetProducts(ctx, &product.BatchGetProductsRequest{
+		Ids:     ids,
+		Pricing: &product.PriceQuery{Currency: currency},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up products: %w", err)
+	}
+
+	products := make(map[string]*product.Product, len(resp.Products))
+	for _, p := range resp.Products {
+		products[p.Id] = p
+	}
+	return products, nil
+}
+
+// cartCurrency returns the currency of a cart once an item in the requested
+// currency, if any, is added to it
+func cartCurrency(cart *model.Cart, requested string) (string, error) {
+	switch {
+	case len(cart.Items) == 0 && requested != "":
+		return requested, nil
+	case len(cart.Items) == 0:
+		return money.DefaultCurrency, nil
+	case requested != "" && requested != cart.Currency:
+		return "", fmt.Errorf("%w: the cart is in %s", model.ErrCurrencyMismatch, cart.Currency)
+	}
+	return cart.Currency, nil
+}
+
+func findCartItem(cart *model.Cart, key string) int {
+	for i, item := range cart.Items {
+		if item.Key() == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateCartID(cartID string) error {
+	if !cartIDPattern.MatchString(cartID) {
+		return apperrors.ErrInvalidInput("invalid cart ID")
+	}
+	return nil
+}
+
+func validateCartItem(productID, sku string) error {
+	if !primitive.IsValidObjectID(productID) {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("invalid product ID %q", productID))
+	}
+	if sku == "" {
+		return apperrors.ErrInvalidInput("SKU is required")
+	}
+	return nil
+}
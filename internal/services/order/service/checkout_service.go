@@ -0,0 +1,492 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go-microservice-boilerplate/internal/payment"
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/proto/user"
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/services/order/repository"
+	"go-microservice-boilerplate/internal/utils/logger"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+const (
+	// reservationTTL is how long reserved stock waits for the payment. An
+	// expired reservation cannot be committed, so the checkout is rolled back.
+	reservationTTL = 15 * time.Minute
+	// checkoutDeadline bounds how long a checkout keeps retrying the steps
+	// before the payment; after it, the checkout is rolled back
+	checkoutDeadline = 10 * time.Minute
+	// checkoutLease is how long a checkout stays with whoever drives it
+	// before the recovery worker may take it over
+	checkoutLease = 30 * time.Second
+	// callTimeout bounds every call to another service or the payment
+	// provider, so a step ends well within the lease
+	callTimeout = 10 * time.Second
+	// resumeBatch bounds how many stalled checkouts one recovery run drives
+	resumeBatch = 50
+)
+
+type checkoutService struct {
+	repo     repository.CheckoutRepository
+	orders   repository.OrderRepository
+	carts    repository.CartRepository
+	users    user.UserServiceClient
+	products product.ProductServiceClient
+	payments payment.Provider
+}
+
+// NewCheckoutService creates a checkout service that reserves stock through
+// the product service and takes payments through the provider
+func NewCheckoutService(repo repository.CheckoutRepository, orders repository.OrderRepository, carts repository.CartRepository,
+	users user.UserServiceClient, products product.ProductServiceClient, payments payment.Provider) CheckoutService {
+	return &checkoutService{
+		repo:     repo,
+		orders:   orders,
+		carts:    carts,
+		users:    users,
+		products: products,
+		payments: payments,
+	}
+}
+
+// Checkout starts a checkout and drives it as far as it goes. A request
+// repeating the idempotency key of an earlier one gets that checkout back,
+// driven further if it was interrupted.
+func (s *checkoutService) Checkout(ctx context.Context, req *model.CheckoutRequest) (*model.Checkout, error) {
+	key := strings.TrimSpace(req.IdempotencyKey)
+	if key == "" {
+		return nil, apperrors.ErrInvalidInput("idempotency key is required")
+	}
+	if len(key) > model.MaxIdempotencyKeyLength {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("idempotency key cannot exceed %d characters", model.MaxIdempotencyKeyLength))
+	}
+	if !primitive.IsValidObjectID(req.UserID) {
+		return nil, apperrors.ErrInvalidInput("invalid user ID")
+	}
+	fingerprint := checkoutFingerprint(req)
+
+	existing, err := s.repo.GetByKey(ctx, req.UserID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up checkout: %w", err)
+	}
+	if existing != nil {
+		return s.retry(ctx, existing, fingerprint)
+	}
+
+	createReq := &model.CreateOrderRequest{
+		UserID:   req.UserID,
+		Items:    req.Items,
+		Currency: req.Currency,
+	}
+	var cartID string
+	if len(req.Items) == 0 {
+		cartID = "user:" + req.UserID
+		cart, err := s.carts.Get(ctx, cartID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cart: %w", err)
+		}
+		if len(cart.Items) == 0 {
+			return nil, apperrors.ErrInvalidInput("the cart is empty")
+		}
+		for _, item := range cart.Items {
+			createReq.Items = append(createReq.Items, model.OrderItemRequest{
+				ProductID: item.ProductID,
+				SKU:       item.SKU,
+				Quantity:  item.Quantity,
+			})
+		}
+		if createReq.Currency == "" {
+			createReq.Currency = cart.Currency
+		}
+	}
+
+	// Requests that could never succeed fail here, before anything is
+	// reserved
+	order, err := priceOrder(ctx, s.users, s.products, createReq)
+	if err != nil {
+		return nil, err
+	}
+
+	checkout := &model.Checkout{
+		UserID:         req.UserID,
+		IdempotencyKey: key,
+		Fingerprint:    fingerprint,
+		CartID:         cartID,
+		OrderID:        primitive.NewObjectID(),
+		ReservationID:  primitive.NewObjectID().Hex(),
+		Items:          order.Items,
+		Total:          order.Total,
+		Status:         model.CheckoutStarted,
+		LockedUntil:    time.Now().Add(checkoutLease),
+	}
+	if err := s.repo.Create(ctx, checkout); err != nil {
+		if !errors.Is(err, model.ErrCheckoutExists) {
+			return nil, fmt.Errorf("failed to create checkout: %w", err)
+		}
+
+		// A concurrent request with the same key stored its checkout first
+		existing, err := s.repo.GetByKey(ctx, req.UserID, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up checkout: %w", err)
+		}
+		if existing == nil {
+			return nil, fmt.Errorf("failed to create checkout: %w", model.ErrCheckoutExists)
+		}
+		return s.retry(ctx, existing, fingerprint)
+	}
+
+	return s.run(ctx, checkout)
+}
+
+// retry answers a request that repeats the idempotency key of a checkout
+func (s *checkoutService) retry(ctx context.Context, checkout *model.Checkout, fingerprint string) (*model.Checkout, error) {
+	if checkout.Fingerprint != fingerprint {
+		return nil, model.ErrIdempotencyKeyReused
+	}
+	if checkout.Done() {
+		return checkout, nil
+	}
+
+	return s.drive(ctx, checkout.ID.Hex())
+}
+
+func (s *checkoutService) GetCheckout(ctx context.Context, id, userID string) (*model.Checkout, error) {
+	if !primitive.IsValidObjectID(id) {
+		return nil, model.ErrCheckoutNotFound
+	}
+
+	checkout, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkout: %w", err)
+	}
+	// The checkouts of other users do not exist for the caller
+	if checkout == nil || (userID != "" && checkout.UserID != userID) {
+		return nil, model.ErrCheckoutNotFound
+	}
+
+	return checkout, nil
+}
+
+// ResumeCheckouts drives the unfinished checkouts nobody is driving, those
+// interrupted by a crash or stopped by an error worth retrying. It returns
+// how many reached a final status.
+func (s *checkoutService) ResumeCheckouts(ctx context.Context) (int, error) {
+	stalled, err := s.repo.ListStalled(ctx, resumeBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list stalled checkouts: %w", err)
+	}
+
+	finished := 0
+	for _, stalledCheckout := range stalled {
+		checkout, err := s.drive(ctx, stalledCheckout.ID.Hex())
+		if err != nil {
+			logger.Errorf("Failed to resume checkout %s: %v", stalledCheckout.ID.Hex(), err)
+			continue
+		}
+		if checkout.Done() {
+			finished++
+		}
+	}
+
+	return finished, nil
+}
+
+// drive takes the lease of a checkout and runs it. A checkout that is
+// finished, or driven by someone else, is returned as it is.
+func (s *checkoutService) drive(ctx context.Context, id string) (*model.Checkout, error) {
+	checkout, err := s.repo.Acquire(ctx, id, checkoutLease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire checkout: %w", err)
+	}
+	if checkout != nil {
+		return s.run(ctx, checkout)
+	}
+
+	checkout, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkout: %w", err)
+	}
+	if checkout == nil {
+		return nil, model.ErrCheckoutNotFound
+	}
+	return checkout, nil
+}
+
+// run takes the steps of a checkout whose lease the caller holds, saving
+// after each one, until the checkout is finished or a step fails with an
+// error worth retrying. The lease is renewed before every step, which must
+// end before the lease does, and released when it stops. A checkout whose
+// lease was taken over is returned as the new holder saved it.
+func (s *checkoutService) run(ctx context.Context, checkout *model.Checkout) (*model.Checkout, error) {
+	// A caller going away must not leave a step half recorded
+	ctx = context.WithoutCancel(ctx)
+
+	for !checkout.Done() {
+		checkout.LockedUntil = time.Now().Add(checkoutLease)
+		if err := s.repo.Save(ctx, checkout); err != nil {
+			return s.saveFailed(ctx, checkout, err)
+		}
+
+		from := checkout.Status
+		stepCtx, cancel := context.WithDeadline(ctx, checkout.LockedUntil)
+		err := s.step(stepCtx, checkout)
+		cancel()
+		if err != nil {
+			checkout.Attempts++
+			// While compensating, Error keeps why the checkout failed
+			if checkout.Status != model.CheckoutCompensating {
+				checkout.Error = err.Error()
+			}
+			logger.Warnf("Checkout %s stopped in status %s (attempt %d): %v", checkout.ID.Hex(), from, checkout.Attempts, err)
+			break
+		}
+
+		checkout.Attempts = 0
+		if checkout.Status != model.CheckoutCompensating && checkout.Status != model.CheckoutFailed {
+			checkout.Error = ""
+		}
+		if err := s.repo.Save(ctx, checkout); err != nil {
+			return s.saveFailed(ctx, checkout, err)
+		}
+	}
+
+	checkout.LockedUntil = time.Time{}
+	if err := s.repo.Save(ctx, checkout); err != nil {
+		return s.saveFailed(ctx, checkout, err)
+	}
+
+	return checkout, nil
+}
+
+// saveFailed handles a failed save of a checkout being run. Losing the
+// lease is not an error of the caller: whoever took it over drives the
+// checkout on, and its saved state is returned.
+func (s *checkoutService) saveFailed(ctx context.Context, checkout *model.Checkout, err error) (*model.Checkout, error) {
+	if !errors.Is(err, model.ErrCheckoutLeaseLost) {
+		return nil, fmt.Errorf("failed to save checkout: %w", err)
+	}
+
+	logger.Warnf("Checkout %s was taken over while in status %s", checkout.ID.Hex(), checkout.Status)
+	current, err := s.repo.GetByID(ctx, checkout.ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkout: %w", err)
+	}
+	if current == nil {
+		return nil, model.ErrCheckoutNotFound
+	}
+	return current, nil
+}
+
+// step takes the next step of a checkout. Every step can be repeated, since
+// a crash may come between taking a step and saving it. A step the checkout
+// cannot recover from moves it to compensating; the returned errors are
+// worth retrying.
+func (s *checkoutService) step(ctx context.Context, checkout *model.Checkout) error {
+	// Checkouts that cannot get as far as the payment give their stock back
+	// instead of retrying forever
+	if (checkout.Status == model.CheckoutStarted || checkout.Status == model.CheckoutReserved) &&
+		time.Since(checkout.CreatedAt) > checkoutDeadline {
+		compensate(checkout, "checkout timed out")
+		return nil
+	}
+
+	switch checkout.Status {
+	case model.CheckoutStarted:
+		items := make([]*product.StockItem, len(checkout.Items))
+		for i, item := range checkout.Items {
+			items[i] = &product.StockItem{
+				ProductId: item.ProductID,
+				VariantId: item.VariantID,
+				Quantity:  item.Quantity,
+			}
+		}
+		// The reservation ID was chosen with the checkout, so a repeated
+		// reserve gets the reservation of the first one
+		callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+		resp, err := s.products.ReserveStock(callCtx, &product.ReserveStockRequest{
+			Id:         checkout.ReservationID,
+			Items:      items,
+			TtlSeconds: int32(reservationTTL / time.Second),
+		})
+		cancel()
+		if err != nil {
+			return compensateUnlessRetryable(checkout, err)
+		}
+		checkout.ReservationID = resp.Reservation.Id
+		checkout.Status = model.CheckoutReserved
+
+	case model.CheckoutReserved:
+		// The order ID was chosen with the checkout, so a repeated create
+		// finds the order of the first one
+		err := s.orders.Create(ctx, &model.Order{
+			ID:         checkout.OrderID,
+			UserID:     checkout.UserID,
+			Items:      checkout.Items,
+			Total:      checkout.Total,
+			Status:     model.StatusPending,
+			CheckoutID: checkout.ID.Hex(),
+		})
+		if err != nil && !errors.Is(err, model.ErrOrderExists) {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+		checkout.Status = model.CheckoutOrdered
+
+	case model.CheckoutOrdered:
+		// Authorizing is keyed by the checkout, so a repeated authorize gets
+		// the authorization of the first one
+		callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+		authorization, err := s.payments.Authorize(callCtx, checkout.ID.Hex(), checkout.UserID, checkout.Total)
+		cancel()
+		if errors.Is(err, payment.ErrDeclined) {
+			compensate(checkout, err.Error())
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to authorize payment: %w", err)
+		}
+		checkout.PaymentID = authorization.ID
+		checkout.Status = model.CheckoutAuthorized
+
+	case model.CheckoutAuthorized:
+		// Committing is the point of no return: a reservation that expired
+		// while the payment was authorized is rolled back instead
+		callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+		_, err := s.products.CommitReservation(callCtx, &product.ReservationRequest{Id: checkout.ReservationID})
+		cancel()
+		if err != nil {
+			return compensateUnlessRetryable(checkout, err)
+		}
+		checkout.Status = model.CheckoutCommitted
+
+	case model.CheckoutCommitted:
+		// From here on every error is retried until the checkout completes
+		if err := s.confirmOrder(ctx, checkout); err != nil {
+			return err
+		}
+		callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+		err := s.payments.Capture(callCtx, checkout.PaymentID)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to capture payment: %w", err)
+		}
+		if checkout.CartID != "" {
+			for _, item := range checkout.Items {
+				if _, err := s.carts.RemoveItem(ctx, checkout.CartID, model.CartItemKey(item.ProductID, item.SKU)); err != nil {
+					return fmt.Errorf("failed to remove ordered items from the cart: %w", err)
+				}
+			}
+		}
+		checkout.Status = model.CheckoutCompleted
+
+	case model.CheckoutCompensating:
+		if checkout.PaymentID != "" {
+			callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+			err := s.payments.Void(callCtx, checkout.PaymentID)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("failed to void payment: %w", err)
+			}
+		}
+		// Cancel does nothing when the order was never created
+		if _, err := s.orders.Cancel(ctx, checkout.OrderID.Hex(), []string{model.StatusPending}, "checkout failed: "+checkout.Error); err != nil {
+			return fmt.Errorf("failed to cancel order: %w", err)
+		}
+		if checkout.ReservationID != "" {
+			callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+			_, err := s.products.ReleaseStock(callCtx, &product.ReservationRequest{Id: checkout.ReservationID})
+			cancel()
+			// A reservation that expired was released by the product service
+			if err != nil && status.Code(err) != codes.FailedPrecondition && status.Code(err) != codes.NotFound {
+				return fmt.Errorf("failed to release stock: %w", err)
+			}
+		}
+		checkout.Status = model.CheckoutFailed
+	}
+
+	return nil
+}
+
+// confirmOrder confirms the order of a committed checkout, succeeding when
+// an earlier attempt already did
+func (s *checkoutService) confirmOrder(ctx context.Context, checkout *model.Checkout) error {
+	confirmed, err := s.orders.Confirm(ctx, checkout.OrderID.Hex(), checkout.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to confirm order: %w", err)
+	}
+	if confirmed != nil {
+		return nil
+	}
+
+	order, err := s.orders.GetByID(ctx, checkout.OrderID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	if order == nil || order.Status != model.StatusConfirmed {
+		return fmt.Errorf("%w: order %s cannot be confirmed", model.ErrInvalidTransition, checkout.OrderID.Hex())
+	}
+	return nil
+}
+
+// compensate starts rolling back the steps a checkout has taken
+func compensate(checkout *model.Checkout, reason string) {
+	checkout.Status = model.CheckoutCompensating
+	checkout.Error = reason
+}
+
+// compensateUnlessRetryable rolls a checkout back after a failed call to
+// another service, unless the failure is worth retrying, which is returned
+func compensateUnlessRetryable(checkout *model.Checkout, err error) error {
+	if retryable(err) {
+		return err
+	}
+
+	compensate(checkout, status.Convert(err).Message())
+	return nil
+}
+
+// retryable reports whether a failed call may succeed when repeated, as
+// opposed to a refusal such as missing stock
+func retryable(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok {
+		// Errors without a status come from the network or a database
+		return true
+	}
+
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+		codes.Canceled, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// checkoutFingerprint identifies the content of a checkout request, so a
+// retry can be told from a different request reusing the idempotency key
+func checkoutFingerprint(req *model.CheckoutRequest) string {
+	var b strings.Builder
+	b.WriteString(money.NormalizeCurrency(req.Currency))
+	if len(req.Items) == 0 {
+		b.WriteString("|cart")
+	}
+	for _, item := range req.Items {
+		fmt.Fprintf(&b, "|%s/%s*%d", item.ProductID, item.SKU, item.Quantity)
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
+)
+
+type OrderService interface {
+	CreateOrder(ctx context.Context, req *model.CreateOrderRequest) (*model.Order, error)
+	// GetOrder finds an order, only among the orders of userID when it is set
+	GetOrder(ctx context.Context, id, userID string) (*model.Order, error)
+	ListOrders(ctx context.Context, params *model.ListOrdersParams) ([]*model.Order, *pagination.PageInfo, error)
+	// CancelOrder cancels a pending order, only among the orders of userID
+	// when it is set
+	CancelOrder(ctx context.Context, id, userID, reason string) (*model.Order, error)
+}
+
+type CartService interface {
+	GetCart(ctx context.Context, cartID string) (*model.CartView, error)
+	AddItem(ctx context.Context, req *model.AddCartItemRequest) (*model.CartView, error)
+	UpdateQuantity(ctx context.Context, cartID, productID, sku string, quantity int32) (*model.CartView, error)
+	RemoveItem(ctx context.Context, cartID, productID, sku string) (*model.CartView, error)
+	MergeCarts(ctx context.Context, sourceID, targetID string) (*model.CartView, error)
+	ClearCart(ctx context.Context, cartID string) error
+}
+
+type CheckoutService interface {
+	Checkout(ctx context.Context, req *model.CheckoutRequest) (*model.Checkout, error)
+	// GetCheckout finds a checkout, only among the checkouts of userID when
+	// it is set
+	GetCheckout(ctx context.Context, id, userID string) (*model.Checkout, error)
+	// ResumeCheckouts drives checkouts that were interrupted, returning how
+	// many of them finished
+	ResumeCheckouts(ctx context.Context) (int, error)
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go-microservice-boilerplate/internal/proto/product"
+	"go-microservice-boilerplate/internal/proto/user"
+	"go-microservice-boilerplate/internal/services/order/model"
+	"go-microservice-boilerplate/internal/services/order/repository"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+type orderService struct {
+	repo     repository.OrderRepository
+	users    user.UserServiceClient
+	products product.ProductServiceClient
+}
+
+// NewOrderService creates an order service that checks users and products
+// against the user and product services
+func NewOrderService(repo repository.OrderRepository, users user.UserServiceClient, products product.ProductServiceClient) OrderService {
+	return &orderService{
+		repo:     repo,
+		users:    users,
+		products: products,
+	}
+}
+
+// CreateOrder places an order for a user. Every item must be a published
+// product, or one of its variants, with enough stock; its name and retail
+// price in the order currency are copied into the order.
+func (s *orderService) CreateOrder(ctx context.Context, req *model.CreateOrderRequest) (*model.Order, error) {
+	order, err := priceOrder(ctx, s.users, s.products, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, order); err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	return order, nil
+}
+
+// priceOrder checks the user and items of an order request and builds the
+// pending order, without storing it
+func priceOrder(ctx context.Context, users user.UserServiceClient, products product.ProductServiceClient, req *model.CreateOrderRequest) (*model.Order, error) {
+	if !primitive.IsValidObjectID(req.UserID) {
+		return nil, apperrors.ErrInvalidInput("invalid user ID")
+	}
+	currency := money.NormalizeCurrency(req.Currency)
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	if !money.Supported(currency) {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("unsupported currency %q", req.Currency))
+	}
+	items, err := mergeItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := users.GetUser(ctx, &user.GetUserRequest{Id: req.UserID}); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	ordered, err := orderedProducts(ctx, products, items, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &model.Order{
+		UserID: req.UserID,
+		Items:  make([]model.OrderItem, 0, len(items)),
+		Total:  money.New(0, currency),
+		Status: model.StatusPending,
+	}
+	for _, item := range items {
+		line, err := orderLine(ordered[item.ProductID], item, currency)
+		if err != nil {
+			return nil, err
+		}
+		if order.Total, err = order.Total.Add(line.Total); err != nil {
+			return nil, err
+		}
+		order.Items = append(order.Items, *line)
+	}
+
+	return order, nil
+}
+
+// mergeItems validates the items of an order, adding up the quantities of
+// items that name the same SKU
+func mergeItems(items []model.OrderItemRequest) ([]model.OrderItemRequest, error) {
+	if len(items) == 0 {
+		return nil, apperrors.ErrInvalidInput("an order needs at least one item")
+	}
+
+	merged := make([]model.OrderItemRequest, 0, len(items))
+	index := make(map[string]int, len(items))
+	for _, item := range items {
+		item.SKU = strings.TrimSpace(item.SKU)
+		if !primitive.IsValidObjectID(item.ProductID) {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("invalid product ID %q", item.ProductID))
+		}
+		if item.SKU == "" {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("item of product %s has no SKU", item.ProductID))
+		}
+		if item.Quantity <= 0 {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("quantity of %s must be positive", item.SKU))
+		}
+
+		key := item.ProductID + "/" + item.SKU
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, item)
+	}
+	if len(merged) > model.MaxItems {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("an order can have at most %d items", model.MaxItems))
+	}
+
+	return merged, nil
+}
+
+// orderedProducts fetches the products of the items in one call, priced in
+// the order currency, keyed by ID
+func orderedProducts(ctx context.Context, products product.ProductServiceClient, items []model.OrderItemRequest, currency string) (map[string]*product.Product, error) {
+	ids := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			ids = append(ids, item.ProductID)
+		}
+	}
+
+	resp, err := products.BatchGetProducts(ctx, &product.BatchGetProductsRequest{
+		Ids:     ids,
+		Pricing: &product.PriceQuery{Currency: currency},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up products: %w", err)
+	}
+	if len(resp.MissingIds) > 0 {
+		return nil, fmt.Errorf("%w: %s", model.ErrProductUnavailable, resp.MissingIds[0])
+	}
+
+	byID := make(map[string]*product.Product, len(resp.Products))
+	for _, p := range resp.Products {
+		byID[p.Id] = p
+	}
+	return byID, nil
+}
+
+// orderLine prices an item from its product, checking the product or
+// variant it names has enough stock
+func orderLine(p *product.Product, item model.OrderItemRequest, currency string) (*model.OrderItem, error) {
+	offer, err := offerFor(p, item.ProductID, item.SKU, currency)
+	if err != nil {
+		return nil, err
+	}
+	if offer.Stock < item.Quantity {
+		return nil, fmt.Errorf("%w: %d of %s available", model.ErrInsufficientStock, offer.Stock, item.SKU)
+	}
+	total, err := offer.Price.Multiply(int64(item.Quantity))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.OrderItem{
+		ProductID: item.ProductID,
+		SKU:       item.SKU,
+		VariantID: offer.VariantID,
+		Name:      p.Name,
+		Quantity:  item.Quantity,
+		UnitPrice: offer.Price,
+		Total:     total,
+	}, nil
+}
+
+// skuOffer is the price and stock a product has for one of its SKUs
+type skuOffer struct {
+	// VariantID is set when the SKU is a variant of the product
+	VariantID string
+	Price     money.Money
+	Stock     int32
+}
+
+// offerFor finds a SKU on a product, either its own or one of its variants,
+// and prices it in currency. p must have been fetched with that currency.
+func offerFor(p *product.Product, productID, sku, currency string) (*skuOffer, error) {
+	if p == nil || p.DisplayPrice == nil || p.DisplayPrice.Price == nil {
+		return nil, fmt.Errorf("%w: %s", model.ErrProductUnavailable, productID)
+	}
+	display := p.DisplayPrice
+	offer := &skuOffer{
+		Price: money.New(display.Price.Amount, display.Price.Currency),
+		Stock: p.Quantity,
+	}
+
+	if sku != p.Sku {
+		var variant *product.Variant
+		for _, v := range p.Variants {
+			if v.Sku == sku {
+				variant = v
+				break
+			}
+		}
+		if variant == nil {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("product %s has no SKU %s", productID, sku))
+		}
+		offer.VariantID = variant.Id
+		offer.Stock = variant.Quantity
+
+		// A variant price in the source currency of the display price is
+		// converted at the same rate
+		if variant.Price != nil {
+			price := money.New(variant.Price.Amount, variant.Price.Currency)
+			switch {
+			case price.Currency == currency:
+				offer.Price = price
+			case display.Converted && display.Source != nil && price.Currency == display.Source.Currency:
+				converted, err := price.Convert(currency, display.Rate)
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert price of %s: %w", sku, err)
+				}
+				offer.Price = converted
+			default:
+				return nil, apperrors.ErrInvalidInput(fmt.Sprintf("%s cannot be priced in %s", sku, currency))
+			}
+		}
+	}
+
+	if offer.Price.Currency != currency {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("%s cannot be priced in %s", sku, currency))
+	}
+
+	return offer, nil
+}
+
+func (s *orderService) GetOrder(ctx context.Context, id, userID string) (*model.Order, error) {
+	if !primitive.IsValidObjectID(id) {
+		return nil, model.ErrOrderNotFound
+	}
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	// The orders of other users do not exist for the caller
+	if order == nil || (userID != "" && order.UserID != userID) {
+		return nil, model.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+func (s *orderService) ListOrders(ctx context.Context, params *model.ListOrdersParams) ([]*model.Order, *pagination.PageInfo, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.Limit > pagination.MaxLimit {
+		return nil, nil, apperrors.ErrInvalidInput(fmt.Sprintf("limit cannot exceed %d", pagination.MaxLimit))
+	}
+	if params.Status != "" && !model.ValidStatus(params.Status) {
+		return nil, nil, apperrors.ErrInvalidInput(fmt.Sprintf("unsupported status %q", params.Status))
+	}
+
+	orders, pageInfo, err := s.repo.List(ctx, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	return orders, pageInfo, nil
+}
+
+func (s *orderService) CancelOrder(ctx context.Context, id, userID, reason string) (*model.Order, error) {
+	order, err := s.GetOrder(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != model.StatusPending {
+		return nil, fmt.Errorf("%w: a %s order cannot be cancelled", model.ErrInvalidTransition, order.Status)
+	}
+	// The checkout that placed the order settles it, with its payment and
+	// stock reservation
+	if order.CheckoutID != "" {
+		return nil, fmt.Errorf("%w: the order is still being checked out", model.ErrInvalidTransition)
+	}
+
+	cancelled, err := s.repo.Cancel(ctx, id, []string{model.StatusPending}, strings.TrimSpace(reason))
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	// Someone else moved the order on since it was read
+	if cancelled == nil {
+		return nil, fmt.Errorf("%w: the order is no longer pending", model.ErrInvalidTransition)
+	}
+
+	return cancelled, nil
+}
@@ -2,46 +2,88 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"go-microservice-boilerplate/internal/exchange"
 	"go-microservice-boilerplate/internal/proto/common"
 	"go-microservice-boilerplate/internal/proto/product"
 	"go-microservice-boilerplate/internal/services/product/model"
 	"go-microservice-boilerplate/internal/services/product/service"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+	"go-microservice-boilerplate/pkg/money"
 )
 
 type ProductGRPCHandler struct {
 	product.UnimplementedProductServiceServer
 	productService service.ProductService
+	// streams is cancelled by CloseStreams to end open watches
+	streams      context.Context
+	closeStreams context.CancelFunc
 }
 
 func NewProductGRPCHandler(productService service.ProductService) *ProductGRPCHandler {
+	streams, closeStreams := context.WithCancel(context.Background())
 	return &ProductGRPCHandler{
 		productService: productService,
+		streams:        streams,
+		closeStreams:   closeStreams,
 	}
 }
 
+// CloseStreams ends every open product watch, which would otherwise keep a
+// graceful stop waiting forever
+func (h *ProductGRPCHandler) CloseStreams() {
+	h.closeStreams()
+}
+
 func (h *ProductGRPCHandler) CreateProduct(ctx context.Context, req *product.CreateProductRequest) (*product.ProductResponse, error) {
 	createReq := &model.CreateProductRequest{
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       req.Price,
+		Price:       moneyFromProto(req.Price),
 		Quantity:    req.Quantity,
 		Category:    req.Category,
 		SKU:         req.Sku,
+
+		ReorderThreshold: req.ReorderThreshold,
+		PriceLists:       listPricesFromProto(req.PriceLists),
+		Attributes:       attributesFromProto(req.Attributes),
+		Status:           req.Status,
+	}
+
+	variants, err := variantsFromProto(req.Variants)
+	if err != nil {
+		code := grpcCode(err)
+		return &product.ProductResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
 	}
+	createReq.Variants = variants
 
 	productModel, err := h.productService.CreateProduct(ctx, createReq)
 	if err != nil {
+		code := grpcCode(err)
 		return &product.ProductResponse{
 			Status: &common.StatusResponse{
-				Code:    int32(codes.Internal),
+				Code:    int32(code),
 				Message: err.Error(),
 				Success: false,
 			},
-		}, status.Error(codes.Internal, err.Error())
+		}, status.Error(code, err.Error())
 	}
 
 	return &product.ProductResponse{
@@ -55,15 +97,19 @@ func (h *ProductGRPCHandler) CreateProduct(ctx context.Context, req *product.Cre
 }
 
 func (h *ProductGRPCHandler) GetProduct(ctx context.Context, req *product.GetProductRequest) (*product.ProductResponse, error) {
-	productModel, err := h.productService.GetProduct(ctx, req.Id)
+	productModel, err := h.productService.GetProduct(ctx, req.Id, &model.GetProductParams{
+		Pricing:            priceQueryFromProto(req.Pricing),
+		IncludeUnpublished: req.IncludeUnpublished,
+	})
 	if err != nil {
+		code := grpcCode(err)
 		return &product.ProductResponse{
 			Status: &common.StatusResponse{
-				Code:    int32(codes.NotFound),
+				Code:    int32(code),
 				Message: err.Error(),
 				Success: false,
 			},
-		}, status.Error(codes.NotFound, err.Error())
+		}, status.Error(code, err.Error())
 	}
 
 	return &product.ProductResponse{
@@ -76,24 +122,93 @@ func (h *ProductGRPCHandler) GetProduct(ctx context.Context, req *product.GetPro
 	}, nil
 }
 
+func (h *ProductGRPCHandler) BatchGetProducts(ctx context.Context, req *product.BatchGetProductsRequest) (*product.BatchGetProductsResponse, error) {
+	products, missing, err := h.productService.BatchGetProducts(ctx, req.Ids, &model.GetProductParams{
+		Pricing:            priceQueryFromProto(req.Pricing),
+		IncludeUnpublished: req.IncludeUnpublished,
+	})
+	if err != nil {
+		code := grpcCode(err)
+		return &product.BatchGetProductsResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoProducts := make([]*product.Product, len(products))
+	for i, p := range products {
+		protoProducts[i] = h.modelToProto(p)
+	}
+
+	return &product.BatchGetProductsResponse{
+		Products:   protoProducts,
+		MissingIds: missing,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Products retrieved successfully",
+			Success: true,
+		},
+	}, nil
+}
+
 func (h *ProductGRPCHandler) UpdateProduct(ctx context.Context, req *product.UpdateProductRequest) (*product.ProductResponse, error) {
 	updateReq := &model.UpdateProductRequest{
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       req.Price,
 		Quantity:    req.Quantity,
 		Category:    req.Category,
+		Status:      req.Status,
+		Fields:      req.GetUpdateMask().GetPaths(),
+
+		ReorderThreshold: req.ReorderThreshold,
+	}
+	if req.Price != nil {
+		price := moneyFromProto(req.Price)
+		updateReq.Price = &price
+	}
+	switch {
+	case len(req.PriceLists) > 0:
+		updateReq.PriceLists = listPricesFromProto(req.PriceLists)
+	case req.ClearPriceLists:
+		updateReq.PriceLists = []model.ListPrice{}
+	}
+	switch {
+	case len(req.Variants) > 0:
+		variants, err := variantsFromProto(req.Variants)
+		if err != nil {
+			code := grpcCode(err)
+			return &product.ProductResponse{
+				Status: &common.StatusResponse{
+					Code:    int32(code),
+					Message: err.Error(),
+					Success: false,
+				},
+			}, status.Error(code, err.Error())
+		}
+		updateReq.Variants = variants
+	case req.ClearVariants:
+		updateReq.Variants = []model.Variant{}
+	}
+	switch {
+	case len(req.Attributes) > 0:
+		updateReq.Attributes = attributesFromProto(req.Attributes)
+	case req.ClearAttributes:
+		updateReq.Attributes = map[string]interface{}{}
 	}
 
 	productModel, err := h.productService.UpdateProduct(ctx, req.Id, updateReq)
 	if err != nil {
+		code := grpcCode(err)
 		return &product.ProductResponse{
 			Status: &common.StatusResponse{
-				Code:    int32(codes.Internal),
+				Code:    int32(code),
 				Message: err.Error(),
 				Success: false,
 			},
-		}, status.Error(codes.Internal, err.Error())
+		}, status.Error(code, err.Error())
 	}
 
 	return &product.ProductResponse{
@@ -124,20 +239,22 @@ func (h *ProductGRPCHandler) DeleteProduct(ctx context.Context, req *product.Del
 }
 
 func (h *ProductGRPCHandler) ListProducts(ctx context.Context, req *product.ListProductsRequest) (*product.ListProductsResponse, error) {
-	page := int(req.Page)
-	limit := int(req.Limit)
-	search := req.Search
-	category := req.Category
+	params := listParamsFromProto(req)
+	params.Page = int(req.Page)
+	params.Limit = int(req.Limit)
+	params.PageToken = req.PageToken
+	params.TotalMode = pagination.TotalMode(req.TotalMode)
 
-	products, total, err := h.productService.ListProducts(ctx, page, limit, search, category)
+	products, pageInfo, err := h.productService.ListProducts(ctx, params)
 	if err != nil {
+		code := grpcCode(err)
 		return &product.ListProductsResponse{
 			Status: &common.StatusResponse{
-				Code:    int32(codes.Internal),
+				Code:    int32(code),
 				Message: err.Error(),
 				Success: false,
 			},
-		}, status.Error(codes.Internal, err.Error())
+		}, status.Error(code, err.Error())
 	}
 
 	protoProducts := make([]*product.Product, len(products))
@@ -145,9 +262,196 @@ func (h *ProductGRPCHandler) ListProducts(ctx context.Context, req *product.List
 		protoProducts[i] = h.modelToProto(p)
 	}
 
+	resp := &product.ListProductsResponse{
+		Products:       protoProducts,
+		NextPageToken:  pageInfo.NextPageToken,
+		TotalEstimated: pageInfo.TotalEstimated,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Products retrieved successfully",
+			Success: true,
+		},
+	}
+	if pageInfo.Total != nil {
+		total := int32(*pageInfo.Total)
+		resp.Total = &total
+	}
+
+	return resp, nil
+}
+
+func (h *ProductGRPCHandler) ListLowStockProducts(ctx context.Context, req *product.ListLowStockProductsRequest) (*product.ListProductsResponse, error) {
+	products, total, err := h.productService.ListLowStockProducts(ctx, int(req.Page), int(req.Limit))
+	if err != nil {
+		code := grpcCode(err)
+		return &product.ListProductsResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoProducts := make([]*product.Product, len(products))
+	for i, p := range products {
+		protoProducts[i] = h.modelToProto(p)
+	}
+
+	total32 := int32(total)
 	return &product.ListProductsResponse{
 		Products: protoProducts,
-		Total:    int32(total),
+		Total:    &total32,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Low stock products retrieved successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+func (h *ProductGRPCHandler) ExportProducts(req *product.ListProductsRequest, stream grpc.ServerStreamingServer[product.Product]) error {
+	err := h.productService.ExportProducts(stream.Context(), listParamsFromProto(req), func(p *model.Product) error {
+		return stream.Send(h.modelToProto(p))
+	})
+	if err != nil {
+		return status.Error(grpcCode(err), err.Error())
+	}
+
+	return nil
+}
+
+func (h *ProductGRPCHandler) WatchProducts(req *product.WatchProductsRequest, stream grpc.ServerStreamingServer[product.ProductChange]) error {
+	params := &model.WatchProductsParams{
+		ProductIDs:         req.ProductIds,
+		Category:           req.Category,
+		CategoryTree:       req.CategoryTree,
+		IncludeUnpublished: req.IncludeUnpublished,
+		ResumeToken:        req.ResumeToken,
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	defer context.AfterFunc(h.streams, cancel)()
+
+	err := h.productService.WatchProducts(ctx, params, func(change *model.ProductChange) error {
+		protoChange := &product.ProductChange{
+			Type:          change.Type,
+			ProductId:     change.ProductID,
+			ChangedFields: change.ChangedFields,
+			ResumeToken:   change.ResumeToken,
+			OccurredAt:    change.OccurredAt.Unix(),
+		}
+		if change.Product != nil {
+			protoChange.Product = h.modelToProto(change.Product)
+		}
+		return stream.Send(protoChange)
+	})
+	if err != nil {
+		return status.Error(grpcCode(err), err.Error())
+	}
+
+	return nil
+}
+
+// listParamsFromProto converts the filters of a list request, shared by
+// listing and exporting products
+func listParamsFromProto(req *product.ListProductsRequest) *model.ListProductsParams {
+	params := &model.ListProductsParams{
+		Search:      req.Search,
+		Category:    req.Category,
+		SortBy:      req.SortBy,
+		SortOrder:   req.SortOrder,
+		MinPrice:    req.MinPrice,
+		MaxPrice:    req.MaxPrice,
+		Currency:    req.Currency,
+		MinQuantity: req.MinQuantity,
+		MaxQuantity: req.MaxQuantity,
+		InStock:     req.InStock,
+		Categories:  req.Categories,
+		Pricing:     priceQueryFromProto(req.Pricing),
+
+		CategoryTree:       req.CategoryTree,
+		Status:             req.Status,
+		IncludeUnpublished: req.IncludeUnpublished,
+	}
+	for _, filter := range req.Attributes {
+		params.Attributes = append(params.Attributes, model.AttributeFilter{
+			Name:  filter.Name,
+			Op:    filter.Op,
+			Value: filter.Value,
+		})
+	}
+	if req.CreatedAfter > 0 {
+		createdAfter := time.Unix(req.CreatedAfter, 0)
+		params.CreatedAfter = &createdAfter
+	}
+	if req.CreatedBefore > 0 {
+		createdBefore := time.Unix(req.CreatedBefore, 0)
+		params.CreatedBefore = &createdBefore
+	}
+
+	return params
+}
+
+func (h *ProductGRPCHandler) SearchProducts(ctx context.Context, req *product.SearchProductsRequest) (*product.SearchProductsResponse, error) {
+	params := &model.SearchProductsParams{
+		Query:           req.Query,
+		Page:            int(req.Page),
+		Limit:           int(req.Limit),
+		SortBy:          req.SortBy,
+		SortOrder:       req.SortOrder,
+		Categories:      req.Categories,
+		MinPrice:        req.MinPrice,
+		MaxPrice:        req.MaxPrice,
+		Currency:        req.Currency,
+		InStock:         req.InStock,
+		PriceBoundaries: req.PriceBoundaries,
+
+		IncludeUnpublished: req.IncludeUnpublished,
+	}
+
+	result, err := h.productService.SearchProducts(ctx, params)
+	if err != nil {
+		code := grpcCode(err)
+		return &product.SearchProductsResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoProducts := make([]*product.Product, len(result.Products))
+	for i, p := range result.Products {
+		protoProducts[i] = h.modelToProto(p)
+	}
+
+	facets := &product.SearchFacets{
+		Stock: &product.StockFacet{
+			InStock:    result.Facets.InStock,
+			OutOfStock: result.Facets.OutOfStock,
+		},
+	}
+	for _, category := range result.Facets.Categories {
+		facets.Categories = append(facets.Categories, &product.FacetCount{
+			Value: category.Value,
+			Count: category.Count,
+		})
+	}
+	for _, bucket := range result.Facets.PriceBuckets {
+		facets.PriceBuckets = append(facets.PriceBuckets, &product.PriceBucket{
+			Min:   bucket.Min,
+			Max:   bucket.Max,
+			Count: bucket.Count,
+		})
+	}
+
+	return &product.SearchProductsResponse{
+		Products: protoProducts,
+		Total:    int32(result.Total),
+		Facets:   facets,
 		Status: &common.StatusResponse{
 			Code:    int32(codes.OK),
 			Message: "Products retrieved successfully",
@@ -156,16 +460,719 @@ func (h *ProductGRPCHandler) ListProducts(ctx context.Context, req *product.List
 	}, nil
 }
 
+func (h *ProductGRPCHandler) SuggestProducts(ctx context.Context, req *product.SuggestProductsRequest) (*product.SuggestProductsResponse, error) {
+	suggestions, err := h.productService.SuggestProducts(ctx, req.Query, int(req.Limit))
+	if err != nil {
+		code := grpcCode(err)
+		return &product.SuggestProductsResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoSuggestions := make([]*product.ProductSuggestion, len(suggestions))
+	for i, suggestion := range suggestions {
+		protoSuggestions[i] = &product.ProductSuggestion{
+			Id:   suggestion.ID,
+			Name: suggestion.Name,
+			Sku:  suggestion.SKU,
+		}
+	}
+
+	return &product.SuggestProductsResponse{
+		Suggestions: protoSuggestions,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Suggestions retrieved successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+func (h *ProductGRPCHandler) PublishProduct(ctx context.Context, req *product.PublishProductRequest) (*product.ProductResponse, error) {
+	productModel, err := h.productService.PublishProduct(ctx, req.Id, unixTime(req.PublishAt))
+	return h.productResponse(productModel, err, "Product published successfully")
+}
+
+func (h *ProductGRPCHandler) ArchiveProduct(ctx context.Context, req *product.ArchiveProductRequest) (*product.ProductResponse, error) {
+	productModel, err := h.productService.ArchiveProduct(ctx, req.Id, unixTime(req.UnpublishAt))
+	return h.productResponse(productModel, err, "Product archived successfully")
+}
+
+func (h *ProductGRPCHandler) ImportProducts(stream grpc.ClientStreamingServer[product.ImportProductsRequest, product.ImportProductsResponse]) error {
+	// The first message decides whether this is a dry run
+	first, err := stream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	dryRun := first != nil && first.DryRun
+
+	next := func() (*model.ImportRow, error) {
+		if first != nil {
+			req := first
+			first = nil
+			return importRowFromProto(req.Row), nil
+		}
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return importRowFromProto(req.Row), nil
+	}
+
+	result, err := h.productService.ImportProducts(stream.Context(), next, dryRun)
+	if err != nil {
+		return status.Error(grpcCode(err), err.Error())
+	}
+
+	resp := &product.ImportProductsResponse{
+		DryRun:  result.DryRun,
+		Total:   int32(result.Total),
+		Created: int32(result.Created),
+		Updated: int32(result.Updated),
+		Failed:  int32(result.Failed),
+		Errors:  make([]*product.ImportRowError, len(result.Errors)),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Products imported successfully",
+			Success: true,
+		},
+	}
+	if result.DryRun {
+		resp.Status.Message = "Products validated successfully"
+	}
+	for i, rowErr := range result.Errors {
+		resp.Errors[i] = &product.ImportRowError{
+			Line:    int32(rowErr.Line),
+			Sku:     rowErr.SKU,
+			Message: rowErr.Message,
+		}
+	}
+
+	return stream.SendAndClose(resp)
+}
+
+func (h *ProductGRPCHandler) AddProductMedia(ctx context.Context, req *product.AddProductMediaRequest) (*product.ProductResponse, error) {
+	productModel, err := h.productService.AddProductMedia(ctx, req.ProductId, mediaFromProto(req.Media))
+	return h.productResponse(productModel, err, "Media added successfully")
+}
+
+func (h *ProductGRPCHandler) DeleteProductMedia(ctx context.Context, req *product.DeleteProductMediaRequest) (*product.ProductResponse, error) {
+	productModel, err := h.productService.DeleteProductMedia(ctx, req.ProductId, req.MediaId)
+	return h.productResponse(productModel, err, "Media deleted successfully")
+}
+
+func (h *ProductGRPCHandler) AdjustStock(ctx context.Context, req *product.AdjustStockRequest) (*product.ProductResponse, error) {
+	productModel, err := h.productService.AdjustStock(ctx, req.Id, &model.AdjustStockRequest{
+		VariantID: req.VariantId,
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+		Note:      req.Note,
+	})
+	if err != nil {
+		code := grpcCode(err)
+		return &product.ProductResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &product.ProductResponse{
+		Product: h.modelToProto(productModel),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Stock adjusted successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+func (h *ProductGRPCHandler) ReserveStock(ctx context.Context, req *product.ReserveStockRequest) (*product.ReservationResponse, error) {
+	reserveReq := &model.ReserveStockRequest{
+		Items: make([]model.ReserveStockItem, len(req.Items)),
+		TTL:   time.Duration(req.TtlSeconds) * time.Second,
+		ID:    req.Id,
+	}
+	for i, item := range req.Items {
+		reserveReq.Items[i] = model.ReserveStockItem{
+			ProductID: item.ProductId,
+			VariantID: item.VariantId,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	reservation, err := h.productService.ReserveStock(ctx, reserveReq)
+	return h.reservationResponse(reservation, err, "Stock reserved successfully")
+}
+
+func (h *ProductGRPCHandler) ReleaseStock(ctx context.Context, req *product.ReservationRequest) (*product.ReservationResponse, error) {
+	reservation, err := h.productService.ReleaseStock(ctx, req.Id)
+	return h.reservationResponse(reservation, err, "Reservation released successfully")
+}
+
+func (h *ProductGRPCHandler) CommitReservation(ctx context.Context, req *product.ReservationRequest) (*product.ReservationResponse, error) {
+	reservation, err := h.productService.CommitReservation(ctx, req.Id)
+	return h.reservationResponse(reservation, err, "Reservation committed successfully")
+}
+
+func (h *ProductGRPCHandler) ListStockMovements(ctx context.Context, req *product.ListStockMovementsRequest) (*product.ListStockMovementsResponse, error) {
+	params := &model.ListStockMovementsParams{
+		ProductID: req.ProductId,
+		VariantID: req.VariantId,
+		Reason:    req.Reason,
+		Limit:     int(req.Limit),
+		PageToken: req.PageToken,
+	}
+	if req.From > 0 {
+		from := time.Unix(req.From, 0)
+		params.From = &from
+	}
+	if req.To > 0 {
+		to := time.Unix(req.To, 0)
+		params.To = &to
+	}
+
+	movements, nextPageToken, err := h.productService.ListStockMovements(ctx, params)
+	if err != nil {
+		code := grpcCode(err)
+		return &product.ListStockMovementsResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoMovements := make([]*product.StockMovement, len(movements))
+	for i, movement := range movements {
+		protoMovements[i] = &product.StockMovement{
+			Id:            movement.ID.Hex(),
+			ProductId:     movement.ProductID.Hex(),
+			Delta:         movement.Delta,
+			Balance:       movement.Balance,
+			Reason:        movement.Reason,
+			Note:          movement.Note,
+			Actor:         movement.Actor,
+			CorrelationId: movement.CorrelationID,
+			CreatedAt:     movement.CreatedAt.Unix(),
+		}
+		if movement.VariantID != nil {
+			protoMovements[i].VariantId = movement.VariantID.Hex()
+		}
+		if movement.ReservationID != nil {
+			protoMovements[i].ReservationId = movement.ReservationID.Hex()
+		}
+	}
+
+	return &product.ListStockMovementsResponse{
+		Movements:     protoMovements,
+		NextPageToken: nextPageToken,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Stock movements retrieved successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+func (h *ProductGRPCHandler) CreateCategory(ctx context.Context, req *product.CreateCategoryRequest) (*product.CategoryResponse, error) {
+	category, err := h.productService.CreateCategory(ctx, &model.CreateCategoryRequest{
+		Name:      req.Name,
+		Slug:      req.Slug,
+		ParentID:  req.ParentId,
+		SortOrder: req.SortOrder,
+
+		Attributes: attributeDefinitionsFromProto(req.Attributes),
+	})
+	return h.categoryResponse(category, err, "Category created successfully")
+}
+
+func (h *ProductGRPCHandler) GetCategory(ctx context.Context, req *product.GetCategoryRequest) (*product.CategoryResponse, error) {
+	category, err := h.productService.GetCategory(ctx, req.Id)
+	return h.categoryResponse(category, err, "Category retrieved successfully")
+}
+
+func (h *ProductGRPCHandler) UpdateCategory(ctx context.Context, req *product.UpdateCategoryRequest) (*product.CategoryResponse, error) {
+	updateReq := &model.UpdateCategoryRequest{
+		Name:      req.Name,
+		Slug:      req.Slug,
+		ParentID:  req.ParentId,
+		SortOrder: req.SortOrder,
+	}
+	switch {
+	case len(req.Attributes) > 0:
+		updateReq.Attributes = attributeDefinitionsFromProto(req.Attributes)
+	case req.ClearAttributes:
+		updateReq.Attributes = []model.AttributeDefinition{}
+	}
+
+	category, err := h.productService.UpdateCategory(ctx, req.Id, updateReq)
+	return h.categoryResponse(category, err, "Category updated successfully")
+}
+
+func (h *ProductGRPCHandler) DeleteCategory(ctx context.Context, req *product.DeleteCategoryRequest) (*common.StatusResponse, error) {
+	if err := h.productService.DeleteCategory(ctx, req.Id); err != nil {
+		code := grpcCode(err)
+		return &common.StatusResponse{
+			Code:    int32(code),
+			Message: err.Error(),
+			Success: false,
+		}, status.Error(code, err.Error())
+	}
+
+	return &common.StatusResponse{
+		Code:    int32(codes.OK),
+		Message: "Category deleted successfully",
+		Success: true,
+	}, nil
+}
+
+func (h *ProductGRPCHandler) ListCategories(ctx context.Context, req *product.ListCategoriesRequest) (*product.ListCategoriesResponse, error) {
+	categories, err := h.productService.ListCategories(ctx, &model.ListCategoriesParams{
+		ParentID:  req.ParentId,
+		Recursive: req.Recursive,
+	})
+	if err != nil {
+		code := grpcCode(err)
+		return &product.ListCategoriesResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoCategories := make([]*product.Category, len(categories))
+	for i, category := range categories {
+		protoCategories[i] = categoryToProto(category)
+	}
+
+	return &product.ListCategoriesResponse{
+		Categories: protoCategories,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Categories retrieved successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+// categoryResponse builds the reply shared by the single category RPCs
+func (h *ProductGRPCHandler) categoryResponse(category *model.Category, err error, message string) (*product.CategoryResponse, error) {
+	if err != nil {
+		code := grpcCode(err)
+		return &product.CategoryResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &product.CategoryResponse{
+		Category: categoryToProto(category),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: message,
+			Success: true,
+		},
+	}, nil
+}
+
+func categoryToProto(c *model.Category) *product.Category {
+	category := &product.Category{
+		Id:        c.ID.Hex(),
+		Name:      c.Name,
+		Slug:      c.Slug,
+		Path:      c.Path,
+		Depth:     c.Depth,
+		SortOrder: c.SortOrder,
+		CreatedAt: c.CreatedAt.Unix(),
+		UpdatedAt: c.UpdatedAt.Unix(),
+
+		Attributes: attributeDefinitionsToProto(c.Attributes),
+	}
+	if c.ParentID != nil {
+		category.ParentId = c.ParentID.Hex()
+	}
+	return category
+}
+
+// productResponse builds the reply shared by the lifecycle RPCs
+func (h *ProductGRPCHandler) productResponse(productModel *model.Product, err error, message string) (*product.ProductResponse, error) {
+	if err != nil {
+		code := grpcCode(err)
+		return &product.ProductResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &product.ProductResponse{
+		Product: h.modelToProto(productModel),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: message,
+			Success: true,
+		},
+	}, nil
+}
+
+// reservationResponse builds the reply shared by the reservation RPCs
+func (h *ProductGRPCHandler) reservationResponse(reservation *model.Reservation, err error, message string) (*product.ReservationResponse, error) {
+	if err != nil {
+		code := grpcCode(err)
+		return &product.ReservationResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &product.ReservationResponse{
+		Reservation: &product.Reservation{
+			Id:         reservation.ID.Hex(),
+			Items:      stockItemsToProto(reservation.Items),
+			Status:     reservation.Status,
+			ExpiresAt:  reservation.ExpiresAt.Unix(),
+			CreatedAt:  reservation.CreatedAt.Unix(),
+			UpdatedAt:  reservation.UpdatedAt.Unix(),
+			Unreturned: stockItemsToProto(reservation.Unreturned),
+		},
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: message,
+			Success: true,
+		},
+	}, nil
+}
+
+func stockItemsToProto(stockItems []model.StockItem) []*product.StockItem {
+	items := make([]*product.StockItem, len(stockItems))
+	for i, item := range stockItems {
+		items[i] = &product.StockItem{
+			ProductId: item.ProductID.Hex(),
+			Quantity:  item.Quantity,
+		}
+		if item.VariantID != nil {
+			items[i].VariantId = item.VariantID.Hex()
+		}
+	}
+	return items
+}
+
 func (h *ProductGRPCHandler) modelToProto(p *model.Product) *product.Product {
 	return &product.Product{
 		Id:          p.ID.Hex(),
 		Name:        p.Name,
 		Description: p.Description,
-		Price:       p.Price,
+		Price:       moneyToProto(p.Price),
 		Quantity:    p.Quantity,
 		Category:    p.Category,
 		Sku:         p.SKU,
 		CreatedAt:   p.CreatedAt.Unix(),
 		UpdatedAt:   p.UpdatedAt.Unix(),
+		Score:       p.Score,
+
+		ReorderThreshold: p.ReorderThreshold,
+		PriceLists:       listPricesToProto(p.PriceLists),
+		DisplayPrice:     displayPriceToProto(p.DisplayPrice),
+		Variants:         variantsToProto(p.Variants),
+		Attributes:       attributesToProto(p.Attributes),
+		Status:           p.CurrentStatus(),
+		PublishedAt:      unixSeconds(p.PublishedAt),
+		PublishAt:        unixSeconds(p.PublishAt),
+		UnpublishAt:      unixSeconds(p.UnpublishAt),
+		Media:            mediaToProto(p.Media),
+	}
+}
+
+// unixTime converts optional unix seconds, where zero means unset
+func unixTime(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0)
+	return &t
+}
+
+// unixSeconds converts an optional time to unix seconds, zero when unset
+func unixSeconds(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func importRowFromProto(row *product.ImportProductRow) *model.ImportRow {
+	if row == nil {
+		return &model.ImportRow{}
+	}
+	return &model.ImportRow{
+		Line:             int(row.Line),
+		SKU:              row.Sku,
+		Name:             row.Name,
+		Description:      row.Description,
+		Price:            row.Price,
+		Currency:         row.Currency,
+		Quantity:         row.Quantity,
+		Category:         row.Category,
+		ReorderThreshold: row.ReorderThreshold,
+		Status:           row.Status,
+	}
+}
+
+// mediaFromProto converts the metadata of an uploaded file, the service
+// assigns the ID, URLs and thumbnail state
+func mediaFromProto(media *product.Media) *model.Media {
+	if media == nil {
+		return nil
+	}
+	return &model.Media{
+		Key:         media.Key,
+		ContentType: media.ContentType,
+		Size:        media.Size,
+		Width:       media.Width,
+		Height:      media.Height,
+		Checksum:    media.Checksum,
+	}
+}
+
+func mediaToProto(media []model.Media) []*product.Media {
+	result := make([]*product.Media, len(media))
+	for i, item := range media {
+		result[i] = &product.Media{
+			Id:              item.ID.Hex(),
+			Key:             item.Key,
+			Url:             item.URL,
+			ContentType:     item.ContentType,
+			Size:            item.Size,
+			Width:           item.Width,
+			Height:          item.Height,
+			Checksum:        item.Checksum,
+			ThumbnailUrl:    item.ThumbnailURL,
+			ThumbnailStatus: item.ThumbnailStatus,
+			CreatedAt:       item.CreatedAt.Unix(),
+		}
+	}
+	return result
+}
+
+// variantsFromProto converts requested variants. An empty id marks a new
+// variant.
+func variantsFromProto(variants []*product.Variant) ([]model.Variant, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	result := make([]model.Variant, len(variants))
+	for i, variant := range variants {
+		result[i] = model.Variant{
+			SKU:      variant.Sku,
+			Options:  variant.Options,
+			Quantity: variant.Quantity,
+		}
+		if variant.Id != "" {
+			id, err := primitive.ObjectIDFromHex(variant.Id)
+			if err != nil {
+				return nil, apperrors.ErrInvalidInput(fmt.Sprintf("invalid variant id %q", variant.Id))
+			}
+			result[i].ID = id
+		}
+		if variant.Price != nil {
+			price := moneyFromProto(variant.Price)
+			result[i].Price = &price
+		}
+	}
+	return result, nil
+}
+
+func variantsToProto(variants []model.Variant) []*product.Variant {
+	result := make([]*product.Variant, len(variants))
+	for i, variant := range variants {
+		result[i] = &product.Variant{
+			Id:       variant.ID.Hex(),
+			Sku:      variant.SKU,
+			Options:  variant.Options,
+			Quantity: variant.Quantity,
+		}
+		if variant.Price != nil {
+			result[i].Price = moneyToProto(*variant.Price)
+		}
+	}
+	return result
+}
+
+// attributesFromProto unwraps attribute values. A value with no field set
+// becomes nil, which the category schema rejects.
+func attributesFromProto(attributes map[string]*product.AttributeValue) map[string]interface{} {
+	if len(attributes) == 0 {
+		return nil
+	}
+	result := make(map[string]interface{}, len(attributes))
+	for name, value := range attributes {
+		switch {
+		case value.StringValue != nil:
+			result[name] = *value.StringValue
+		case value.NumberValue != nil:
+			result[name] = *value.NumberValue
+		case value.BoolValue != nil:
+			result[name] = *value.BoolValue
+		default:
+			result[name] = nil
+		}
+	}
+	return result
+}
+
+func attributesToProto(attributes map[string]interface{}) map[string]*product.AttributeValue {
+	result := make(map[string]*product.AttributeValue, len(attributes))
+	for name, value := range attributes {
+		switch v := value.(type) {
+		case string:
+			result[name] = &product.AttributeValue{StringValue: &v}
+		case float64:
+			result[name] = &product.AttributeValue{NumberValue: &v}
+		case bool:
+			result[name] = &product.AttributeValue{BoolValue: &v}
+		}
+	}
+	return result
+}
+
+func attributeDefinitionsFromProto(definitions []*product.AttributeDefinition) []model.AttributeDefinition {
+	if len(definitions) == 0 {
+		return nil
+	}
+	result := make([]model.AttributeDefinition, len(definitions))
+	for i, definition := range definitions {
+		result[i] = model.AttributeDefinition{
+			Name:     definition.Name,
+			Type:     definition.Type,
+			Required: definition.Required,
+			Unit:     definition.Unit,
+			Values:   definition.Values,
+		}
+	}
+	return result
+}
+
+func attributeDefinitionsToProto(definitions []model.AttributeDefinition) []*product.AttributeDefinition {
+	result := make([]*product.AttributeDefinition, len(definitions))
+	for i, definition := range definitions {
+		result[i] = &product.AttributeDefinition{
+			Name:     definition.Name,
+			Type:     definition.Type,
+			Required: definition.Required,
+			Unit:     definition.Unit,
+			Values:   definition.Values,
+		}
+	}
+	return result
+}
+
+func listPricesFromProto(prices []*product.ListPrice) []model.ListPrice {
+	if len(prices) == 0 {
+		return nil
+	}
+	result := make([]model.ListPrice, len(prices))
+	for i, price := range prices {
+		result[i] = model.ListPrice{List: price.List, Price: moneyFromProto(price.Price)}
+	}
+	return result
+}
+
+func listPricesToProto(prices []model.ListPrice) []*product.ListPrice {
+	result := make([]*product.ListPrice, len(prices))
+	for i, price := range prices {
+		result[i] = &product.ListPrice{List: price.List, Price: moneyToProto(price.Price)}
+	}
+	return result
+}
+
+func priceQueryFromProto(query *product.PriceQuery) *model.PriceQuery {
+	if query == nil {
+		return nil
+	}
+	return &model.PriceQuery{List: query.List, Currency: query.Currency}
+}
+
+func displayPriceToProto(price *model.DisplayPrice) *product.DisplayPrice {
+	if price == nil {
+		return nil
+	}
+
+	result := &product.DisplayPrice{
+		List:      price.List,
+		Price:     moneyToProto(price.Price),
+		Source:    moneyToProto(price.Source),
+		Converted: price.Converted,
+		Rate:      price.Rate,
+	}
+	if price.RateTimestamp != nil {
+		result.RateTimestamp = price.RateTimestamp.Unix()
+	}
+	return result
+}
+
+func moneyFromProto(m *common.Money) money.Money {
+	if m == nil {
+		return money.Money{}
+	}
+	return money.New(m.Amount, m.Currency)
+}
+
+func moneyToProto(m money.Money) *common.Money {
+	return &common.Money{
+		Amount:   m.Amount,
+		Currency: m.Currency,
 	}
 }
+
+// grpcCode maps service errors onto gRPC status codes
+func grpcCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, pagination.ErrInvalidPageToken), errors.Is(err, money.ErrOverflow):
+		return codes.InvalidArgument
+	case errors.Is(err, model.ErrProductNotFound), errors.Is(err, model.ErrReservationNotFound), errors.Is(err, model.ErrCategoryNotFound),
+		errors.Is(err, model.ErrVariantNotFound), errors.Is(err, model.ErrMediaNotFound):
+		return codes.NotFound
+	case errors.Is(err, model.ErrCategoryExists):
+		return codes.AlreadyExists
+	case errors.Is(err, model.ErrInsufficientStock), errors.Is(err, model.ErrReservationClosed), errors.Is(err, model.ErrCategoryInUse),
+		errors.Is(err, model.ErrInvalidTransition), errors.Is(err, model.ErrInvalidResumeToken):
+		return codes.FailedPrecondition
+	case errors.Is(err, model.ErrProductChanged):
+		return codes.Aborted
+	case errors.Is(err, exchange.ErrRateNotFound), errors.Is(err, model.ErrWatchUnsupported),
+		errors.Is(err, model.ErrWatchFiltersUnsupported):
+		return codes.Unavailable
+	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Code {
+		case http.StatusBadRequest:
+			return codes.InvalidArgument
+		case http.StatusNotFound:
+			return codes.NotFound
+		case http.StatusConflict:
+			return codes.AlreadyExists
+		}
+	}
+
+	return codes.Internal
+}
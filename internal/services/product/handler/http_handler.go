@@ -45,7 +45,7 @@ func (h *ProductHTTPHandler) CreateProduct(c *gin.Context) {
 func (h *ProductHTTPHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
 
-	product, err := h.productService.GetProduct(c.Request.Context(), id)
+	product, err := h.productService.GetProduct(c.Request.Context(), id, &model.GetProductParams{Pricing: priceQuery(c)})
 	if err != nil {
 		response.Error(c, http.StatusNotFound, "Product not found", err.Error())
 		return
@@ -87,10 +87,19 @@ func (h *ProductHTTPHandler) DeleteProduct(c *gin.Context) {
 func (h *ProductHTTPHandler) ListProducts(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	search := c.Query("search")
-	category := c.Query("category")
 
-	products, total, err := h.productService.ListProducts(c.Request.Context(), page, limit, search, category)
+	params := &model.ListProductsParams{
+		Page:      page,
+		Limit:     limit,
+		PageToken: c.Query("page_token"),
+		Search:    c.Query("search"),
+		Category:  c.Query("category"),
+		Pricing:   priceQuery(c),
+
+		CategoryTree: c.Query("category_tree"),
+	}
+
+	products, pageInfo, err := h.productService.ListProducts(c.Request.Context(), params)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Failed to list products", err.Error())
 		return
@@ -99,12 +108,22 @@ func (h *ProductHTTPHandler) ListProducts(c *gin.Context) {
 	result := gin.H{
 		"products": products,
 		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
+			"page":            params.Page,
+			"limit":           params.Limit,
+			"total":           pageInfo.Total,
+			"total_estimated": pageInfo.TotalEstimated,
+			"next_page_token": pageInfo.NextPageToken,
 		},
 	}
 
 	response.Success(c, http.StatusOK, "Products retrieved successfully", result)
 }
+
+// priceQuery reads the optional price_list and price_currency parameters
+func priceQuery(c *gin.Context) *model.PriceQuery {
+	list, currency := c.Query("price_list"), c.Query("price_currency")
+	if list == "" && currency == "" {
+		return nil
+	}
+	return &model.PriceQuery{List: list, Currency: currency}
+}
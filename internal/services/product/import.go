@@ -0,0 +1,262 @@
+package product
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"google.golang.org/grpc"
+
+	"go-microservice-boilerplate/internal/proto/product"
+)
+
+// ImportStream is the client side of the ImportProducts RPC
+type ImportStream = grpc.ClientStreamingClient[product.ImportProductsRequest, product.ImportProductsResponse]
+
+// importColumns are the CSV columns an import understands; sku, name, price
+// and category are required
+var importColumns = []string{"sku", "name", "description", "price", "currency", "quantity", "category", "reorder_threshold", "status"}
+
+// importRecord is one product of a JSON import
+type importRecord struct {
+	SKU              string      `json:"sku"`
+	Name             string      `json:"name"`
+	Description      string      `json:"description"`
+	Price            json.Number `json:"price"`
+	Currency         string      `json:"currency"`
+	Quantity         *int32      `json:"quantity"`
+	Category         string      `json:"category"`
+	ReorderThreshold *int32      `json:"reorder_threshold"`
+	Status           string      `json:"status"`
+}
+
+// ImportProducts streams the products of a CSV or JSON file to the product
+// service, which creates or updates them by SKU, and writes a report of the
+// rows that failed. JSON files hold an array of products or one product per
+// line. It returns the number of failed rows.
+func ImportProducts(stream ImportStream, path string, dryRun bool, out io.Writer) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer file.Close()
+
+	// Rows that cannot even be read are reported together with the rows
+	// the product service rejects
+	var rejected []*product.ImportRowError
+	send := func(row *product.ImportProductRow) error {
+		err := stream.Send(&product.ImportProductsRequest{DryRun: dryRun, Row: row})
+		if errors.Is(err, io.EOF) {
+			// The service ended the stream, its status says why
+			_, err = stream.CloseAndRecv()
+		}
+		return err
+	}
+	reject := func(line int, sku string, err error) {
+		rejected = append(rejected, &product.ImportRowError{Line: int32(line), Sku: sku, Message: err.Error()})
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		err = readCSVRows(file, send, reject)
+	case ".json", ".jsonl", ".ndjson":
+		err = readJSONRows(file, send, reject)
+	default:
+		return 0, fmt.Errorf("unsupported import file %s, expected .csv or .json", path)
+	}
+	if err != nil {
+		stream.CloseSend()
+		return 0, err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, fmt.Errorf("import failed: %w", err)
+	}
+
+	report := append(resp.Errors, rejected...)
+	sort.SliceStable(report, func(i, j int) bool { return report[i].Line < report[j].Line })
+	failed := int(resp.Failed) + len(rejected)
+
+	if len(report) > 0 {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LINE\tSKU\tERROR")
+		for _, rowErr := range report {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", rowErr.Line, rowErr.Sku, rowErr.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return failed, err
+		}
+	}
+
+	verb := "imported"
+	if dryRun {
+		verb = "validated (dry run)"
+	}
+	fmt.Fprintf(out, "%d row(s) %s: %d created, %d updated, %d failed\n",
+		int(resp.Total)+len(rejected), verb, resp.Created, resp.Updated, failed)
+	return failed, nil
+}
+
+// readCSVRows sends every row of a CSV file with a header row naming its
+// columns
+func readCSVRows(r io.Reader, send func(*product.ImportProductRow) error, reject func(int, string, error)) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"sku", "name", "price", "category"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("CSV header is missing the %s column, expected %s", name, strings.Join(importColumns, ","))
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && parseErr.Err == csv.ErrFieldCount {
+			reject(parseErr.StartLine, "", parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := &product.ImportProductRow{
+			Line:        int32(line),
+			Sku:         field("sku"),
+			Name:        field("name"),
+			Description: field("description"),
+			Price:       field("price"),
+			Currency:    field("currency"),
+			Category:    field("category"),
+			Status:      field("status"),
+		}
+		if row.Quantity, err = optionalInt32(field("quantity")); err != nil {
+			reject(line, row.Sku, fmt.Errorf("invalid quantity: %w", err))
+			continue
+		}
+		if row.ReorderThreshold, err = optionalInt32(field("reorder_threshold")); err != nil {
+			reject(line, row.Sku, fmt.Errorf("invalid reorder threshold: %w", err))
+			continue
+		}
+
+		if err := send(row); err != nil {
+			return fmt.Errorf("failed to send row %d: %w", line, err)
+		}
+	}
+}
+
+// readJSONRows sends every product of a JSON array, or of a stream of JSON
+// objects, numbering them from one
+func readJSONRows(r io.Reader, send func(*product.ImportProductRow) error, reject func(int, string, error)) error {
+	buffered := bufio.NewReader(r)
+	decoder := json.NewDecoder(buffered)
+
+	array, err := startsWithArray(buffered)
+	if err != nil {
+		return err
+	}
+	if array {
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("failed to read JSON: %w", err)
+		}
+	}
+
+	for line := 1; ; line++ {
+		if array && !decoder.More() {
+			return nil
+		}
+
+		var record importRecord
+		err := decoder.Decode(&record)
+		if !array && errors.Is(err, io.EOF) {
+			return nil
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			reject(line, record.SKU, fmt.Errorf("invalid %s", typeErr.Field))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read product %d: %w", line, err)
+		}
+
+		row := &product.ImportProductRow{
+			Line:             int32(line),
+			Sku:              strings.TrimSpace(record.SKU),
+			Name:             record.Name,
+			Description:      record.Description,
+			Price:            record.Price.String(),
+			Currency:         record.Currency,
+			Quantity:         record.Quantity,
+			Category:         record.Category,
+			ReorderThreshold: record.ReorderThreshold,
+			Status:           record.Status,
+		}
+		if err := send(row); err != nil {
+			return fmt.Errorf("failed to send product %d: %w", line, err)
+		}
+	}
+}
+
+// startsWithArray reports whether the first non-blank character of r opens
+// a JSON array, without consuming it
+func startsWithArray(r *bufio.Reader) (bool, error) {
+	for n := 1; ; n++ {
+		peeked, err := r.Peek(n)
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to read JSON: %w", err)
+		}
+		switch c := peeked[n-1]; c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		default:
+			return c == '[', nil
+		}
+	}
+}
+
+// optionalInt32 parses an optional CSV number, empty meaning unset
+func optionalInt32(value string) (*int32, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a whole number", value)
+	}
+	n := int32(parsed)
+	return &n, nil
+}
@@ -0,0 +1,120 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// migrateBatchSize bounds the number of updates sent per bulk write
+const migrateBatchSize = 500
+
+// legacyPriceIndexes were built on the float price field and are replaced by
+// indexes on price.amount
+var legacyPriceIndexes = []string{"category_1_price_1__id_1", "price_1__id_1"}
+
+// MigratePrices converts products still storing price as a float of major
+// units into money of the given currency, then moves the price indexes to
+// price.amount. It is safe to run repeatedly and returns the number of
+// products converted.
+func MigratePrices(ctx context.Context, mongodb *database.MongoDB, currency string, out io.Writer) (int, error) {
+	currency = money.NormalizeCurrency(currency)
+	if !money.Supported(currency) {
+		return 0, fmt.Errorf("%w: %q", money.ErrUnsupportedCurrency, currency)
+	}
+
+	collection := mongodb.Collection("products")
+	opts := options.Find().SetProjection(bson.M{"price": 1})
+	cursor, err := collection.Find(ctx, bson.M{"price": bson.M{"$type": "number"}}, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find legacy prices: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	converted := 0
+	var batch []mongo.WriteModel
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		result, err := collection.BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return fmt.Errorf("failed to convert prices: %w", err)
+		}
+		// Prices changed since they were read are not counted
+		converted += int(result.ModifiedCount)
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Price float64            `bson:"price"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return converted, fmt.Errorf("failed to decode product: %w", err)
+		}
+
+		price, err := money.FromFloat(doc.Price, currency)
+		if err != nil {
+			return converted, err
+		}
+
+		// Match the old value too, so a price changed meanwhile is left alone
+		batch = append(batch, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID, "price": doc.Price}).
+			SetUpdate(bson.M{"$set": bson.M{"price": price}}))
+
+		if len(batch) == migrateBatchSize {
+			if err := flush(); err != nil {
+				return converted, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return converted, err
+	}
+	if err := flush(); err != nil {
+		return converted, err
+	}
+
+	if err := migratePriceIndexes(ctx, collection); err != nil {
+		return converted, err
+	}
+
+	fmt.Fprintf(out, "Converted %d product price(s) to %s minor units\n", converted, currency)
+	return converted, nil
+}
+
+// migratePriceIndexes moves the price indexes to price.amount
+func migratePriceIndexes(ctx context.Context, collection *mongo.Collection) error {
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "price.amount", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "price.amount", Value: 1}, {Key: "_id", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create price indexes: %w", err)
+	}
+
+	for _, name := range legacyPriceIndexes {
+		if _, err := collection.Indexes().DropOne(ctx, name); err != nil {
+			var cmdErr mongo.CommandError
+			if errors.As(err, &cmdErr) && cmdErr.Name == "IndexNotFound" {
+				continue
+			}
+			return fmt.Errorf("failed to drop index %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Attribute types
+const (
+	AttributeString  = "string"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+	AttributeEnum    = "enum"
+)
+
+// Attribute filter operators
+const (
+	AttributeEq  = "eq"
+	AttributeMin = "min"
+	AttributeMax = "max"
+)
+
+// AttributeTypes are the accepted attribute types
+var AttributeTypes = map[string]bool{
+	AttributeString:  true,
+	AttributeNumber:  true,
+	AttributeBoolean: true,
+	AttributeEnum:    true,
+}
+
+// AttributeDefinition declares a typed attribute that products of a
+// category, and of every category below it, may carry
+type AttributeDefinition struct {
+	Name     string   `bson:"name" json:"name" binding:"required"`
+	Type     string   `bson:"type" json:"type" binding:"required"`
+	Required bool     `bson:"required" json:"required"`
+	Unit     string   `bson:"unit,omitempty" json:"unit,omitempty"`
+	Values   []string `bson:"values,omitempty" json:"values,omitempty"`
+}
+
+// Check verifies that value has the declared type, returning it in its
+// stored form: strings, float64 numbers and booleans
+func (d *AttributeDefinition) Check(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case AttributeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int32:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		}
+	case AttributeBoolean:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case AttributeString:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case AttributeEnum:
+		if v, ok := value.(string); ok {
+			for _, allowed := range d.Values {
+				if v == allowed {
+					return v, nil
+				}
+			}
+			return nil, fmt.Errorf("attribute %s must be one of %v", d.Name, d.Values)
+		}
+	}
+	return nil, fmt.Errorf("attribute %s must be a %s", d.Name, d.Type)
+}
+
+// AttributeFilter restricts a listing to products whose attribute equals
+// Value, or for min and max is a number within the bound
+type AttributeFilter struct {
+	Name  string
+	Op    string
+	Value string
+}
+
+// Candidates returns the stored values an equality filter may match. Query
+// strings carry no type, so "10" matches both the string and the number.
+func (f *AttributeFilter) Candidates() []interface{} {
+	candidates := []interface{}{f.Value}
+	if number, err := strconv.ParseFloat(f.Value, 64); err == nil {
+		candidates = append(candidates, number)
+	}
+	if boolean, err := strconv.ParseBool(f.Value); err == nil {
+		candidates = append(candidates, boolean)
+	}
+	return candidates
+}
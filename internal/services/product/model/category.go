@@ -0,0 +1,98 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryPathSeparator joins the slugs of a category and its ancestors
+const CategoryPathSeparator = "/"
+
+var (
+	// ErrCategoryNotFound is returned for unknown category IDs
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryExists is returned when a sibling already uses the slug
+	ErrCategoryExists = errors.New("a category with this path already exists")
+	// ErrCategoryInUse is returned when deleting a category that still has
+	// subcategories or products
+	ErrCategoryInUse = errors.New("category still has subcategories or products")
+)
+
+// Category is a node of the product category tree. Path is the materialized
+// path of slugs from the root, e.g. "electronics/laptops", and is what
+// products store as their category, so a subtree is a single prefix match.
+type Category struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name      string              `bson:"name" json:"name"`
+	Slug      string              `bson:"slug" json:"slug"`
+	ParentID  *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Path      string              `bson:"path" json:"path"`
+	Depth     int32               `bson:"depth" json:"depth"`
+	SortOrder int32               `bson:"sort_order" json:"sort_order"`
+	// Attributes is the schema of product attributes introduced by this
+	// category, products also get the attributes of its ancestors
+	Attributes []AttributeDefinition `bson:"attributes,omitempty" json:"attributes,omitempty"`
+	CreatedAt  time.Time             `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time             `bson:"updated_at" json:"updated_at"`
+}
+
+type CreateCategoryRequest struct {
+	Name       string                `json:"name" binding:"required"`
+	Slug       string                `json:"slug"`
+	ParentID   string                `json:"parent_id"`
+	SortOrder  int32                 `json:"sort_order"`
+	Attributes []AttributeDefinition `json:"attributes" binding:"omitempty,dive"`
+}
+
+type UpdateCategoryRequest struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	// ParentID moves the category, an empty string moves it to the top level
+	ParentID  *string `json:"parent_id"`
+	SortOrder *int32  `json:"sort_order"`
+	// Attributes replaces the schema when set, an empty list clears it
+	Attributes []AttributeDefinition `json:"attributes" binding:"omitempty,dive"`
+}
+
+type ListCategoriesParams struct {
+	// ParentID lists the children of a category, empty for the top level
+	ParentID string
+	// Recursive includes every descendant instead of direct children only
+	Recursive bool
+}
+
+var (
+	slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// Slugify derives a slug from a category name, e.g. "Home & Garden" becomes
+// "home-garden"
+func Slugify(name string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// ValidSlug reports whether slug only uses lowercase letters, digits and
+// single dashes
+func ValidSlug(slug string) bool {
+	return slugPattern.MatchString(slug)
+}
+
+// NormalizeCategoryPath lower-cases a category path and strips surrounding
+// whitespace and separators, so "Electronics/Laptops/" matches
+// "electronics/laptops"
+func NormalizeCategoryPath(path string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(path)), CategoryPathSeparator)
+}
+
+// JoinCategoryPath appends a slug to the path of its parent
+func JoinCategoryPath(parent, slug string) string {
+	if parent == "" {
+		return slug
+	}
+	return parent + CategoryPathSeparator + slug
+}
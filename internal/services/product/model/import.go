@@ -0,0 +1,37 @@
+package model
+
+// ImportRow is one product of a bulk import, matched to an existing product
+// by SKU. Empty optional fields keep the values of an existing product.
+type ImportRow struct {
+	// Line locates the row in the imported file for the error report
+	Line        int
+	SKU         string
+	Name        string
+	Description string
+	// Price is a decimal amount in major units, such as 19.99
+	Price            string
+	Currency         string
+	Quantity         *int32
+	Category         string
+	ReorderThreshold *int32
+	// Status only applies to new products, which are drafts by default
+	Status string
+}
+
+// ImportRowError reports why a row was not imported
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku"`
+	Message string `json:"message"`
+}
+
+// ImportResult summarizes a bulk import. A dry run counts what would have
+// been created and updated without writing anything.
+type ImportResult struct {
+	DryRun  bool             `json:"dry_run"`
+	Total   int              `json:"total"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors,omitempty"`
+}
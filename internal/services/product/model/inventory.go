@@ -0,0 +1,169 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reservation statuses
+const (
+	ReservationActive    = "active"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+)
+
+// Stock alert statuses
+const (
+	AlertOpen     = "open"
+	AlertResolved = "resolved"
+)
+
+// Events sent through the notifier
+const (
+	EventStockLow         = "stock.low"
+	EventStockReplenished = "stock.replenished"
+)
+
+// Stock movement reasons
+const (
+	ReasonInitial     = "initial"
+	ReasonSale        = "sale"
+	ReasonRestock     = "restock"
+	ReasonAdjustment  = "adjustment"
+	ReasonReservation = "reservation"
+	ReasonRelease     = "release"
+)
+
+// AdjustmentReasons are the reasons accepted for manual stock adjustments.
+// Reservation entries are only written by the reservation workflow.
+var AdjustmentReasons = map[string]bool{
+	ReasonSale:       true,
+	ReasonRestock:    true,
+	ReasonAdjustment: true,
+}
+
+const (
+	// DefaultReservationTTL is how long a reservation holds stock when the
+	// caller does not ask for a specific duration
+	DefaultReservationTTL = 15 * time.Minute
+	// MaxReservationTTL bounds caller supplied reservation durations
+	MaxReservationTTL = 24 * time.Hour
+)
+
+var (
+	// ErrProductNotFound is returned when a stock change targets a product
+	// that does not exist
+	ErrProductNotFound = errors.New("product not found")
+	// ErrReservationNotFound is returned for unknown reservation IDs
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrInsufficientStock is returned when a decrement would take a product
+	// below zero
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrReservationClosed is returned when a reservation was already closed
+	// the other way, or has expired before a commit
+	ErrReservationClosed = errors.New("reservation is no longer active")
+)
+
+// StockItem is a quantity of a single product held by a reservation
+type StockItem struct {
+	ProductID primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	VariantID *primitive.ObjectID `bson:"variant_id,omitempty" json:"variant_id,omitempty"`
+	Quantity  int32               `bson:"quantity" json:"quantity"`
+}
+
+// Reservation holds stock for a pending checkout. Stock is deducted when the
+// reservation is made and handed back if it is released or expires.
+type Reservation struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Items     []StockItem        `bson:"items" json:"items"`
+	Status    string             `bson:"status" json:"status"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	// Unreturned lists the items whose product or variant was gone when
+	// the reservation was released, so their stock could not be handed back
+	Unreturned []StockItem `bson:"unreturned,omitempty" json:"unreturned,omitempty"`
+	CreatedAt  time.Time   `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time   `bson:"updated_at" json:"updated_at"`
+}
+
+// StockMovement is an immutable ledger entry recording a single change to
+// the quantity of a product. Entries are only ever inserted, so summing the
+// deltas of a product reproduces its quantity.
+type StockMovement struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ProductID     primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	VariantID     *primitive.ObjectID `bson:"variant_id,omitempty" json:"variant_id,omitempty"`
+	Delta         int32               `bson:"delta" json:"delta"`
+	Balance       int32               `bson:"balance" json:"balance"`
+	Reason        string              `bson:"reason" json:"reason"`
+	Note          string              `bson:"note,omitempty" json:"note,omitempty"`
+	Actor         string              `bson:"actor,omitempty" json:"actor,omitempty"`
+	CorrelationID string              `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
+	ReservationID *primitive.ObjectID `bson:"reservation_id,omitempty" json:"reservation_id,omitempty"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// StockAlert is raised when a product falls below its reorder threshold and
+// stays open until the stock is replenished, so each shortage is reported once
+type StockAlert struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProductID        primitive.ObjectID `bson:"product_id" json:"product_id"`
+	SKU              string             `bson:"sku" json:"sku"`
+	Name             string             `bson:"name" json:"name"`
+	Quantity         int32              `bson:"quantity" json:"quantity"`
+	ReorderThreshold int32              `bson:"reorder_threshold" json:"reorder_threshold"`
+	Status           string             `bson:"status" json:"status"`
+	ResolvedQuantity *int32             `bson:"resolved_quantity,omitempty" json:"resolved_quantity,omitempty"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	ResolvedAt       *time.Time         `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+type ListStockMovementsParams struct {
+	ProductID string
+	// VariantID limits the ledger to one variant of the product
+	VariantID string
+	From      *time.Time
+	To        *time.Time
+	Reason    string
+	Limit     int
+	PageToken string
+}
+
+// StockDrift reports a product, or one of its variants, whose quantity
+// disagrees with its ledger
+type StockDrift struct {
+	ProductID primitive.ObjectID  `json:"product_id"`
+	VariantID *primitive.ObjectID `json:"variant_id,omitempty"`
+	SKU       string              `json:"sku"`
+	Name      string              `json:"name"`
+	Quantity  int32               `json:"quantity"`
+	Ledger    int64               `json:"ledger"`
+	Entries   int64               `json:"entries"`
+}
+
+// Drift is how far the stored quantity is ahead of the ledger
+func (d *StockDrift) Drift() int64 {
+	return int64(d.Quantity) - d.Ledger
+}
+
+type AdjustStockRequest struct {
+	// VariantID adjusts the stock of a variant instead of the product
+	VariantID string `json:"variant_id"`
+	Delta     int32  `json:"delta" binding:"required"`
+	Reason    string `json:"reason"`
+	Note      string `json:"note"`
+}
+
+type ReserveStockItem struct {
+	ProductID string `json:"product_id" binding:"required"`
+	VariantID string `json:"variant_id"`
+	Quantity  int32  `json:"quantity" binding:"required,gt=0"`
+}
+
+type ReserveStockRequest struct {
+	Items []ReserveStockItem `json:"items" binding:"required,min=1,dive"`
+	TTL   time.Duration      `json:"-"`
+	// ID of the reservation, chosen by callers that retry the request
+	ID string `json:"-"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Product lifecycle states
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// ErrInvalidTransition is returned when a product cannot move from its
+// current status to the requested one
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// statusTransitions lists the states each state may move to. Archived
+// products go back to draft before they are published again.
+var statusTransitions = map[string][]string{
+	StatusDraft:     {StatusPublished, StatusArchived},
+	StatusPublished: {StatusDraft, StatusArchived},
+	StatusArchived:  {StatusDraft},
+}
+
+// ValidStatus reports whether status is a lifecycle state
+func ValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// CanTransition reports whether a product may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentStatus returns the lifecycle state of the product. Products stored
+// before lifecycle states existed have none and count as published.
+func (p *Product) CurrentStatus() string {
+	if p.Status == "" {
+		return StatusPublished
+	}
+	return p.Status
+}
+
+// IsPublished reports whether the product is visible in public listings
+func (p *Product) IsPublished() bool {
+	return p.CurrentStatus() == StatusPublished
+}
+
+// DueTransition returns the status a scheduled publish or unpublish moves
+// the product to at now, or an empty string when nothing is due
+func (p *Product) DueTransition(now time.Time) string {
+	switch status := p.CurrentStatus(); {
+	case status == StatusDraft && p.PublishAt != nil && !p.PublishAt.After(now):
+		return StatusPublished
+	case status == StatusPublished && p.UnpublishAt != nil && !p.UnpublishAt.After(now):
+		return StatusArchived
+	}
+	return ""
+}
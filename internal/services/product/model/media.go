@@ -0,0 +1,46 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Thumbnail states of a media item
+const (
+	ThumbnailPending = "pending"
+	ThumbnailReady   = "ready"
+	ThumbnailFailed  = "failed"
+)
+
+// ErrMediaNotFound is returned when a product has no media item with an ID
+var ErrMediaNotFound = errors.New("media not found")
+
+// Media is an image attached to a product. The file itself lives in the blob
+// store under Key, the thumbnail is generated in the background.
+type Media struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Key         string             `bson:"key" json:"-"`
+	URL         string             `bson:"url" json:"url"`
+	ContentType string             `bson:"content_type" json:"content_type"`
+	Size        int64              `bson:"size" json:"size"`
+	Width       int32              `bson:"width" json:"width"`
+	Height      int32              `bson:"height" json:"height"`
+	// Checksum is the hex encoded SHA-256 of the file
+	Checksum        string    `bson:"checksum" json:"checksum"`
+	ThumbnailKey    string    `bson:"thumbnail_key,omitempty" json:"-"`
+	ThumbnailURL    string    `bson:"thumbnail_url,omitempty" json:"thumbnail_url,omitempty"`
+	ThumbnailStatus string    `bson:"thumbnail_status" json:"thumbnail_status"`
+	CreatedAt       time.Time `bson:"created_at" json:"created_at"`
+}
+
+// MediaItem returns the media item with the given ID, or nil
+func (p *Product) MediaItem(id primitive.ObjectID) *Media {
+	for i := range p.Media {
+		if p.Media[i].ID == id {
+			return &p.Media[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// PriceListRetail is the price list served when a caller does not name one.
+// The base price of a product is its retail price in its own currency.
+const PriceListRetail = "retail"
+
+// ListPrice is the price of a product in one price list and currency, e.g.
+// EUR retail or USD wholesale
+type ListPrice struct {
+	List  string      `bson:"list" json:"list" binding:"required"`
+	Price money.Money `bson:"price" json:"price" binding:"required"`
+}
+
+// PriceQuery asks for product prices from a price list in a given currency
+type PriceQuery struct {
+	List     string
+	Currency string
+}
+
+// DisplayPrice is the price of a product resolved for a PriceQuery. When no
+// price is stored in the requested currency it is converted from Source,
+// and Rate and RateTimestamp record the exchange rate that was applied.
+type DisplayPrice struct {
+	List          string      `json:"list"`
+	Price         money.Money `json:"price"`
+	Source        money.Money `json:"source"`
+	Converted     bool        `json:"converted"`
+	Rate          float64     `json:"rate"`
+	RateTimestamp *time.Time  `json:"rate_timestamp,omitempty"`
+}
+
+// ListPrices returns the prices of a product in the given list, starting
+// with the base price for the retail list
+func (p *Product) ListPrices(list string) []money.Money {
+	var prices []money.Money
+	if list == PriceListRetail {
+		prices = append(prices, p.Price)
+	}
+	for _, price := range p.PriceLists {
+		if price.List == list {
+			prices = append(prices, price.Price)
+		}
+	}
+	return prices
+}
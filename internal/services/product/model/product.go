@@ -3,33 +3,156 @@ package model
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"time"
+
+	"go-microservice-boilerplate/internal/utils/pagination"
+	"go-microservice-boilerplate/pkg/money"
 )
 
 type Product struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Name        string             `bson:"name" json:"name" binding:"required"`
 	Description string             `bson:"description" json:"description"`
-	Price       float64            `bson:"price" json:"price" binding:"required,gt=0"`
+	Price       money.Money        `bson:"price" json:"price" binding:"required"`
 	Quantity    int32              `bson:"quantity" json:"quantity" binding:"required,gte=0"`
 	Category    string             `bson:"category" json:"category" binding:"required"`
 	SKU         string             `bson:"sku" json:"sku" binding:"required"`
 	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	Score       float64            `bson:"score,omitempty" json:"score,omitempty"`
+	// ReorderThreshold raises a low-stock alert once the quantity drops
+	// below it, zero disables alerts
+	ReorderThreshold int32 `bson:"reorder_threshold" json:"reorder_threshold"`
+	// PriceLists holds prices beyond the base price, such as EUR retail or
+	// wholesale
+	PriceLists []ListPrice `bson:"price_lists,omitempty" json:"price_lists,omitempty"`
+	// DisplayPrice is only set when a caller asks for a price list or currency
+	DisplayPrice *DisplayPrice `bson:"-" json:"display_price,omitempty"`
+	Variants     []Variant     `bson:"variants,omitempty" json:"variants,omitempty"`
+	// Attributes holds values typed by the attribute schema of the category
+	Attributes map[string]interface{} `bson:"attributes,omitempty" json:"attributes,omitempty"`
+	// Status is the lifecycle state, only published products are public
+	Status      string     `bson:"status,omitempty" json:"status"`
+	PublishedAt *time.Time `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	// PublishAt and UnpublishAt schedule a draft to be published and a
+	// published product to be archived
+	PublishAt   *time.Time `bson:"publish_at,omitempty" json:"publish_at,omitempty"`
+	UnpublishAt *time.Time `bson:"unpublish_at,omitempty" json:"unpublish_at,omitempty"`
+	Media       []Media    `bson:"media,omitempty" json:"media,omitempty"`
+}
+
+// IsLowStock reports whether the product has fallen below its reorder threshold
+func (p *Product) IsLowStock() bool {
+	return p.Quantity < p.ReorderThreshold
 }
 
 type CreateProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Quantity    int32   `json:"quantity" binding:"required,gte=0"`
-	Category    string  `json:"category" binding:"required"`
-	SKU         string  `json:"sku" binding:"required"`
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price" binding:"required"`
+	Quantity    int32       `json:"quantity" binding:"required,gte=0"`
+	Category    string      `json:"category" binding:"required"`
+	SKU         string      `json:"sku" binding:"required"`
+
+	ReorderThreshold int32                  `json:"reorder_threshold" binding:"gte=0"`
+	PriceLists       []ListPrice            `json:"price_lists" binding:"omitempty,dive"`
+	Variants         []Variant              `json:"variants" binding:"omitempty,dive"`
+	Attributes       map[string]interface{} `json:"attributes"`
+	// Status is draft, the default, or published
+	Status string `json:"status"`
 }
 
 type UpdateProductRequest struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"omitempty,gt=0"`
-	Quantity    int32   `json:"quantity" binding:"omitempty,gte=0"`
-	Category    string  `json:"category"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Price       *money.Money `json:"price"`
+	Quantity    *int32       `json:"quantity" binding:"omitempty,gte=0"`
+	Category    string       `json:"category"`
+
+	ReorderThreshold *int32 `json:"reorder_threshold" binding:"omitempty,gte=0"`
+	// PriceLists replaces every price list when set, an empty list clears them
+	PriceLists []ListPrice `json:"price_lists" binding:"omitempty,dive"`
+	// Variants replaces the variants when set, an empty list removes them.
+	// Existing variants are matched by ID or SKU and keep their stock.
+	Variants []Variant `json:"variants" binding:"omitempty,dive"`
+	// Attributes replaces the attributes when set, an empty map clears them
+	Attributes map[string]interface{} `json:"attributes"`
+	// Status moves the product to another lifecycle state right away
+	Status string `json:"status"`
+	// Fields lists the fields to write, taking their values as given so an
+	// empty description clears it. attributes.<name> writes one attribute,
+	// removing it when Attributes has no value for it. Without fields only
+	// the values that are set are written.
+	Fields []string `json:"-"`
+}
+
+// UpdatableFields are the product fields an update mask can name, besides
+// attributes.<name>
+var UpdatableFields = []string{
+	"name", "description", "price", "quantity", "category", "reorder_threshold",
+	"price_lists", "variants", "attributes", "status",
+}
+
+type GetProductParams struct {
+	// Pricing, when set, resolves the display price
+	Pricing *PriceQuery
+	// IncludeUnpublished returns drafts and archived products too
+	IncludeUnpublished bool
+}
+
+type ListProductsParams struct {
+	Page          int
+	Limit         int
+	PageToken     string
+	TotalMode     pagination.TotalMode
+	Search        string
+	Category      string
+	SortBy        string
+	SortOrder     string
+	MinPrice      *int64
+	MaxPrice      *int64
+	Currency      string
+	MinQuantity   *int32
+	MaxQuantity   *int32
+	InStock       bool
+	Categories    []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// CategoryTree matches a category path and all of its subcategories
+	CategoryTree string
+	Attributes   []AttributeFilter
+	// Pricing, when set, resolves a display price for every product
+	Pricing *PriceQuery
+	// Status limits the listing to one lifecycle state
+	Status string
+	// IncludeUnpublished lists drafts and archived products too, public
+	// listings only show published ones
+	IncludeUnpublished bool
+}
+
+// ProductSortFields maps the sort keys accepted by ListProducts to document fields.
+// Relevance sorts by text search score and is only valid alongside a search.
+// Price only lists products priced in the currency of the listing.
+var ProductSortFields = map[string]string{
+	"relevance":  "score",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"name":       "name",
+	"price":      "price.amount",
+	"quantity":   "quantity",
+}
+
+// SortValue returns the value of the given sort key, used to build page cursors
+func (p *Product) SortValue(sortBy string) interface{} {
+	switch sortBy {
+	case "updated_at":
+		return p.UpdatedAt
+	case "name":
+		return p.Name
+	case "price":
+		return p.Price.Amount
+	case "quantity":
+		return p.Quantity
+	default:
+		return p.CreatedAt
+	}
 }
@@ -0,0 +1,52 @@
+package model
+
+// DefaultPriceBoundaries are the lower bounds, in minor units, of the price
+// buckets used when a search does not specify its own
+var DefaultPriceBoundaries = []int64{0, 2500, 5000, 10000, 25000, 50000, 100000}
+
+type SearchProductsParams struct {
+	Query           string
+	Page            int
+	Limit           int
+	SortBy          string
+	SortOrder       string
+	Categories      []string
+	MinPrice        *int64
+	MaxPrice        *int64
+	Currency        string
+	InStock         bool
+	PriceBoundaries []int64
+	// IncludeUnpublished searches drafts and archived products too
+	IncludeUnpublished bool
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type PriceBucket struct {
+	Min   int64  `json:"min"`
+	Max   *int64 `json:"max,omitempty"`
+	Count int64  `json:"count"`
+}
+
+type SearchFacets struct {
+	Categories   []FacetCount  `json:"categories"`
+	PriceBuckets []PriceBucket `json:"price_buckets"`
+	InStock      int64         `json:"in_stock"`
+	OutOfStock   int64         `json:"out_of_stock"`
+}
+
+type SearchResult struct {
+	Products []*Product   `json:"products"`
+	Total    int64        `json:"total"`
+	Facets   SearchFacets `json:"facets"`
+}
+
+// Suggestion is a typeahead entry for the storefront search box
+type Suggestion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	SKU  string `json:"sku"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/pkg/money"
+)
+
+var (
+	// ErrVariantNotFound is returned when a stock change targets a variant
+	// the product does not have
+	ErrVariantNotFound = errors.New("variant not found")
+	// ErrProductChanged is returned when the variants of a product changed
+	// between reading and replacing them
+	ErrProductChanged = errors.New("product was changed concurrently")
+)
+
+// Variant is a purchasable version of a product, such as a size and color,
+// with its own SKU and stock. A nil Price sells the variant at the product price.
+type Variant struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	SKU      string             `bson:"sku" json:"sku" binding:"required"`
+	Options  map[string]string  `bson:"options,omitempty" json:"options,omitempty"`
+	Price    *money.Money       `bson:"price,omitempty" json:"price,omitempty"`
+	Quantity int32              `bson:"quantity" json:"quantity" binding:"gte=0"`
+}
+
+// Variant returns the variant with the given ID, or nil
+func (p *Product) Variant(id primitive.ObjectID) *Variant {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// StockOf returns the quantity of a variant, or of the product itself when
+// variantID is nil
+func (p *Product) StockOf(variantID *primitive.ObjectID) int32 {
+	if variantID == nil {
+		return p.Quantity
+	}
+	if variant := p.Variant(*variantID); variant != nil {
+		return variant.Quantity
+	}
+	return 0
+}
+
+// SKUs returns the SKU of the product followed by those of its variants
+func (p *Product) SKUs() []string {
+	skus := []string{p.SKU}
+	for _, variant := range p.Variants {
+		skus = append(skus, variant.SKU)
+	}
+	return skus
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Product change types
+const (
+	// ChangeWatching opens every watch, carrying the resume token of the
+	// moment it started
+	ChangeWatching = "watching"
+	ChangeCreated  = "created"
+	ChangeUpdated  = "updated"
+	// ChangeDeleted is also sent when a product stops matching the filters
+	// of a watch, such as when it is unpublished or moved to another category
+	ChangeDeleted = "deleted"
+)
+
+// MaxWatchedProducts bounds how many product IDs one watch filters by
+const MaxWatchedProducts = 100
+
+var (
+	// ErrInvalidResumeToken is returned for resume tokens that cannot be
+	// decoded, or that are too old to resume from
+	ErrInvalidResumeToken = errors.New("invalid or expired resume token")
+	// ErrWatchUnsupported is returned when the database cannot stream
+	// changes, as standalone MongoDB servers cannot
+	ErrWatchUnsupported = errors.New("watching products needs MongoDB to run as a replica set")
+	// ErrWatchFiltersUnsupported is returned for watches filtering products
+	// by their fields when the database keeps no pre-images, without which
+	// deletions cannot be filtered
+	ErrWatchFiltersUnsupported = errors.New("watching products by category or status needs MongoDB 6.0 with pre-images of products enabled")
+)
+
+type WatchProductsParams struct {
+	ProductIDs []string
+	// Category and CategoryTree filter like they do when listing products
+	Category     string
+	CategoryTree string
+	// IncludeUnpublished streams changes of drafts and archived products
+	IncludeUnpublished bool
+	// ResumeToken continues a watch after the change that carried it
+	ResumeToken string
+}
+
+// FiltersDocuments reports whether the watch filters products by their
+// fields, which deletions only carry in their pre-image. Product IDs are
+// filtered by the key every change carries.
+func (p *WatchProductsParams) FiltersDocuments() bool {
+	return p.Category != "" || p.CategoryTree != "" || !p.IncludeUnpublished
+}
+
+// Matches reports whether product passes the filters of the watch
+func (p *WatchProductsParams) Matches(product *Product) bool {
+	if len(p.ProductIDs) > 0 {
+		watched := false
+		for _, id := range p.ProductIDs {
+			if id == product.ID.Hex() {
+				watched = true
+				break
+			}
+		}
+		if !watched {
+			return false
+		}
+	}
+	if p.Category != "" && product.Category != p.Category {
+		return false
+	}
+	if p.CategoryTree != "" && product.Category != p.CategoryTree &&
+		!strings.HasPrefix(product.Category, p.CategoryTree+CategoryPathSeparator) {
+		return false
+	}
+	return p.IncludeUnpublished || product.IsPublished()
+}
+
+// ProductChange is a change of a product streamed to watchers
+type ProductChange struct {
+	Type      string
+	ProductID string
+	// Product is the product after the change, nil for deletions
+	Product *Product
+	// Previous is the product before an update or deletion, when the
+	// database kept it. It is not sent to watchers.
+	Previous *Product
+	// ChangedFields lists the top-level fields an update changed, such as
+	// quantity or price
+	ChangedFields []string
+	// ResumeToken resumes a watch right after this change
+	ResumeToken string
+	OccurredAt  time.Time
+}
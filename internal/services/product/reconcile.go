@@ -0,0 +1,60 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/product/repository"
+)
+
+// ReconcileStock recomputes every product quantity from the stock ledger and
+// writes a report of the products whose stored quantity drifted from it.
+// It returns the number of drifted products.
+func ReconcileStock(ctx context.Context, mongodb *database.MongoDB, out io.Writer) (int, error) {
+	inventoryRepo := repository.NewMongoInventoryRepository(mongodb)
+
+	drifted, err := inventoryRepo.Reconcile(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reconcile stock: %w", err)
+	}
+
+	if len(drifted) == 0 {
+		fmt.Fprintln(out, "All product quantities match the stock ledger")
+		return 0, nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PRODUCT ID\tVARIANT ID\tSKU\tNAME\tQUANTITY\tLEDGER\tENTRIES\tDRIFT")
+	for _, d := range drifted {
+		variantID := "-"
+		if d.VariantID != nil {
+			variantID = d.VariantID.Hex()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%+d\n", d.ProductID.Hex(), variantID, d.SKU, d.Name, d.Quantity, d.Ledger, d.Entries, d.Drift())
+	}
+	if err := w.Flush(); err != nil {
+		return len(drifted), err
+	}
+
+	fmt.Fprintf(out, "%d product(s) drifted from the stock ledger\n", len(drifted))
+	return len(drifted), nil
+}
+
+// BackfillLedger opens the stock ledger of products and variants that have
+// no entry yet, such as those created before the ledger existed, with their
+// current quantity as the opening balance. It is safe to run repeatedly and
+// returns the number of ledgers opened.
+func BackfillLedger(ctx context.Context, mongodb *database.MongoDB, out io.Writer) (int, error) {
+	inventoryRepo := repository.NewMongoInventoryRepository(mongodb)
+
+	opened, err := inventoryRepo.OpenMissingLedgers(ctx)
+	if err != nil {
+		return opened, fmt.Errorf("failed to backfill the stock ledger: %w", err)
+	}
+
+	fmt.Fprintf(out, "Opened the stock ledger of %d product(s) and variant(s)\n", opened)
+	return opened, nil
+}
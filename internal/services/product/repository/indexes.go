@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/product/model"
+)
+
+// EnsureIndexes creates the indexes the product repositories rely on for
+// correctness rather than speed. deployments/mongodb/init-mongo.js creates
+// them too, but only runs on an empty data directory.
+func EnsureIndexes(ctx context.Context, db *database.MongoDB) error {
+	// SKUs of variants are unique across products
+	err := db.EnsureIndexes(ctx, "products", mongo.IndexModel{
+		Keys:    bson.D{{Key: "variants.sku", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"variants.sku": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// A single open low-stock alert per product
+	return db.EnsureIndexes(ctx, "stock_alerts", mongo.IndexModel{
+		Keys:    bson.D{{Key: "product_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": model.AlertOpen}),
+	})
+}
@@ -2,7 +2,12 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
 	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
 )
 
 // ProductRepository defines the contract for product data operations
@@ -10,10 +15,106 @@ type ProductRepository interface {
 	// Basic CRUD operations
 	Create(ctx context.Context, product *model.Product) error
 	GetByID(ctx context.Context, id string) (*model.Product, error)
+	// GetByIDs returns the products with any of the given IDs, in no order
+	GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error)
 	GetBySKU(ctx context.Context, sku string) (*model.Product, error)
-	Update(ctx context.Context, id string, product *model.Product) error
+	// GetBySKUs returns the products with any of the given SKUs
+	GetBySKUs(ctx context.Context, skus []string) ([]*model.Product, error)
+	// UpsertBySKU creates or updates products matched by SKU in one bulk
+	// write. Stock, status and creation time are only written for new
+	// products. It returns which products were inserted and the write error
+	// of every product that failed, both keyed by index.
+	UpsertBySKU(ctx context.Context, products []*model.Product) (map[int]bool, map[int]error, error)
+	// Update writes the given fields of product, removing the empty ones.
+	// attributes.<name> writes a single attribute.
+	Update(ctx context.Context, id string, product *model.Product, fields []string) error
+	// ReplaceVariants swaps the variants of a product, failing with
+	// ErrProductChanged unless they still equal current
+	ReplaceVariants(ctx context.Context, id primitive.ObjectID, current, variants []model.Variant) error
+	// SKUsInUse returns which of skus are taken by products or variants,
+	// ignoring the product exclude
+	SKUsInUse(ctx context.Context, skus []string, exclude *primitive.ObjectID) ([]string, error)
+	// UpdateStatus stores the lifecycle fields of a product, failing with
+	// ErrProductChanged unless its status is still from
+	UpdateStatus(ctx context.Context, product *model.Product, from string) error
+	// ListDueTransitions returns products whose scheduled publish or
+	// unpublish time has passed
+	ListDueTransitions(ctx context.Context, now time.Time, limit int) ([]*model.Product, error)
+	// AddMedia appends a media item to a product and returns the updated
+	// product, or nil when it does not exist
+	AddMedia(ctx context.Context, id primitive.ObjectID, media *model.Media) (*model.Product, error)
+	// RemoveMedia removes a media item from a product and returns the
+	// updated product, or nil when either does not exist
+	RemoveMedia(ctx context.Context, id, mediaID primitive.ObjectID) (*model.Product, error)
+	// ListPendingThumbnails returns products with media still waiting for
+	// a thumbnail
+	ListPendingThumbnails(ctx context.Context, limit int) ([]*model.Product, error)
+	// SetThumbnail stores the thumbnail and dimensions of a media item
+	SetThumbnail(ctx context.Context, id primitive.ObjectID, media *model.Media) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, page, limit int, search, category string) ([]*model.Product, int64, error)
+	List(ctx context.Context, params *model.ListProductsParams) ([]*model.Product, *pagination.PageInfo, error)
+	// Export calls fn for every product matching the list filters, in list
+	// order, reading them from a cursor one at a time. Paging parameters
+	// are ignored.
+	Export(ctx context.Context, params *model.ListProductsParams, fn func(*model.Product) error) error
+	// Watch calls fn with every change of the products matching params
+	// until ctx is cancelled, starting with a ChangeWatching change that
+	// carries the resume token of the moment watching began
+	Watch(ctx context.Context, params *model.WatchProductsParams, fn func(*model.ProductChange) error) error
+	Search(ctx context.Context, params *model.SearchProductsParams) (*model.SearchResult, error)
+	SuggestByPrefix(ctx context.Context, prefix string, limit int) ([]*model.Product, error)
+	ListLowStock(ctx context.Context, page, limit int) ([]*model.Product, int64, error)
+}
+
+// InventoryRepository defines the contract for atomic stock operations.
+// Every change to a product quantity is appended to the stock ledger in the
+// transaction of the change, joining the transaction of ctx when it has one.
+type InventoryRepository interface {
+	OpenLedger(ctx context.Context, product *model.Product) error
+	OpenVariantLedgers(ctx context.Context, productID primitive.ObjectID, variants []model.Variant) error
+	// OpenMissingLedgers records the current stock as the opening balance of
+	// products and variants without any ledger entry, such as those created
+	// before the ledger existed, and returns how many it opened
+	OpenMissingLedgers(ctx context.Context) (int, error)
+	// AdjustStock changes the stock of a product, or of one of its variants
+	// when variantID is set
+	AdjustStock(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID, delta int32, reason, note string) (*model.Product, error)
+	SetStock(ctx context.Context, productID primitive.ObjectID, quantity int32, note string) (*model.Product, error)
+	// Reserve takes the stock of the reservation and stores it. A
+	// reservation whose ID is already stored is loaded instead, without
+	// taking stock again.
+	Reserve(ctx context.Context, reservation *model.Reservation) ([]*model.Product, error)
+	Release(ctx context.Context, id primitive.ObjectID) (*model.Reservation, []*model.Product, error)
+	Commit(ctx context.Context, id primitive.ObjectID) (*model.Reservation, error)
+	// ListExpired returns the IDs of up to limit active reservations that
+	// expired by now, in ID order after the ID after
+	ListExpired(ctx context.Context, now time.Time, after primitive.ObjectID, limit int) ([]primitive.ObjectID, error)
+	ListMovements(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID, params *model.ListStockMovementsParams) ([]*model.StockMovement, string, error)
+	Reconcile(ctx context.Context) ([]*model.StockDrift, error)
+
+	// OpenAlert records a low-stock alert and reports false when the product
+	// already has an open one
+	OpenAlert(ctx context.Context, alert *model.StockAlert) (bool, error)
+	// ResolveAlert closes the open alert of a product, returning nil when
+	// there was none
+	ResolveAlert(ctx context.Context, productID primitive.ObjectID, quantity int32) (*model.StockAlert, error)
+}
+
+// CategoryRepository defines the contract for the category tree
+type CategoryRepository interface {
+	Create(ctx context.Context, category *model.Category) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*model.Category, error)
+	GetByPath(ctx context.Context, path string) (*model.Category, error)
+	// GetByPaths returns the categories with the given paths, in no order
+	GetByPaths(ctx context.Context, paths []string) ([]*model.Category, error)
+	// Update saves a category and, when its path changed from oldPath,
+	// rewrites the paths of its descendants and the categories of its products
+	Update(ctx context.Context, category *model.Category, oldPath string) error
+	// Delete removes a category that has no subcategories and no products
+	Delete(ctx context.Context, category *model.Category) error
+	// List returns the children of parent, or the top level when parent is
+	// nil, including every descendant when recursive
+	List(ctx context.Context, parent *model.Category, recursive bool) ([]*model.Category, error)
 }
 
 // ProductCache defines the contract for product caching operations
@@ -21,7 +122,16 @@ type ProductCache interface {
 	Set(ctx context.Context, key string, product *model.Product, expiration int) error
 	Get(ctx context.Context, key string) (*model.Product, error)
 	Delete(ctx context.Context, key string) error
+	GetMany(ctx context.Context, keys []string) ([]*model.Product, error)
+	SetMany(ctx context.Context, products map[string]*model.Product, expiration int) error
 	SetList(ctx context.Context, key string, products []*model.Product, expiration int) error
 	GetList(ctx context.Context, key string) ([]*model.Product, error)
 	InvalidatePattern(ctx context.Context, pattern string) error
 }
+
+// ProductSuggestIndex defines the contract for the typeahead prefix index
+type ProductSuggestIndex interface {
+	Index(ctx context.Context, product *model.Product) error
+	Remove(ctx context.Context, id string) error
+	Suggest(ctx context.Context, prefix string, limit int) ([]*model.Suggestion, error)
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/product/model"
+)
+
+type mongoCategoryRepository struct {
+	categories *mongo.Collection
+	products   *mongo.Collection
+}
+
+// NewMongoCategoryRepository creates a MongoDB backed category repository.
+// Categories keep a materialized path that products reference, so moving or
+// renaming a category rewrites the paths of its subtree and its products.
+func NewMongoCategoryRepository(db *database.MongoDB) CategoryRepository {
+	return &mongoCategoryRepository{
+		categories: db.Collection("categories"),
+		products:   db.Collection("products"),
+	}
+}
+
+func (r *mongoCategoryRepository) Create(ctx context.Context, category *model.Category) error {
+	now := time.Now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+
+	result, err := r.categories.InsertOne(ctx, category)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrCategoryExists
+	}
+	if err != nil {
+		return err
+	}
+
+	category.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoCategoryRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.Category, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoCategoryRepository) GetByPath(ctx context.Context, path string) (*model.Category, error) {
+	return r.findOne(ctx, bson.M{"path": path})
+}
+
+func (r *mongoCategoryRepository) GetByPaths(ctx context.Context, paths []string) ([]*model.Category, error) {
+	cursor, err := r.categories.Find(ctx, bson.M{"path": bson.M{"$in": paths}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var categories []*model.Category
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *mongoCategoryRepository) Update(ctx context.Context, category *model.Category, oldPath string) error {
+	category.UpdatedAt = time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"name":       category.Name,
+			"slug":       category.Slug,
+			"path":       category.Path,
+			"depth":      category.Depth,
+			"sort_order": category.SortOrder,
+			"attributes": category.Attributes,
+			"updated_at": category.UpdatedAt,
+		},
+	}
+	if category.ParentID != nil {
+		update["$set"].(bson.M)["parent_id"] = category.ParentID
+	} else {
+		update["$unset"] = bson.M{"parent_id": ""}
+	}
+
+	result, err := r.categories.UpdateOne(ctx, bson.M{"_id": category.ID}, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrCategoryExists
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrCategoryNotFound
+	}
+
+	if category.Path == oldPath {
+		return nil
+	}
+
+	// Swap the old path prefix for the new one across the subtree. The
+	// category itself was already moved, so only descendants match here.
+	descendants := bson.M{"path": bson.M{"$regex": subtreePattern(oldPath)}}
+	_, err = r.categories.UpdateMany(ctx, descendants, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"path":       rebasePath("$path", oldPath, category.Path),
+			"depth":      bson.M{"$add": bson.A{"$depth", category.Depth - int32(strings.Count(oldPath, model.CategoryPathSeparator))}},
+			"updated_at": category.UpdatedAt,
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.products.UpdateMany(ctx, categoryTreeFilter("category", oldPath), mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"category":   rebasePath("$category", oldPath, category.Path),
+			"updated_at": category.UpdatedAt,
+		}}},
+	})
+	return err
+}
+
+func (r *mongoCategoryRepository) Delete(ctx context.Context, category *model.Category) error {
+	children, err := r.categories.CountDocuments(ctx, bson.M{"parent_id": category.ID}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	products, err := r.products.CountDocuments(ctx, bson.M{"category": category.Path}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if children > 0 || products > 0 {
+		return model.ErrCategoryInUse
+	}
+
+	result, err := r.categories.DeleteOne(ctx, bson.M{"_id": category.ID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return model.ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (r *mongoCategoryRepository) List(ctx context.Context, parent *model.Category, recursive bool) ([]*model.Category, error) {
+	filter := bson.M{}
+	switch {
+	case parent != nil && recursive:
+		filter["path"] = bson.M{"$regex": subtreePattern(parent.Path)}
+	case parent != nil:
+		filter["parent_id"] = parent.ID
+	case !recursive:
+		filter["parent_id"] = bson.M{"$exists": false}
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "depth", Value: 1},
+		{Key: "sort_order", Value: 1},
+		{Key: "name", Value: 1},
+	})
+	cursor, err := r.categories.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []*model.Category{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *mongoCategoryRepository) findOne(ctx context.Context, filter bson.M) (*model.Category, error) {
+	var category model.Category
+	err := r.categories.FindOne(ctx, filter).Decode(&category)
+	if err == mongo.ErrNoDocuments {
+		return nil, model.ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// categoryTreeFilter matches a category path and every path below it. The
+// anchored prefix lets MongoDB answer it from an index on field.
+func categoryTreeFilter(field, path string) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{field: path},
+		bson.M{field: bson.M{"$regex": subtreePattern(path)}},
+	}}
+}
+
+// subtreePattern matches the paths strictly below path
+func subtreePattern(path string) string {
+	return "^" + regexp.QuoteMeta(path+model.CategoryPathSeparator)
+}
+
+// rebasePath replaces the leading oldPath of the path in field with newPath
+func rebasePath(field, oldPath, newPath string) bson.M {
+	return bson.M{"$concat": bson.A{newPath, bson.M{"$substrBytes": bson.A{field, len(oldPath), -1}}}}
+}
@@ -0,0 +1,658 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+// movementSortKey identifies the newest-first ordering of ledger page tokens
+const movementSortKey = "created_at:desc"
+
+type mongoInventoryRepository struct {
+	db           *database.MongoDB
+	products     *mongo.Collection
+	reservations *mongo.Collection
+	movements    *mongo.Collection
+	alerts       *mongo.Collection
+}
+
+// NewMongoInventoryRepository creates a MongoDB backed inventory repository.
+// Every stock change is a single guarded $inc on the product document, so
+// concurrent decrements can never take a quantity below zero.
+func NewMongoInventoryRepository(db *database.MongoDB) InventoryRepository {
+	return &mongoInventoryRepository{
+		db:           db,
+		products:     db.Collection("products"),
+		reservations: db.Collection("stock_reservations"),
+		movements:    db.Collection("stock_movements"),
+		alerts:       db.Collection("stock_alerts"),
+	}
+}
+
+func (r *mongoInventoryRepository) OpenLedger(ctx context.Context, product *model.Product) error {
+	return r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := r.recordMovement(ctx, &model.StockMovement{
+			ProductID: product.ID,
+			Delta:     product.Quantity,
+			Balance:   product.Quantity,
+			Reason:    model.ReasonInitial,
+		})
+		if err != nil {
+			return err
+		}
+		return r.OpenVariantLedgers(ctx, product.ID, product.Variants)
+	})
+}
+
+func (r *mongoInventoryRepository) OpenVariantLedgers(ctx context.Context, productID primitive.ObjectID, variants []model.Variant) error {
+	return r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, variant := range variants {
+			variantID := variant.ID
+			err := r.recordMovement(ctx, &model.StockMovement{
+				ProductID: productID,
+				VariantID: &variantID,
+				Delta:     variant.Quantity,
+				Balance:   variant.Quantity,
+				Reason:    model.ReasonInitial,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *mongoInventoryRepository) OpenMissingLedgers(ctx context.Context) (int, error) {
+	keys, err := r.ledgerTotals(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"quantity": 1, "variants": 1}).
+		SetSort(bson.D{{Key: "_id", Value: 1}})
+	products, err := r.products.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer products.Close(ctx)
+
+	opened := 0
+	for products.Next(ctx) {
+		var product model.Product
+		if err := products.Decode(&product); err != nil {
+			return opened, err
+		}
+
+		// The current quantity becomes the opening balance of ledgers that
+		// have no entry at all
+		var movements []*model.StockMovement
+		if _, ok := keys[ledgerKey{ProductID: product.ID}]; !ok {
+			movements = append(movements, &model.StockMovement{
+				ProductID: product.ID,
+				Delta:     product.Quantity,
+				Balance:   product.Quantity,
+				Reason:    model.ReasonInitial,
+				Note:      "opening balance",
+			})
+		}
+		for _, variant := range product.Variants {
+			variantID := variant.ID
+			if _, ok := keys[ledgerKey{ProductID: product.ID, VariantID: variantID}]; !ok {
+				movements = append(movements, &model.StockMovement{
+					ProductID: product.ID,
+					VariantID: &variantID,
+					Delta:     variant.Quantity,
+					Balance:   variant.Quantity,
+					Reason:    model.ReasonInitial,
+					Note:      "opening balance",
+				})
+			}
+		}
+
+		for _, movement := range movements {
+			if err := r.recordMovement(ctx, movement); err != nil {
+				return opened, err
+			}
+			opened++
+		}
+	}
+
+	return opened, products.Err()
+}
+
+func (r *mongoInventoryRepository) AdjustStock(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID, delta int32, reason, note string) (*model.Product, error) {
+	var product *model.Product
+	err := r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if product, err = r.incStock(ctx, productID, variantID, delta); err != nil {
+			return err
+		}
+
+		return r.recordMovement(ctx, &model.StockMovement{
+			ProductID: productID,
+			VariantID: variantID,
+			Delta:     delta,
+			Balance:   product.StockOf(variantID),
+			Reason:    reason,
+			Note:      note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (r *mongoInventoryRepository) SetStock(ctx context.Context, productID primitive.ObjectID, quantity int32, note string) (*model.Product, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	update := bson.M{"$set": bson.M{"quantity": quantity, "updated_at": time.Now()}}
+
+	var product model.Product
+	err := r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := r.products.FindOneAndUpdate(ctx, bson.M{"_id": productID}, update, opts).Decode(&product)
+		if err == mongo.ErrNoDocuments {
+			return model.ErrProductNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		delta := quantity - product.Quantity
+		if delta == 0 {
+			return nil
+		}
+		return r.recordMovement(ctx, &model.StockMovement{
+			ProductID: productID,
+			Delta:     delta,
+			Balance:   quantity,
+			Reason:    model.ReasonAdjustment,
+			Note:      note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	product.Quantity = quantity
+	return &product, nil
+}
+
+func (r *mongoInventoryRepository) Reserve(ctx context.Context, reservation *model.Reservation) ([]*model.Product, error) {
+	now := time.Now()
+	if reservation.ID.IsZero() {
+		reservation.ID = primitive.NewObjectID()
+	}
+	reservation.Status = model.ReservationActive
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+
+	// The stock and the reservation claiming it are written in one
+	// transaction, so stock is never taken without a reservation that
+	// hands it back when it expires
+	var products []*model.Product
+	err := r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		// A repeated request gets the reservation it already made; a
+		// concurrent one conflicts on the reservation and retries into this
+		err := r.reservations.FindOne(ctx, bson.M{"_id": reservation.ID}).Decode(reservation)
+		if err == nil {
+			products = nil
+			return nil
+		}
+		if err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		products = make([]*model.Product, 0, len(reservation.Items))
+		for _, item := range reservation.Items {
+			product, err := r.incStock(ctx, item.ProductID, item.VariantID, -item.Quantity)
+			if err != nil {
+				return fmt.Errorf("product %s: %w", item.ProductID.Hex(), err)
+			}
+			products = append(products, product)
+
+			err = r.recordMovement(ctx, &model.StockMovement{
+				ProductID:     item.ProductID,
+				VariantID:     item.VariantID,
+				Delta:         -item.Quantity,
+				Balance:       product.StockOf(item.VariantID),
+				Reason:        model.ReasonReservation,
+				ReservationID: &reservation.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = r.reservations.InsertOne(ctx, reservation)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *mongoInventoryRepository) Release(ctx context.Context, id primitive.ObjectID) (*model.Reservation, []*model.Product, error) {
+	return r.release(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoInventoryRepository) Commit(ctx context.Context, id primitive.ObjectID) (*model.Reservation, error) {
+	// The stock was already deducted when reserving, committing only stops
+	// the reservation from being released
+	reservation, _, err := r.close(ctx, bson.M{"_id": id, "expires_at": bson.M{"$gt": time.Now()}}, model.ReservationCommitted)
+	return reservation, err
+}
+
+func (r *mongoInventoryRepository) ListExpired(ctx context.Context, now time.Time, after primitive.ObjectID, limit int) ([]primitive.ObjectID, error) {
+	filter := bson.M{
+		"status":     model.ReservationActive,
+		"expires_at": bson.M{"$lte": now},
+		"_id":        bson.M{"$gt": after},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1})
+
+	cursor, err := r.reservations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var expired []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &expired); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(expired))
+	for i, reservation := range expired {
+		ids[i] = reservation.ID
+	}
+	return ids, nil
+}
+
+func (r *mongoInventoryRepository) ListMovements(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID, params *model.ListStockMovementsParams) ([]*model.StockMovement, string, error) {
+	filter := bson.M{"product_id": productID}
+	if variantID != nil {
+		filter["variant_id"] = *variantID
+	}
+	if params.Reason != "" {
+		filter["reason"] = params.Reason
+	}
+
+	createdAt := bson.M{}
+	if params.From != nil {
+		createdAt["$gte"] = *params.From
+	}
+	if params.To != nil {
+		createdAt["$lt"] = *params.To
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	if params.PageToken != "" {
+		cursor, err := pagination.DecodeCursor(params.PageToken, movementSortKey)
+		if err != nil {
+			return nil, "", err
+		}
+		filter = bson.M{"$and": []bson.M{filter, cursor.After("created_at", true)}}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(params.Limit + 1))
+
+	cursor, err := r.movements.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var movements []*model.StockMovement
+	if err := cursor.All(ctx, &movements); err != nil {
+		return nil, "", err
+	}
+
+	var nextPageToken string
+	if len(movements) > params.Limit {
+		movements = movements[:params.Limit]
+		last := movements[len(movements)-1]
+		if nextPageToken, err = pagination.EncodeCursor(movementSortKey, last.CreatedAt, last.ID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return movements, nextPageToken, nil
+}
+
+func (r *mongoInventoryRepository) Reconcile(ctx context.Context) ([]*model.StockDrift, error) {
+	ledger, err := r.ledgerTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"sku": 1, "name": 1, "quantity": 1, "variants": 1}).
+		SetSort(bson.D{{Key: "_id", Value: 1}})
+	products, err := r.products.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer products.Close(ctx)
+
+	var drifted []*model.StockDrift
+	for products.Next(ctx) {
+		var product model.Product
+		if err := products.Decode(&product); err != nil {
+			return nil, err
+		}
+
+		total := ledger[ledgerKey{ProductID: product.ID}]
+		drift := &model.StockDrift{
+			ProductID: product.ID,
+			SKU:       product.SKU,
+			Name:      product.Name,
+			Quantity:  product.Quantity,
+			Ledger:    total.Ledger,
+			Entries:   total.Entries,
+		}
+		if drift.Drift() != 0 {
+			drifted = append(drifted, drift)
+		}
+
+		for _, variant := range product.Variants {
+			variantID := variant.ID
+			total := ledger[ledgerKey{ProductID: product.ID, VariantID: variantID}]
+			drift := &model.StockDrift{
+				ProductID: product.ID,
+				VariantID: &variantID,
+				SKU:       variant.SKU,
+				Name:      product.Name,
+				Quantity:  variant.Quantity,
+				Ledger:    total.Ledger,
+				Entries:   total.Entries,
+			}
+			if drift.Drift() != 0 {
+				drifted = append(drifted, drift)
+			}
+		}
+	}
+
+	return drifted, products.Err()
+}
+
+func (r *mongoInventoryRepository) OpenAlert(ctx context.Context, alert *model.StockAlert) (bool, error) {
+	alert.ID = primitive.NewObjectID()
+	alert.Status = model.AlertOpen
+	alert.CreatedAt = time.Now()
+
+	// A unique partial index allows a single open alert per product, so a
+	// duplicate key means the product is already being reported
+	if _, err := r.alerts.InsertOne(ctx, alert); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mongoInventoryRepository) ResolveAlert(ctx context.Context, productID primitive.ObjectID, quantity int32) (*model.StockAlert, error) {
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"status":            model.AlertResolved,
+		"resolved_quantity": quantity,
+		"resolved_at":       now,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var alert model.StockAlert
+	err := r.alerts.FindOneAndUpdate(ctx, bson.M{"product_id": productID, "status": model.AlertOpen}, update, opts).Decode(&alert)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &alert, nil
+}
+
+// incStock atomically applies delta to the quantity of a product, or of one
+// of its variants when variantID is set. Decrements only match while enough
+// stock is left.
+func (r *mongoInventoryRepository) incStock(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID, delta int32) (*model.Product, error) {
+	filter := bson.M{"_id": productID}
+	field := "quantity"
+	if variantID != nil {
+		variant := bson.M{"_id": *variantID}
+		if delta < 0 {
+			variant["quantity"] = bson.M{"$gte": -delta}
+		}
+		filter["variants"] = bson.M{"$elemMatch": variant}
+		field = "variants.$.quantity"
+	} else if delta < 0 {
+		filter["quantity"] = bson.M{"$gte": -delta}
+	}
+
+	update := bson.M{
+		"$inc": bson.M{field: delta},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var product model.Product
+	err := r.products.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		// Tell a missing product or variant apart from one that ran out of stock
+		count, countErr := r.products.CountDocuments(ctx, bson.M{"_id": productID})
+		if countErr != nil {
+			return nil, countErr
+		}
+		if count == 0 {
+			return nil, model.ErrProductNotFound
+		}
+		if variantID != nil {
+			count, countErr = r.products.CountDocuments(ctx, bson.M{"_id": productID, "variants._id": *variantID})
+			if countErr != nil {
+				return nil, countErr
+			}
+			if count == 0 {
+				return nil, model.ErrVariantNotFound
+			}
+		}
+		return nil, model.ErrInsufficientStock
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// close moves an active reservation matching filter to status, reporting
+// whether this call did. The status guard makes sure only one of release,
+// commit and expiry can ever win.
+func (r *mongoInventoryRepository) close(ctx context.Context, filter bson.M, status string) (*model.Reservation, bool, error) {
+	filter["status"] = model.ReservationActive
+	update := bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var reservation model.Reservation
+	err := r.reservations.FindOneAndUpdate(ctx, filter, update, opts).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		id, ok := filter["_id"]
+		if !ok {
+			return nil, false, model.ErrReservationClosed
+		}
+
+		err = r.reservations.FindOne(ctx, bson.M{"_id": id}).Decode(&reservation)
+		if err == mongo.ErrNoDocuments {
+			return nil, false, model.ErrReservationNotFound
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		// Closing a reservation the same way twice succeeds, so callers can
+		// retry after losing the first answer
+		if reservation.Status == status {
+			return &reservation, false, nil
+		}
+		return nil, false, model.ErrReservationClosed
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &reservation, true, nil
+}
+
+// release closes an active reservation matching filter and hands its stock
+// back in the same transaction, so released stock is never lost or returned
+// twice
+func (r *mongoInventoryRepository) release(ctx context.Context, filter bson.M) (*model.Reservation, []*model.Product, error) {
+	var reservation *model.Reservation
+	var products []*model.Product
+	err := r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		var closed bool
+		var err error
+		reservation, closed, err = r.close(ctx, filter, model.ReservationReleased)
+		if err != nil {
+			return err
+		}
+		// Released before, the stock is back already
+		if !closed {
+			products = nil
+			return nil
+		}
+
+		var unreturned []model.StockItem
+		products, unreturned, err = r.restock(ctx, reservation.Items, &reservation.ID)
+		if err != nil || len(unreturned) == 0 {
+			return err
+		}
+
+		// Kept on the reservation, as there is no ledger to hand them back to
+		reservation.Unreturned = unreturned
+		_, err = r.reservations.UpdateOne(ctx,
+			bson.M{"_id": reservation.ID},
+			bson.M{"$set": bson.M{"unreturned": unreturned}},
+		)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reservation, products, nil
+}
+
+// restock hands the items of a reservation back to their products and
+// returns the products it updated, and the items whose product or variant
+// no longer exists
+func (r *mongoInventoryRepository) restock(ctx context.Context, items []model.StockItem, reservationID *primitive.ObjectID) ([]*model.Product, []model.StockItem, error) {
+	products := make([]*model.Product, 0, len(items))
+	var unreturned []model.StockItem
+	for _, item := range items {
+		product, err := r.incStock(ctx, item.ProductID, item.VariantID, item.Quantity)
+		if errors.Is(err, model.ErrProductNotFound) || errors.Is(err, model.ErrVariantNotFound) {
+			unreturned = append(unreturned, item)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to restock %d of product %s: %w", item.Quantity, item.ProductID.Hex(), err)
+		}
+
+		err = r.recordMovement(ctx, &model.StockMovement{
+			ProductID:     item.ProductID,
+			VariantID:     item.VariantID,
+			Delta:         item.Quantity,
+			Balance:       product.StockOf(item.VariantID),
+			Reason:        model.ReasonRelease,
+			ReservationID: reservationID,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, unreturned, nil
+}
+
+// ledgerKey identifies the ledger of a product, or of one of its variants.
+// Product level entries have no variant_id and group under a zero variant.
+type ledgerKey struct {
+	ProductID primitive.ObjectID `bson:"product_id"`
+	VariantID primitive.ObjectID `bson:"variant_id,omitempty"`
+}
+
+// ledgerTotal sums the entries of one ledger
+type ledgerTotal struct {
+	Key     ledgerKey `bson:"_id"`
+	Ledger  int64     `bson:"ledger"`
+	Entries int64     `bson:"entries"`
+}
+
+// ledgerTotals sums the stock ledger of every product and variant
+func (r *mongoInventoryRepository) ledgerTotals(ctx context.Context) (map[ledgerKey]ledgerTotal, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":     bson.M{"product_id": "$product_id", "variant_id": "$variant_id"},
+			"ledger":  bson.M{"$sum": "$delta"},
+			"entries": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.movements.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var totals []ledgerTotal
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+
+	ledger := make(map[ledgerKey]ledgerTotal, len(totals))
+	for _, total := range totals {
+		ledger[total.Key] = total
+	}
+	return ledger, nil
+}
+
+// recordMovement appends an entry to the stock ledger, stamped with the actor
+// and correlation ID of the request. Callers write it in the transaction of
+// the stock change it records, so neither is stored without the other.
+func (r *mongoInventoryRepository) recordMovement(ctx context.Context, movement *model.StockMovement) error {
+	movement.ID = primitive.NewObjectID()
+	movement.Actor = requestmeta.Actor(ctx)
+	movement.CorrelationID = requestmeta.CorrelationID(ctx)
+	movement.CreatedAt = time.Now()
+
+	if _, err := r.movements.InsertOne(ctx, movement); err != nil {
+		return fmt.Errorf("failed to record stock movement for product %s: %w", movement.ProductID.Hex(), err)
+	}
+	return nil
+}
@@ -2,6 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,8 +17,19 @@ import (
 
 	"go-microservice-boilerplate/internal/database"
 	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
 )
 
+// textScore projects or sorts by the relevance of a $text match
+var textScore = bson.M{"$meta": "textScore"}
+
+// inStockExpr is true for products with stock of their own or in any
+// variant. $max over a missing variants array is null, which is not > 0.
+var inStockExpr = bson.M{"$or": bson.A{
+	bson.M{"$gt": bson.A{"$quantity", 0}},
+	bson.M{"$gt": bson.A{bson.M{"$max": "$variants.quantity"}, 0}},
+}}
+
 type mongoProductRepository struct {
 	collection *mongo.Collection
 }
@@ -50,6 +67,43 @@ func (r *mongoProductRepository) GetByID(ctx context.Context, id string) (*model
 	return &product, nil
 }
 
+// emptyValue reports whether value is a zero value or an empty list or map
+func emptyValue(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func (r *mongoProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	objectIDs := make([]primitive.ObjectID, len(ids))
+	for i, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs[i] = objectID
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*model.Product
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
 func (r *mongoProductRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
 	var product model.Product
 	err := r.collection.FindOne(ctx, bson.M{"sku": sku}).Decode(&product)
@@ -63,28 +117,314 @@ func (r *mongoProductRepository) GetBySKU(ctx context.Context, sku string) (*mod
 	return &product, nil
 }
 
-func (r *mongoProductRepository) Update(ctx context.Context, id string, product *model.Product) error {
+func (r *mongoProductRepository) GetBySKUs(ctx context.Context, skus []string) ([]*model.Product, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"sku": bson.M{"$in": skus}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*model.Product
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *mongoProductRepository) UpsertBySKU(ctx context.Context, products []*model.Product) (map[int]bool, map[int]error, error) {
+	now := time.Now()
+	writes := make([]mongo.WriteModel, len(products))
+	for i, product := range products {
+		insert := bson.M{
+			"_id":        product.ID,
+			"quantity":   product.Quantity,
+			"status":     product.Status,
+			"created_at": now,
+		}
+		if product.PublishedAt != nil {
+			insert["published_at"] = *product.PublishedAt
+		}
+		if len(product.Attributes) > 0 {
+			insert["attributes"] = product.Attributes
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"name":              product.Name,
+				"description":       product.Description,
+				"price":             product.Price,
+				"category":          product.Category,
+				"reorder_threshold": product.ReorderThreshold,
+				"updated_at":        now,
+			},
+			"$setOnInsert": insert,
+		}
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"sku": product.SKU}).
+			SetUpdate(update).
+			SetUpsert(true)
+	}
+
+	// Unordered writes keep going past rows that fail
+	result, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	failed := make(map[int]error)
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			failed[writeErr.Index] = writeErr
+		}
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	inserted := make(map[int]bool)
+	if result != nil {
+		for index := range result.UpsertedIDs {
+			inserted[int(index)] = true
+		}
+	}
+
+	return inserted, failed, nil
+}
+
+func (r *mongoProductRepository) Update(ctx context.Context, id string, product *model.Product, fields []string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
+	// Quantity is owned by the inventory repository and never overwritten here
 	product.UpdatedAt = time.Now()
-	update := bson.M{
-		"$set": bson.M{
-			"name":        product.Name,
-			"description": product.Description,
-			"price":       product.Price,
-			"quantity":    product.Quantity,
-			"category":    product.Category,
-			"updated_at":  product.UpdatedAt,
-		},
+	set := bson.M{"updated_at": product.UpdatedAt}
+	unset := bson.M{}
+	for _, field := range fields {
+		var value interface{}
+		switch field {
+		case "name":
+			value = product.Name
+		case "description":
+			value = product.Description
+		case "price":
+			value = product.Price
+		case "category":
+			value = product.Category
+		case "reorder_threshold":
+			value = product.ReorderThreshold
+		case "price_lists":
+			value = product.PriceLists
+		case "attributes":
+			value = product.Attributes
+		default:
+			name, ok := strings.CutPrefix(field, "attributes.")
+			if !ok {
+				return fmt.Errorf("unknown product field %q", field)
+			}
+			value = product.Attributes[name]
+		}
+
+		// Cleared fields are removed rather than stored empty
+		if emptyValue(value) {
+			unset[field] = ""
+		} else {
+			set[field] = value
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
 	}
 
 	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	return err
 }
 
+func (r *mongoProductRepository) ReplaceVariants(ctx context.Context, id primitive.ObjectID, current, variants []model.Variant) error {
+	// Matching the IDs and stock of the variants as they were read makes the
+	// swap fail instead of losing a stock change made in the meantime
+	expected := bson.A{}
+	for _, variant := range current {
+		expected = append(expected, bson.A{variant.ID, variant.Quantity})
+	}
+	filter := bson.M{
+		"_id": id,
+		"$expr": bson.M{"$eq": bson.A{
+			bson.M{"$map": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$variants", bson.A{}}},
+				"as":    "variant",
+				"in":    bson.A{"$$variant._id", "$$variant.quantity"},
+			}},
+			expected,
+		}},
+	}
+
+	update := bson.M{"$set": bson.M{"variants": variants, "updated_at": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrProductChanged
+	}
+	return nil
+}
+
+func (r *mongoProductRepository) SKUsInUse(ctx context.Context, skus []string, exclude *primitive.ObjectID) ([]string, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"sku": bson.M{"$in": skus}},
+		bson.M{"variants.sku": bson.M{"$in": skus}},
+	}}
+	if exclude != nil {
+		filter["_id"] = bson.M{"$ne": *exclude}
+	}
+
+	opts := options.Find().SetProjection(bson.M{"sku": 1, "variants.sku": 1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	wanted := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		wanted[sku] = true
+	}
+
+	var taken []string
+	for cursor.Next(ctx) {
+		var product model.Product
+		if err := cursor.Decode(&product); err != nil {
+			return nil, err
+		}
+		for _, sku := range product.SKUs() {
+			if wanted[sku] {
+				taken = append(taken, sku)
+				wanted[sku] = false
+			}
+		}
+	}
+
+	return taken, cursor.Err()
+}
+
+func (r *mongoProductRepository) UpdateStatus(ctx context.Context, product *model.Product, from string) error {
+	filter := mergeFilters(bson.M{"_id": product.ID}, statusFilter(from))
+
+	set := bson.M{"status": product.Status, "updated_at": time.Now()}
+	unset := bson.M{}
+	for field, value := range map[string]*time.Time{
+		"published_at": product.PublishedAt,
+		"publish_at":   product.PublishAt,
+		"unpublish_at": product.UnpublishAt,
+	} {
+		if value != nil {
+			set[field] = *value
+		} else {
+			unset[field] = ""
+		}
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrProductChanged
+	}
+	product.UpdatedAt = set["updated_at"].(time.Time)
+	return nil
+}
+
+func (r *mongoProductRepository) ListDueTransitions(ctx context.Context, now time.Time, limit int) ([]*model.Product, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": model.StatusDraft, "publish_at": bson.M{"$lte": now}},
+		mergeFilters(statusFilter(model.StatusPublished), bson.M{"unpublish_at": bson.M{"$lte": now}}),
+	}}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*model.Product
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *mongoProductRepository) AddMedia(ctx context.Context, id primitive.ObjectID, media *model.Media) (*model.Product, error) {
+	update := bson.M{
+		"$push": bson.M{"media": media},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	return r.findAndUpdate(ctx, bson.M{"_id": id}, update)
+}
+
+func (r *mongoProductRepository) RemoveMedia(ctx context.Context, id, mediaID primitive.ObjectID) (*model.Product, error) {
+	update := bson.M{
+		"$pull": bson.M{"media": bson.M{"_id": mediaID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	return r.findAndUpdate(ctx, bson.M{"_id": id, "media._id": mediaID}, update)
+}
+
+func (r *mongoProductRepository) ListPendingThumbnails(ctx context.Context, limit int) ([]*model.Product, error) {
+	filter := bson.M{"media.thumbnail_status": model.ThumbnailPending}
+	opts := options.Find().SetProjection(bson.M{"media": 1}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*model.Product
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *mongoProductRepository) SetThumbnail(ctx context.Context, id primitive.ObjectID, media *model.Media) error {
+	update := bson.M{"$set": bson.M{
+		"media.$.thumbnail_key":    media.ThumbnailKey,
+		"media.$.thumbnail_url":    media.ThumbnailURL,
+		"media.$.thumbnail_status": media.ThumbnailStatus,
+		"media.$.width":            media.Width,
+		"media.$.height":           media.Height,
+		"updated_at":               time.Now(),
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "media._id": media.ID}, update)
+	return err
+}
+
+// findAndUpdate applies update to the matching product and returns it as
+// updated, or nil when nothing matched
+func (r *mongoProductRepository) findAndUpdate(ctx context.Context, filter, update bson.M) (*model.Product, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var product model.Product
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
 func (r *mongoProductRepository) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -95,49 +435,411 @@ func (r *mongoProductRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
-func (r *mongoProductRepository) List(ctx context.Context, page, limit int, search, category string) ([]*model.Product, int64, error) {
-	filter := bson.M{}
+func (r *mongoProductRepository) List(ctx context.Context, params *model.ListProductsParams) ([]*model.Product, *pagination.PageInfo, error) {
+	filter := buildListFilter(params)
 
-	if search != "" {
-		filter["$or"] = []bson.M{
-			{"name": bson.M{"$regex": search, "$options": "i"}},
-			{"description": bson.M{"$regex": search, "$options": "i"}},
-			{"sku": bson.M{"$regex": search, "$options": "i"}},
-		}
+	sortField := model.ProductSortFields[params.SortBy]
+	sortKey := params.SortBy + ":" + params.SortOrder
+	descending := params.SortOrder == "desc"
+	sortDirection := 1
+	if descending {
+		sortDirection = -1
 	}
 
-	if category != "" {
-		filter["category"] = bson.M{"$regex": category, "$options": "i"}
+	var cursor *pagination.Cursor
+	if params.PageToken != "" {
+		var err error
+		if cursor, err = pagination.DecodeCursor(params.PageToken, sortKey); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Count total documents
+	total, estimated, err := pagination.Count(ctx, r.collection, filter, params.TotalMode, cursor != nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Fetch one extra document to learn whether another page follows
+	findOptions := options.Find().
+		SetLimit(int64(params.Limit + 1)).
+		SetSort(bson.D{{Key: sortField, Value: sortDirection}, {Key: "_id", Value: sortDirection}})
+
+	if params.Search != "" {
+		findOptions.SetProjection(bson.M{"score": textScore})
+		if params.SortBy == "relevance" {
+			findOptions.SetSort(bson.D{{Key: "score", Value: textScore}, {Key: "_id", Value: 1}})
+		}
+	}
+
+	query := filter
+	if cursor != nil {
+		query = bson.M{"$and": []bson.M{filter, cursor.After(sortField, descending)}}
+	} else {
+		findOptions.SetSkip(int64((params.Page - 1) * params.Limit))
+	}
+
+	dbCursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer dbCursor.Close(ctx)
+
+	var products []*model.Product
+	if err = dbCursor.All(ctx, &products); err != nil {
+		return nil, nil, err
+	}
+
+	pageInfo := &pagination.PageInfo{
+		Total:          total,
+		TotalEstimated: estimated,
+	}
+
+	hasMore := len(products) > params.Limit
+	if hasMore {
+		products = products[:params.Limit]
+	}
+
+	// Text scores cannot be used in filters, so relevance ordering only pages by offset
+	if hasMore && params.SortBy != "relevance" {
+		last := products[len(products)-1]
+		if pageInfo.NextPageToken, err = pagination.EncodeCursor(sortKey, last.SortValue(params.SortBy), last.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return products, pageInfo, nil
+}
+
+func (r *mongoProductRepository) Export(ctx context.Context, params *model.ListProductsParams, fn func(*model.Product) error) error {
+	sortDirection := 1
+	if params.SortOrder == "desc" {
+		sortDirection = -1
+	}
+	findOptions := options.Find().
+		SetBatchSize(pagination.ExportBatchSize).
+		SetSort(bson.D{{Key: model.ProductSortFields[params.SortBy], Value: sortDirection}, {Key: "_id", Value: sortDirection}})
+
+	if params.Search != "" {
+		findOptions.SetProjection(bson.M{"score": textScore})
+		if params.SortBy == "relevance" {
+			findOptions.SetSort(bson.D{{Key: "score", Value: textScore}, {Key: "_id", Value: 1}})
+		}
+	}
+
+	dbCursor, err := r.collection.Find(ctx, buildListFilter(params), findOptions)
+	if err != nil {
+		return err
+	}
+	defer dbCursor.Close(ctx)
+
+	for dbCursor.Next(ctx) {
+		var product model.Product
+		if err := dbCursor.Decode(&product); err != nil {
+			return err
+		}
+		if err := fn(&product); err != nil {
+			return err
+		}
+	}
+
+	return dbCursor.Err()
+}
+
+func (r *mongoProductRepository) ListLowStock(ctx context.Context, page, limit int) ([]*model.Product, int64, error) {
+	filter := bson.M{
+		"reorder_threshold": bson.M{"$gt": 0},
+		"$expr":             bson.M{"$lt": bson.A{"$quantity", "$reorder_threshold"}},
+	}
+
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Calculate skip
-	skip := (page - 1) * limit
+	// Emptiest shelves first
+	opts := options.Find().
+		SetSort(bson.D{{Key: "quantity", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
 
-	// Find documents with pagination
-	cursor, err := r.collection.Find(ctx, filter, &options.FindOptions{
-		Skip:  &[]int64{int64(skip)}[0],
-		Limit: &[]int64{int64(limit)}[0],
-		Sort:  bson.M{"created_at": -1},
-	})
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
 	var products []*model.Product
-	if err = cursor.All(ctx, &products); err != nil {
+	if err := cursor.All(ctx, &products); err != nil {
 		return nil, 0, err
 	}
 
 	return products, total, nil
 }
 
+// buildListFilter translates listing parameters into a MongoDB filter
+func buildListFilter(params *model.ListProductsParams) bson.M {
+	var conditions []bson.M
+
+	if params.Status != "" {
+		conditions = append(conditions, statusFilter(params.Status))
+	}
+
+	if params.Search != "" {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": params.Search}})
+	}
+
+	if params.Category != "" {
+		conditions = append(conditions, bson.M{"category": params.Category})
+	}
+
+	if len(params.Categories) > 0 {
+		conditions = append(conditions, bson.M{"category": bson.M{"$in": params.Categories}})
+	}
+
+	if params.CategoryTree != "" {
+		conditions = append(conditions, categoryTreeFilter("category", params.CategoryTree))
+	}
+
+	for _, attribute := range params.Attributes {
+		conditions = append(conditions, attributeFilter(attribute))
+	}
+
+	price := priceRange(params.MinPrice, params.MaxPrice, params.Currency)
+	// Amounts of different currencies do not compare
+	if params.SortBy == "price" {
+		price["price.currency"] = params.Currency
+	}
+	if len(price) > 0 {
+		conditions = append(conditions, price)
+	}
+
+	quantity := bson.M{}
+	if params.MinQuantity != nil {
+		quantity["$gte"] = *params.MinQuantity
+	}
+	if params.MaxQuantity != nil {
+		quantity["$lte"] = *params.MaxQuantity
+	}
+	if len(quantity) > 0 {
+		conditions = append(conditions, bson.M{"quantity": quantity})
+	}
+	if params.InStock {
+		conditions = append(conditions, inStockFilter())
+	}
+
+	created := bson.M{}
+	if params.CreatedAfter != nil {
+		created["$gte"] = *params.CreatedAfter
+	}
+	if params.CreatedBefore != nil {
+		created["$lt"] = *params.CreatedBefore
+	}
+	if len(created) > 0 {
+		conditions = append(conditions, bson.M{"created_at": created})
+	}
+
+	switch len(conditions) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return conditions[0]
+	default:
+		return bson.M{"$and": conditions}
+	}
+}
+
+func (r *mongoProductRepository) Search(ctx context.Context, params *model.SearchProductsParams) (*model.SearchResult, error) {
+	// Only the text query runs before $facet, so each facet can drop its own
+	// filter and report counts for the values a shopper could switch to
+	pipeline := mongo.Pipeline{}
+	if params.Query != "" {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$match", Value: bson.M{"$text": bson.M{"$search": params.Query}}}},
+			bson.D{{Key: "$addFields", Value: bson.M{"score": textScore}}},
+		)
+	}
+	if !params.IncludeUnpublished {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: statusFilter(model.StatusPublished)}})
+	}
+
+	categoryFilter := bson.M{}
+	if len(params.Categories) > 0 {
+		categoryFilter["category"] = bson.M{"$in": params.Categories}
+	}
+
+	priceFilter := priceRange(params.MinPrice, params.MaxPrice, params.Currency)
+	// Amounts of different currencies do not compare
+	if params.SortBy == "price" {
+		priceFilter["price.currency"] = params.Currency
+	}
+
+	stockFilter := bson.M{}
+	if params.InStock {
+		stockFilter = inStockFilter()
+	}
+
+	sortDirection := 1
+	if params.SortOrder == "desc" {
+		sortDirection = -1
+	}
+	sort := bson.D{{Key: model.ProductSortFields[params.SortBy], Value: sortDirection}, {Key: "_id", Value: sortDirection}}
+
+	matchAll := bson.M{"$match": mergeFilters(categoryFilter, priceFilter, stockFilter)}
+	facets := bson.M{
+		"products": bson.A{
+			matchAll,
+			bson.M{"$sort": sort},
+			bson.M{"$skip": int64((params.Page - 1) * params.Limit)},
+			bson.M{"$limit": int64(params.Limit)},
+		},
+		"total": bson.A{
+			matchAll,
+			bson.M{"$count": "count"},
+		},
+		"categories": bson.A{
+			bson.M{"$match": mergeFilters(priceFilter, stockFilter)},
+			bson.M{"$sortByCount": "$category"},
+			bson.M{"$limit": 50},
+		},
+		"price_buckets": bson.A{
+			bson.M{"$match": mergeFilters(categoryFilter, stockFilter, bson.M{"price.currency": params.Currency})},
+			bson.M{"$bucket": bson.M{
+				"groupBy":    "$price.amount",
+				"boundaries": params.PriceBoundaries,
+				"default":    "other",
+				"output":     bson.M{"count": bson.M{"$sum": 1}},
+			}},
+		},
+		"stock": bson.A{
+			bson.M{"$match": mergeFilters(categoryFilter, priceFilter)},
+			bson.M{"$group": bson.M{
+				"_id":          nil,
+				"in_stock":     bson.M{"$sum": bson.M{"$cond": bson.A{inStockExpr, 1, 0}}},
+				"out_of_stock": bson.M{"$sum": bson.M{"$cond": bson.A{inStockExpr, 0, 1}}},
+			}},
+		},
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: facets}})
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Products     []*model.Product `bson:"products"`
+		Total        []bson.M         `bson:"total"`
+		Categories   []bson.M         `bson:"categories"`
+		PriceBuckets []bson.M         `bson:"price_buckets"`
+		Stock        []bson.M         `bson:"stock"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	result := &model.SearchResult{}
+	if len(results) == 0 {
+		return result, nil
+	}
+	facetResult := results[0]
+
+	result.Products = facetResult.Products
+	if len(facetResult.Total) > 0 {
+		result.Total = getInt64FromBSON(facetResult.Total[0], "count")
+	}
+
+	for _, doc := range facetResult.Categories {
+		category, _ := doc["_id"].(string)
+		result.Facets.Categories = append(result.Facets.Categories, model.FacetCount{
+			Value: category,
+			Count: getInt64FromBSON(doc, "count"),
+		})
+	}
+
+	// $bucket labels each bucket with its lower bound, prices at or above the
+	// last boundary land in the default bucket
+	boundaries := params.PriceBoundaries
+	for _, doc := range facetResult.PriceBuckets {
+		bucket := model.PriceBucket{Count: getInt64FromBSON(doc, "count")}
+		if _, isDefault := doc["_id"].(string); isDefault {
+			bucket.Min = boundaries[len(boundaries)-1]
+		} else {
+			bucket.Min = getInt64FromBSON(doc, "_id")
+			for i, boundary := range boundaries[:len(boundaries)-1] {
+				if boundary == bucket.Min {
+					upper := boundaries[i+1]
+					bucket.Max = &upper
+					break
+				}
+			}
+		}
+		result.Facets.PriceBuckets = append(result.Facets.PriceBuckets, bucket)
+	}
+
+	if len(facetResult.Stock) > 0 {
+		result.Facets.InStock = getInt64FromBSON(facetResult.Stock[0], "in_stock")
+		result.Facets.OutOfStock = getInt64FromBSON(facetResult.Stock[0], "out_of_stock")
+	}
+
+	return result, nil
+}
+
+// SuggestByPrefix matches products whose name or SKU starts with prefix. It
+// backs the typeahead when the Redis index cannot answer.
+func (r *mongoProductRepository) SuggestByPrefix(ctx context.Context, prefix string, limit int) ([]*model.Product, error) {
+	pattern := "^" + regexp.QuoteMeta(prefix)
+	// Suggestions are public, so only published products are offered
+	filter := mergeFilters(statusFilter(model.StatusPublished), bson.M{"$or": []bson.M{
+		{"name": bson.M{"$regex": pattern, "$options": "i"}},
+		{"sku": bson.M{"$regex": "^" + regexp.QuoteMeta(strings.ToUpper(prefix))}},
+	}})
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*model.Product
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// mergeFilters combines single-field filters into one filter document
+func mergeFilters(filters ...bson.M) bson.M {
+	merged := bson.M{}
+	for _, filter := range filters {
+		for key, value := range filter {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// priceRange matches prices within a range of minor units. Amounts in
+// different currencies are not comparable, so a range also pins the currency.
+func priceRange(minPrice, maxPrice *int64, currency string) bson.M {
+	amount := bson.M{}
+	if minPrice != nil {
+		amount["$gte"] = *minPrice
+	}
+	if maxPrice != nil {
+		amount["$lte"] = *maxPrice
+	}
+	if len(amount) == 0 {
+		return bson.M{}
+	}
+
+	return bson.M{"price.currency": currency, "price.amount": amount}
+}
+
 // Helper functions for BSON type conversion
 func getInt64FromBSON(doc bson.M, key string) int64 {
 	if val, ok := doc[key]; ok {
@@ -170,3 +872,38 @@ func getFloat64FromBSON(doc bson.M, key string) float64 {
 	}
 	return 0.0
 }
+
+// statusFilter matches products in a lifecycle state. Products without a
+// status predate lifecycle states and count as published.
+func statusFilter(status string) bson.M {
+	if status == model.StatusPublished {
+		return bson.M{"status": bson.M{"$in": bson.A{model.StatusPublished, nil}}}
+	}
+	return bson.M{"status": status}
+}
+
+// inStockFilter matches products with stock of their own or in any variant
+func inStockFilter() bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"quantity": bson.M{"$gt": 0}},
+		bson.M{"variants.quantity": bson.M{"$gt": 0}},
+	}}
+}
+
+// attributeFilter translates an attribute filter into a condition on the
+// attributes subdocument
+func attributeFilter(filter model.AttributeFilter) bson.M {
+	field := "attributes." + filter.Name
+	switch filter.Op {
+	case model.AttributeMin, model.AttributeMax:
+		// Validated as a number by the service
+		bound, _ := strconv.ParseFloat(filter.Value, 64)
+		op := "$gte"
+		if filter.Op == model.AttributeMax {
+			op = "$lte"
+		}
+		return bson.M{field: bson.M{op: bound}}
+	default:
+		return bson.M{field: bson.M{"$in": filter.Candidates()}}
+	}
+}
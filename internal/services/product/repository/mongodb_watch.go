@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/services/product/model"
+)
+
+// Server error codes of change streams
+const (
+	codeInvalidResumeToken      = 260
+	codeChangeStreamFatal       = 280
+	codeChangeStreamHistoryLost = 286
+	codeChangeStreamUnsupported = 40573
+)
+
+// productChangeEvent is a change stream event of the products collection
+type productChangeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *model.Product `bson:"fullDocument"`
+	// FullDocumentBeforeChange is set for updates and deletions when the
+	// collection keeps pre-images
+	FullDocumentBeforeChange *model.Product `bson:"fullDocumentBeforeChange"`
+	UpdateDescription        *struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+	ClusterTime primitive.Timestamp `bson:"clusterTime"`
+}
+
+func (r *mongoProductRepository) Watch(ctx context.Context, params *model.WatchProductsParams, fn func(*model.ProductChange) error) error {
+	// Filtering deletions needs the product they deleted
+	if params.FiltersDocuments() {
+		enabled, err := r.preImagesEnabled(ctx)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return model.ErrWatchFiltersUnsupported
+		}
+	}
+
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+	if params.ResumeToken != "" {
+		token, err := decodeResumeToken(params.ResumeToken)
+		if err != nil {
+			return err
+		}
+		opts.SetResumeAfter(token)
+	}
+
+	stream, err := r.collection.Watch(ctx, watchPipeline(params), opts)
+	if err != nil {
+		return watchError(err)
+	}
+	defer stream.Close(context.Background())
+
+	// The token of the moment the watch started lets a client that saw no
+	// change yet resume without missing one
+	opened := &model.ProductChange{
+		Type:        model.ChangeWatching,
+		ResumeToken: params.ResumeToken,
+		OccurredAt:  time.Now(),
+	}
+	if token := stream.ResumeToken(); token != nil {
+		opened.ResumeToken = encodeResumeToken(token)
+	}
+	if err := fn(opened); err != nil {
+		return err
+	}
+
+	for stream.Next(ctx) {
+		var event productChangeEvent
+		if err := stream.Decode(&event); err != nil {
+			return err
+		}
+
+		change := &model.ProductChange{
+			ProductID:   event.DocumentKey.ID.Hex(),
+			Product:     event.FullDocument,
+			Previous:    event.FullDocumentBeforeChange,
+			ResumeToken: encodeResumeToken(stream.ResumeToken()),
+			OccurredAt:  time.Unix(int64(event.ClusterTime.T), 0),
+		}
+		switch event.OperationType {
+		case "insert":
+			change.Type = model.ChangeCreated
+		case "delete":
+			change.Type = model.ChangeDeleted
+			change.Product = nil
+		default:
+			// The product was deleted before its update could be looked
+			// up; the deletion follows
+			if event.FullDocument == nil {
+				continue
+			}
+			change.Type = model.ChangeUpdated
+			if event.UpdateDescription != nil {
+				change.ChangedFields = changedFields(event.UpdateDescription.UpdatedFields, event.UpdateDescription.RemovedFields)
+			}
+		}
+
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return watchError(stream.Err())
+}
+
+// watchPipeline filters the change stream by the watch parameters
+func watchPipeline(params *model.WatchProductsParams) mongo.Pipeline {
+	conditions := []bson.M{
+		{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}}},
+	}
+
+	if len(params.ProductIDs) > 0 {
+		ids := make(bson.A, 0, len(params.ProductIDs))
+		for _, id := range params.ProductIDs {
+			if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+				ids = append(ids, objectID)
+			}
+		}
+		conditions = append(conditions, bson.M{"documentKey._id": bson.M{"$in": ids}})
+	}
+
+	// Changes pass when the product matches before or after them, so
+	// watchers learn when a product leaves their filters
+	after := documentFilter(params, "fullDocument")
+	if len(after) > 0 {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"$and": after},
+			bson.M{"$and": documentFilter(params, "fullDocumentBeforeChange")},
+		}})
+	}
+
+	return mongo.Pipeline{{{Key: "$match", Value: bson.M{"$and": conditions}}}}
+}
+
+// documentFilter returns the category and visibility conditions of a watch
+// on the product stored at prefix of change events
+func documentFilter(params *model.WatchProductsParams, prefix string) []bson.M {
+	var document []bson.M
+	if params.Category != "" {
+		document = append(document, bson.M{prefix + ".category": params.Category})
+	}
+	if params.CategoryTree != "" {
+		document = append(document, categoryTreeFilter(prefix+".category", params.CategoryTree))
+	}
+	if !params.IncludeUnpublished {
+		// Products without a status count as published
+		document = append(document, bson.M{prefix + ".status": bson.M{"$in": bson.A{model.StatusPublished, "", nil}}})
+	}
+	return document
+}
+
+// preImagesEnabled reports whether change streams of the products
+// collection carry the product before each change
+func (r *mongoProductRepository) preImagesEnabled(ctx context.Context) (bool, error) {
+	specs, err := r.collection.Database().ListCollectionSpecifications(ctx, bson.M{"name": r.collection.Name()})
+	if err != nil {
+		return false, err
+	}
+	if len(specs) == 0 || specs[0].Options == nil {
+		return false, nil
+	}
+	enabled, ok := specs[0].Options.Lookup("changeStreamPreAndPostImages", "enabled").BooleanOK()
+	return ok && enabled, nil
+}
+
+// changedFields returns the top-level fields an update set or removed
+func changedFields(updated bson.M, removed []string) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		field, _, _ := strings.Cut(path, ".")
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	for path := range updated {
+		add(path)
+	}
+	for _, path := range removed {
+		add(path)
+	}
+	return fields
+}
+
+func encodeResumeToken(token bson.Raw) string {
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+func decodeResumeToken(token string) (bson.Raw, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, model.ErrInvalidResumeToken
+	}
+	raw := bson.Raw(data)
+	if err := raw.Validate(); err != nil {
+		return nil, model.ErrInvalidResumeToken
+	}
+	return raw, nil
+}
+
+// watchError translates the errors of change streams the caller can act on
+func watchError(err error) error {
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		switch {
+		case serverErr.HasErrorCode(codeChangeStreamUnsupported):
+			return model.ErrWatchUnsupported
+		case serverErr.HasErrorCode(codeInvalidResumeToken), serverErr.HasErrorCode(codeChangeStreamFatal),
+			serverErr.HasErrorCode(codeChangeStreamHistoryLost):
+			return model.ErrInvalidResumeToken
+		}
+	}
+	return err
+}
@@ -56,6 +56,49 @@ func (c *productCache) Delete(ctx context.Context, key string) error {
 	return c.client.Client.Del(ctx, key).Err()
 }
 
+// GetMany retrieves several products from cache in one round trip. The result
+// lines up with keys, holding nil for every key that missed.
+func (c *productCache) GetMany(ctx context.Context, keys []string) ([]*model.Product, error) {
+	values, err := c.client.Client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*model.Product, len(keys))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var product model.Product
+		// An entry that no longer decodes counts as a miss
+		if err := json.Unmarshal([]byte(data), &product); err == nil {
+			products[i] = &product
+		}
+	}
+
+	return products, nil
+}
+
+// SetMany stores several products in cache, keyed by cache key, in one round
+// trip
+func (c *productCache) SetMany(ctx context.Context, products map[string]*model.Product, expiration int) error {
+	pipe := c.client.Client.Pipeline()
+	for key, product := range products {
+		data, err := json.Marshal(product)
+		if err != nil {
+			return fmt.Errorf("failed to marshal product: %w", err)
+		}
+		pipe.Set(ctx, key, data, time.Duration(expiration)*time.Second)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return nil
+}
+
 // SetList stores a list of products in cache
 func (c *productCache) SetList(ctx context.Context, key string, products []*model.Product, expiration int) error {
 	data, err := json.Marshal(products)
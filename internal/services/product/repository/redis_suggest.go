@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/product/model"
+)
+
+const (
+	// suggestTermsKey is a sorted set of "<term>\x00<product id>" members, all
+	// scored 0 so ZRANGEBYLEX can answer prefix queries
+	suggestTermsKey = "product:suggest:terms"
+	// suggestDataKey maps product IDs to the name and SKU shown in suggestions
+	suggestDataKey = "product:suggest:data"
+	// maxSuggestWords caps how many word-start suffixes of a name are indexed
+	maxSuggestWords = 8
+)
+
+type redisSuggestIndex struct {
+	client *database.Redis
+}
+
+// NewRedisSuggestIndex creates a Redis backed product prefix index
+func NewRedisSuggestIndex(redis *database.Redis) ProductSuggestIndex {
+	return &redisSuggestIndex{
+		client: redis,
+	}
+}
+
+// Index adds or refreshes the prefix terms of a product
+func (i *redisSuggestIndex) Index(ctx context.Context, product *model.Product) error {
+	id := product.ID.Hex()
+	suggestion := model.Suggestion{ID: id, Name: product.Name, SKU: product.SKU}
+
+	data, err := json.Marshal(suggestion)
+	if err != nil {
+		return fmt.Errorf("failed to marshal suggestion: %w", err)
+	}
+
+	staleTerms, err := i.storedTerms(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.client.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(staleTerms) > 0 {
+			pipe.ZRem(ctx, suggestTermsKey, staleTerms...)
+		}
+
+		var members []*redis.Z
+		for _, term := range suggestTerms(&suggestion) {
+			members = append(members, &redis.Z{Member: term + "\x00" + id})
+		}
+		if len(members) > 0 {
+			pipe.ZAdd(ctx, suggestTermsKey, members...)
+		}
+		pipe.HSet(ctx, suggestDataKey, id, data)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index product suggestions: %w", err)
+	}
+
+	return nil
+}
+
+// Remove drops every prefix term of a product
+func (i *redisSuggestIndex) Remove(ctx context.Context, id string) error {
+	staleTerms, err := i.storedTerms(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.client.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(staleTerms) > 0 {
+			pipe.ZRem(ctx, suggestTermsKey, staleTerms...)
+		}
+		pipe.HDel(ctx, suggestDataKey, id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove product suggestions: %w", err)
+	}
+
+	return nil
+}
+
+// Suggest returns up to limit products whose name or SKU starts with prefix
+func (i *redisSuggestIndex) Suggest(ctx context.Context, prefix string, limit int) ([]*model.Suggestion, error) {
+	prefix = normalizeSuggestTerm(prefix)
+
+	// Several terms can point at the same product, so over-fetch and dedupe
+	members, err := i.client.Client.ZRangeByLex(ctx, suggestTermsKey, &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: int64(limit * 3),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suggestions: %w", err)
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, member := range members {
+		id := member[strings.LastIndexByte(member, 0)+1:]
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		if len(ids) == limit {
+			break
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	values, err := i.client.Client.HMGet(ctx, suggestDataKey, ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load suggestions: %w", err)
+	}
+
+	suggestions := make([]*model.Suggestion, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var suggestion model.Suggestion
+		if err := json.Unmarshal([]byte(data), &suggestion); err != nil {
+			continue
+		}
+		suggestions = append(suggestions, &suggestion)
+	}
+
+	return suggestions, nil
+}
+
+// storedTerms returns the sorted set members written for a product by the
+// last Index call
+func (i *redisSuggestIndex) storedTerms(ctx context.Context, id string) ([]interface{}, error) {
+	data, err := i.client.Client.HGet(ctx, suggestDataKey, id).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load indexed suggestion: %w", err)
+	}
+
+	var suggestion model.Suggestion
+	if err := json.Unmarshal([]byte(data), &suggestion); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal indexed suggestion: %w", err)
+	}
+
+	var members []interface{}
+	for _, term := range suggestTerms(&suggestion) {
+		members = append(members, term+"\x00"+id)
+	}
+	return members, nil
+}
+
+// suggestTerms lists the searchable prefixes of a product: its SKU, its full
+// name and the name starting at each following word
+func suggestTerms(suggestion *model.Suggestion) []string {
+	var terms []string
+	if sku := normalizeSuggestTerm(suggestion.SKU); sku != "" {
+		terms = append(terms, sku)
+	}
+
+	words := strings.Fields(normalizeSuggestTerm(suggestion.Name))
+	for i := range words {
+		if i == maxSuggestWords {
+			break
+		}
+		terms = append(terms, strings.Join(words[i:], " "))
+	}
+
+	return terms
+}
+
+func normalizeSuggestTerm(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
@@ -1,37 +1,80 @@
 package product
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"go-microservice-boilerplate/internal/blobstore"
 	"go-microservice-boilerplate/internal/config"
 	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/exchange"
+	"go-microservice-boilerplate/internal/notifier"
+	"go-microservice-boilerplate/internal/outbox"
 	"go-microservice-boilerplate/internal/proto/product"
 	"go-microservice-boilerplate/internal/services/product/handler"
 	"go-microservice-boilerplate/internal/services/product/repository"
 	"go-microservice-boilerplate/internal/services/product/service"
 	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+const (
+	// reservationSweepInterval is how often expired stock reservations are released
+	reservationSweepInterval = 30 * time.Second
+	// lifecycleSweepInterval is how often scheduled publishes and unpublishes run
+	lifecycleSweepInterval = time.Minute
+	// thumbnailSweepInterval is how often thumbnails of new media are generated
+	thumbnailSweepInterval = 10 * time.Second
 )
 
 type Server struct {
 	config         *config.Config
 	grpcServer     *grpc.Server
 	productService service.ProductService
+	productHandler *handler.ProductGRPCHandler
+	outbox         outbox.Outbox
+	publisher      events.Publisher
+	stopWorkers    context.CancelFunc
 }
 
 func NewServer(cfg *config.Config, mongodb *database.MongoDB, redis *database.Redis) *Server {
+	indexCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.MongoDB.Timeout)*time.Second)
+	defer cancel()
+	if err := repository.EnsureIndexes(indexCtx, mongodb); err != nil {
+		logger.Fatalf("Failed to create product indexes: %v", err)
+	}
+	// Watches need the product before a change to tell when it left them
+	if err := mongodb.EnablePreImages(indexCtx, "products"); err != nil {
+		logger.Warnf("Product watches will not see products leave their filters: %v", err)
+	}
+
 	// Initialize repositories
 	productRepo := repository.NewMongoProductRepository(mongodb)
 	productCache := repository.NewRedisProductCache(redis)
+	suggestIndex := repository.NewRedisSuggestIndex(redis)
+	inventoryRepo := repository.NewMongoInventoryRepository(mongodb)
+	categoryRepo := repository.NewMongoCategoryRepository(mongodb)
+	productOutbox := outbox.NewMongoOutbox(mongodb)
+
+	blobs, err := blobstore.New(cfg.BlobStore)
+	if err != nil {
+		logger.Fatalf("Failed to create blob store: %v", err)
+	}
 
 	// Initialize service
-	productService := service.NewProductService(productRepo, productCache)
+	productService := service.NewProductService(productRepo, productCache, suggestIndex, inventoryRepo, categoryRepo, notifier.New(cfg.Notifier), exchange.New(cfg.Exchange), blobs, productOutbox, cfg.Media.ThumbnailSize)
 
 	// Initialize gRPC server
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestmeta.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(requestmeta.StreamServerInterceptor()),
+	)
 
 	// Register handlers
 	productHandler := handler.NewProductGRPCHandler(productService)
@@ -44,6 +87,9 @@ func NewServer(cfg *config.Config, mongodb *database.MongoDB, redis *database.Re
 		config:         cfg,
 		grpcServer:     grpcServer,
 		productService: productService,
+		productHandler: productHandler,
+		outbox:         productOutbox,
+		publisher:      events.NewBus(cfg.Events, redis),
 	}
 }
 
@@ -56,6 +102,13 @@ func (s *Server) Start() error {
 
 	logger.Infof("Product service starting on port %s", port)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWorkers = cancel
+	go s.releaseExpiredReservations(requestmeta.WithActor(ctx, "system:reservation-expiry"))
+	go s.processScheduledTransitions(requestmeta.WithActor(ctx, "system:lifecycle-scheduler"))
+	go s.processThumbnails(requestmeta.WithActor(ctx, "system:thumbnailer"))
+	go outbox.Run(ctx, s.outbox, s.publisher)
+
 	if err := s.grpcServer.Serve(listener); err != nil {
 		return fmt.Errorf("failed to serve gRPC server: %w", err)
 	}
@@ -65,5 +118,75 @@ func (s *Server) Start() error {
 
 func (s *Server) Stop() {
 	logger.Info("Shutting down Product service...")
+	if s.stopWorkers != nil {
+		s.stopWorkers()
+	}
+	s.productHandler.CloseStreams()
 	s.grpcServer.GracefulStop()
 }
+
+// releaseExpiredReservations hands the stock of expired reservations back
+// until ctx is cancelled
+func (s *Server) releaseExpiredReservations(ctx context.Context) {
+	ticker := time.NewTicker(reservationSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.productService.ReleaseExpiredReservations(ctx)
+			if err != nil {
+				logger.Errorf("Failed to release expired reservations: %v", err)
+			}
+			if released > 0 {
+				logger.Infof("Released %d expired stock reservations", released)
+			}
+		}
+	}
+}
+
+// processScheduledTransitions publishes and archives products at their
+// scheduled times until ctx is cancelled
+func (s *Server) processScheduledTransitions(ctx context.Context) {
+	ticker := time.NewTicker(lifecycleSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			moved, err := s.productService.ProcessScheduledTransitions(ctx)
+			if err != nil {
+				logger.Errorf("Failed to process scheduled product transitions: %v", err)
+			}
+			if moved > 0 {
+				logger.Infof("Moved %d products through scheduled lifecycle transitions", moved)
+			}
+		}
+	}
+}
+
+// processThumbnails generates thumbnails for newly uploaded media until ctx
+// is cancelled
+func (s *Server) processThumbnails(ctx context.Context) {
+	ticker := time.NewTicker(thumbnailSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			generated, err := s.productService.ProcessThumbnails(ctx)
+			if err != nil {
+				logger.Errorf("Failed to generate thumbnails: %v", err)
+			}
+			if generated > 0 {
+				logger.Infof("Generated %d media thumbnails", generated)
+			}
+		}
+	}
+}
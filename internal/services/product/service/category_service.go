@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/logger"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+)
+
+func (s *productService) CreateCategory(ctx context.Context, req *model.CreateCategoryRequest) (*model.Category, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, apperrors.ErrInvalidInput("category name is required")
+	}
+
+	slug, err := categorySlug(req.Slug, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAttributeDefinitions(req.Attributes); err != nil {
+		return nil, err
+	}
+
+	category := &model.Category{
+		Name:       name,
+		Slug:       slug,
+		Path:       slug,
+		SortOrder:  req.SortOrder,
+		Attributes: req.Attributes,
+	}
+
+	if req.ParentID != "" {
+		parent, err := s.parentCategory(ctx, req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		category.ParentID = &parent.ID
+		category.Path = model.JoinCategoryPath(parent.Path, slug)
+		category.Depth = parent.Depth + 1
+	}
+
+	if err := s.categories.Create(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return category, nil
+}
+
+func (s *productService) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	categoryID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput("invalid category id")
+	}
+
+	category, err := s.categories.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	return category, nil
+}
+
+func (s *productService) UpdateCategory(ctx context.Context, id string, req *model.UpdateCategoryRequest) (*model.Category, error) {
+	category, err := s.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	oldPath := category.Path
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		category.Name = name
+	}
+	if req.Slug != "" {
+		if category.Slug, err = categorySlug(req.Slug, category.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.SortOrder != nil {
+		category.SortOrder = *req.SortOrder
+	}
+	if req.Attributes != nil {
+		if err := validateAttributeDefinitions(req.Attributes); err != nil {
+			return nil, err
+		}
+		category.Attributes = req.Attributes
+	}
+
+	// The category stays under its current parent unless it is moved
+	parentPath := ""
+	if i := strings.LastIndex(oldPath, model.CategoryPathSeparator); i >= 0 {
+		parentPath = oldPath[:i]
+	}
+	if req.ParentID != nil {
+		category.ParentID, parentPath, category.Depth = nil, "", 0
+		if *req.ParentID != "" {
+			parent, err := s.parentCategory(ctx, *req.ParentID)
+			if err != nil {
+				return nil, err
+			}
+			// A category cannot be moved below itself
+			if parent.ID == category.ID || strings.HasPrefix(parent.Path, oldPath+model.CategoryPathSeparator) {
+				return nil, apperrors.ErrInvalidInput("a category cannot be moved into its own subtree")
+			}
+			category.ParentID, parentPath, category.Depth = &parent.ID, parent.Path, parent.Depth+1
+		}
+	}
+	category.Path = model.JoinCategoryPath(parentPath, category.Slug)
+
+	if err := s.categories.Update(ctx, category, oldPath); err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	// Products of the subtree now carry a different category
+	if category.Path != oldPath {
+		if err := s.cache.InvalidatePattern(ctx, "product:*"); err != nil {
+			logger.Warnf("Failed to invalidate cached products after moving category %s: %v", category.ID.Hex(), err)
+		}
+	}
+
+	return category, nil
+}
+
+func (s *productService) DeleteCategory(ctx context.Context, id string) error {
+	category, err := s.GetCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.categories.Delete(ctx, category); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	return nil
+}
+
+func (s *productService) ListCategories(ctx context.Context, params *model.ListCategoriesParams) ([]*model.Category, error) {
+	var parent *model.Category
+	if params.ParentID != "" {
+		var err error
+		if parent, err = s.GetCategory(ctx, params.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	categories, err := s.categories.List(ctx, parent, params.Recursive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// resolveCategory returns the canonical path of an existing category,
+// accepting any casing and stray separators
+func (s *productService) resolveCategory(ctx context.Context, path string) (string, error) {
+	path = model.NormalizeCategoryPath(path)
+	if path == "" {
+		return "", apperrors.ErrInvalidInput("category is required")
+	}
+
+	category, err := s.categories.GetByPath(ctx, path)
+	if errors.Is(err, model.ErrCategoryNotFound) {
+		return "", apperrors.ErrInvalidInput(fmt.Sprintf("unknown category %q", path))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up category: %w", err)
+	}
+
+	return category.Path, nil
+}
+
+// parentCategory loads the category a new or moved category is placed under
+func (s *productService) parentCategory(ctx context.Context, id string) (*model.Category, error) {
+	parentID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput("invalid parent category id")
+	}
+
+	parent, err := s.categories.GetByID(ctx, parentID)
+	if errors.Is(err, model.ErrCategoryNotFound) {
+		return nil, apperrors.ErrInvalidInput("parent category does not exist")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent category: %w", err)
+	}
+
+	return parent, nil
+}
+
+// categorySlug validates an explicit slug or derives one from the name
+func categorySlug(slug, name string) (string, error) {
+	if slug == "" {
+		if slug = model.Slugify(name); slug == "" {
+			return "", apperrors.ErrInvalidInput("category name must contain letters or digits")
+		}
+		return slug, nil
+	}
+
+	if !model.ValidSlug(slug) {
+		return "", apperrors.ErrInvalidInput("slug may only contain lowercase letters, digits and single dashes")
+	}
+	return slug, nil
+}
+
+// attributeNamePattern restricts attribute names to identifiers usable in
+// query parameters and document paths
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// validateAttributeDefinitions checks the attribute schema of a category
+func validateAttributeDefinitions(definitions []model.AttributeDefinition) error {
+	names := make(map[string]bool, len(definitions))
+	for i := range definitions {
+		definition := &definitions[i]
+		definition.Name = strings.TrimSpace(definition.Name)
+		definition.Type = strings.ToLower(strings.TrimSpace(definition.Type))
+
+		if !attributeNamePattern.MatchString(definition.Name) {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("invalid attribute name %q", definition.Name))
+		}
+		if names[definition.Name] {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("attribute %s is defined more than once", definition.Name))
+		}
+		names[definition.Name] = true
+
+		if !model.AttributeTypes[definition.Type] {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("attribute %s has unsupported type %q", definition.Name, definition.Type))
+		}
+		if definition.Type == model.AttributeEnum && len(definition.Values) == 0 {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("enum attribute %s needs values", definition.Name))
+		}
+		if definition.Type != model.AttributeEnum && len(definition.Values) > 0 {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("only enum attributes take values, %s is a %s", definition.Name, definition.Type))
+		}
+	}
+	return nil
+}
+
+// attributeSchema returns the attributes products of a category may carry:
+// those of the category and of its ancestors, the nearest definition of a
+// name winning
+func (s *productService) attributeSchema(ctx context.Context, path string) (map[string]model.AttributeDefinition, error) {
+	var paths []string
+	slugs := strings.Split(path, model.CategoryPathSeparator)
+	for i := range slugs {
+		paths = append(paths, strings.Join(slugs[:i+1], model.CategoryPathSeparator))
+	}
+
+	categories, err := s.categories.GetByPaths(ctx, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attribute schema: %w", err)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Depth < categories[j].Depth })
+
+	schema := make(map[string]model.AttributeDefinition)
+	for _, category := range categories {
+		for _, definition := range category.Attributes {
+			schema[definition.Name] = definition
+		}
+	}
+	return schema, nil
+}
+
+// validateAttributes checks product attributes against the schema of its
+// category and returns them in their stored form
+func (s *productService) validateAttributes(ctx context.Context, category string, attributes map[string]interface{}) (map[string]interface{}, error) {
+	schema, err := s.attributeSchema(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+
+	validated := make(map[string]interface{}, len(attributes))
+	for name, value := range attributes {
+		definition, ok := schema[name]
+		if !ok {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("attribute %s is not defined for category %s", name, category))
+		}
+		if validated[name], err = definition.Check(value); err != nil {
+			return nil, apperrors.ErrInvalidInput(err.Error())
+		}
+	}
+	for name, definition := range schema {
+		if _, ok := validated[name]; definition.Required && !ok {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("attribute %s is required for category %s", name, category))
+		}
+	}
+
+	if len(validated) == 0 {
+		return nil, nil
+	}
+	return validated, nil
+}
+
+// validateAttributeFilters checks the attribute filters of a listing
+func validateAttributeFilters(filters []model.AttributeFilter) error {
+	for i := range filters {
+		filter := &filters[i]
+		if filter.Op == "" {
+			filter.Op = model.AttributeEq
+		}
+		if !attributeNamePattern.MatchString(filter.Name) {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("invalid attribute name %q", filter.Name))
+		}
+		switch filter.Op {
+		case model.AttributeEq:
+		case model.AttributeMin, model.AttributeMax:
+			if _, err := strconv.ParseFloat(filter.Value, 64); err != nil {
+				return apperrors.ErrInvalidInput(fmt.Sprintf("attribute %s %s must be a number", filter.Name, filter.Op))
+			}
+		default:
+			return apperrors.ErrInvalidInput(fmt.Sprintf("unsupported attribute operator %q", filter.Op))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/validator"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// importBatchSize is how many rows are validated and written together
+const importBatchSize = 500
+
+// errImportBatchFailed rolls back a batch in which some products failed
+var errImportBatchFailed = errors.New("some products of the batch failed")
+
+// ImportRowSource yields the rows of an import one at a time, returning
+// io.EOF after the last one
+type ImportRowSource func() (*model.ImportRow, error)
+
+// productImport tracks the state of one bulk import across its batches
+type productImport struct {
+	service *productService
+	result  *model.ImportResult
+	// lines maps every SKU imported so far to the line that used it first
+	lines map[string]int
+	// categories caches the canonical path, or the rejection, of every
+	// category named by a row
+	categories map[string]importCategory
+}
+
+type importCategory struct {
+	path string
+	err  error
+	// attributesErr rejects new products, which have no attributes, when
+	// the category requires some
+	attributesErr error
+}
+
+// importedProduct is a valid row together with the product it becomes
+type importedProduct struct {
+	row      *model.ImportRow
+	product  *model.Product
+	existing *model.Product
+}
+
+// ImportProducts creates or updates products matched by SKU from rows,
+// writing them in batches. Invalid rows are reported and skipped. A dry run
+// validates every row without writing anything.
+func (s *productService) ImportProducts(ctx context.Context, next ImportRowSource, dryRun bool) (*model.ImportResult, error) {
+	imp := &productImport{
+		service:    s,
+		result:     &model.ImportResult{DryRun: dryRun},
+		lines:      make(map[string]int),
+		categories: make(map[string]importCategory),
+	}
+
+	batch := make([]*model.ImportRow, 0, importBatchSize)
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		imp.result.Total++
+		if row.Line == 0 {
+			row.Line = imp.result.Total
+		}
+		batch = append(batch, row)
+
+		if len(batch) == importBatchSize {
+			if err := imp.importBatch(ctx, batch); err != nil {
+				return nil, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := imp.importBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	return imp.result, nil
+}
+
+// importBatch validates a batch of rows against each other and the stored
+// catalog, then upserts the valid ones in one bulk write
+func (imp *productImport) importBatch(ctx context.Context, rows []*model.ImportRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	s := imp.service
+
+	valid := make([]*model.ImportRow, 0, len(rows))
+	skus := make([]string, 0, len(rows))
+	for _, row := range rows {
+		row.SKU = strings.TrimSpace(row.SKU)
+		if err := validator.ValidateSKU(row.SKU); err != nil {
+			imp.fail(row, err)
+			continue
+		}
+		if line, ok := imp.lines[row.SKU]; ok {
+			imp.fail(row, fmt.Errorf("duplicate SKU, first used on line %d", line))
+			continue
+		}
+		imp.lines[row.SKU] = row.Line
+		valid = append(valid, row)
+		skus = append(skus, row.SKU)
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	existing, err := s.repo.GetBySKUs(ctx, skus)
+	if err != nil {
+		return fmt.Errorf("failed to look up products: %w", err)
+	}
+	bySKU := make(map[string]*model.Product, len(existing))
+	for _, product := range existing {
+		bySKU[product.SKU] = product
+	}
+
+	// SKUs are unique across products and their variants, so a new product
+	// cannot take the SKU of a variant
+	var newSKUs []string
+	for _, sku := range skus {
+		if bySKU[sku] == nil {
+			newSKUs = append(newSKUs, sku)
+		}
+	}
+	variantSKUs := make(map[string]bool)
+	if len(newSKUs) > 0 {
+		taken, err := s.repo.SKUsInUse(ctx, newSKUs, nil)
+		if err != nil {
+			return fmt.Errorf("failed to check SKUs: %w", err)
+		}
+		for _, sku := range taken {
+			variantSKUs[sku] = true
+		}
+	}
+
+	imported := make([]importedProduct, 0, len(valid))
+	for _, row := range valid {
+		if variantSKUs[row.SKU] {
+			imp.fail(row, fmt.Errorf("SKU %s is already used by a product variant", row.SKU))
+			continue
+		}
+
+		product, err := imp.buildProduct(ctx, row, bySKU[row.SKU])
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) {
+			imp.fail(row, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		imported = append(imported, importedProduct{row: row, product: product, existing: bySKU[row.SKU]})
+	}
+
+	if imp.result.DryRun {
+		for _, item := range imported {
+			if item.existing != nil {
+				imp.result.Updated++
+			} else {
+				imp.result.Created++
+			}
+		}
+		return nil
+	}
+	if len(imported) == 0 {
+		return nil
+	}
+
+	return imp.write(ctx, imported)
+}
+
+// write stores imported products in one transaction. When some of them
+// fail the transaction is rolled back and every product is written on its
+// own, so only the failing ones are reported.
+func (imp *productImport) write(ctx context.Context, items []importedProduct) error {
+	inserted, failed, err := imp.writeBatch(ctx, items)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 && len(items) > 1 {
+		for _, item := range items {
+			if err := imp.write(ctx, []importedProduct{item}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, item := range items {
+		if err, ok := failed[i]; ok {
+			imp.fail(item.row, err)
+			continue
+		}
+		imp.stored(ctx, item, inserted[i])
+	}
+	return nil
+}
+
+// writeBatch upserts products together with their stock ledger entries and
+// events, returning which products were inserted and which failed. Nothing
+// is written when any product fails.
+func (imp *productImport) writeBatch(ctx context.Context, items []importedProduct) (map[int]bool, map[int]error, error) {
+	s := imp.service
+	products := make([]*model.Product, len(items))
+	skus := make([]string, len(items))
+	for i, item := range items {
+		products[i] = item.product
+		skus[i] = item.row.SKU
+	}
+
+	var inserted map[int]bool
+	var failed map[int]error
+	err := s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		var err error
+		inserted, failed, err = s.repo.UpsertBySKU(ctx, products)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write products: %w", err)
+		}
+		if len(failed) > 0 {
+			return nil, errImportBatchFailed
+		}
+
+		created := make(map[string]bool)
+		for i, item := range items {
+			switch {
+			case inserted[i]:
+				created[item.row.SKU] = true
+				if err := s.inventory.OpenLedger(ctx, item.product); err != nil {
+					return nil, fmt.Errorf("failed to open stock ledger of product %s: %w", item.row.SKU, err)
+				}
+			case item.existing != nil && item.row.Quantity != nil && *item.row.Quantity != item.existing.Quantity:
+				if _, err := s.inventory.SetStock(ctx, item.product.ID, *item.row.Quantity, "product import"); err != nil {
+					return nil, fmt.Errorf("failed to import stock of product %s: %w", item.row.SKU, err)
+				}
+			}
+		}
+
+		// Products created by someone else since the batch was read are
+		// only known by SKU, so events carry the products as stored
+		stored, err := s.repo.GetBySKUs(ctx, skus)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load imported products: %w", err)
+		}
+		recorded := make([]*events.Event, 0, len(stored))
+		for _, product := range stored {
+			eventType := events.ProductUpdated
+			if created[product.SKU] {
+				eventType = events.ProductCreated
+			}
+			event, err := events.New(ctx, eventType, product.ID.Hex(), product)
+			if err != nil {
+				return nil, err
+			}
+			recorded = append(recorded, event)
+		}
+		return recorded, nil
+	})
+	if errors.Is(err, errImportBatchFailed) {
+		return inserted, failed, nil
+	}
+	return inserted, failed, err
+}
+
+// buildProduct turns a row into the product to store, merged over the
+// existing product with its SKU
+func (imp *productImport) buildProduct(ctx context.Context, row *model.ImportRow, existing *model.Product) (*model.Product, error) {
+	s := imp.service
+
+	name := strings.TrimSpace(row.Name)
+	if name == "" {
+		return nil, apperrors.ErrInvalidInput("name is required")
+	}
+	currency := row.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	price, err := money.Parse(row.Price, money.NormalizeCurrency(currency))
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("invalid price: %v", err))
+	}
+	if err := validatePrice(&price); err != nil {
+		return nil, err
+	}
+	if row.Quantity != nil && *row.Quantity < 0 {
+		return nil, apperrors.ErrInvalidInput("quantity cannot be negative")
+	}
+	if row.ReorderThreshold != nil && *row.ReorderThreshold < 0 {
+		return nil, apperrors.ErrInvalidInput("reorder threshold cannot be negative")
+	}
+	category, err := imp.resolveCategory(ctx, row.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	var product model.Product
+	if existing != nil {
+		product = *existing
+		if row.Status != "" && row.Status != existing.CurrentStatus() {
+			return nil, apperrors.ErrInvalidInput("status can only be set for new products")
+		}
+		// Kept attributes must fit the schema of a new category
+		if category.path != existing.Category {
+			if _, err := s.validateAttributes(ctx, category.path, existing.Attributes); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		product = model.Product{ID: primitive.NewObjectID(), SKU: row.SKU, Status: row.Status}
+		if product.Status == "" {
+			product.Status = model.StatusDraft
+		}
+		if product.Status != model.StatusDraft && product.Status != model.StatusPublished {
+			return nil, apperrors.ErrInvalidInput("a new product must be a draft or published")
+		}
+		if product.Status == model.StatusPublished {
+			now := time.Now()
+			product.PublishedAt = &now
+		}
+		if category.attributesErr != nil {
+			return nil, category.attributesErr
+		}
+	}
+
+	product.Name = name
+	if row.Description != "" {
+		product.Description = row.Description
+	}
+	product.Price = price
+	product.Category = category.path
+	if row.Quantity != nil {
+		product.Quantity = *row.Quantity
+	}
+	if row.ReorderThreshold != nil {
+		product.ReorderThreshold = *row.ReorderThreshold
+	}
+	// A new base price can clash with a price list entry
+	if err := validatePriceLists(product.Price, product.PriceLists); err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// resolveCategory resolves the category of a row, looking every category up
+// once per import
+func (imp *productImport) resolveCategory(ctx context.Context, path string) (importCategory, error) {
+	key := model.NormalizeCategoryPath(path)
+	if cached, ok := imp.categories[key]; ok {
+		return cached, cached.err
+	}
+
+	var category importCategory
+	var appErr *apperrors.AppError
+	category.path, category.err = imp.service.resolveCategory(ctx, path)
+	if category.err != nil && !errors.As(category.err, &appErr) {
+		return category, category.err
+	}
+	if category.err == nil {
+		_, category.attributesErr = imp.service.validateAttributes(ctx, category.path, nil)
+		if category.attributesErr != nil && !errors.As(category.attributesErr, &appErr) {
+			return category, category.attributesErr
+		}
+	}
+
+	imp.categories[key] = category
+	return category, category.err
+}
+
+// stored finishes a written product once its transaction committed
+func (imp *productImport) stored(ctx context.Context, item importedProduct, inserted bool) {
+	s := imp.service
+	product := item.product
+
+	switch {
+	case inserted:
+		imp.result.Created++
+	case item.existing == nil:
+		// Created by someone else since the batch was read; the row updated
+		// it but its ID and stock are unknown here
+		imp.result.Updated++
+		return
+	default:
+		imp.result.Updated++
+		s.cache.Delete(ctx, fmt.Sprintf("product:%s", product.ID.Hex()))
+	}
+
+	s.checkStockLevel(ctx, product)
+	s.indexSuggestions(ctx, product)
+}
+
+func (imp *productImport) fail(row *model.ImportRow, err error) {
+	imp.result.Failed++
+	imp.result.Errors = append(imp.result.Errors, model.ImportRowError{
+		Line:    row.Line,
+		SKU:     row.SKU,
+		Message: err.Error(),
+	})
+}
@@ -2,13 +2,49 @@ package service
 
 import (
 	"context"
+	"time"
+
 	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
 )
 
 type ProductService interface {
 	CreateProduct(ctx context.Context, req *model.CreateProductRequest) (*model.Product, error)
-	GetProduct(ctx context.Context, id string) (*model.Product, error)
+	GetProduct(ctx context.Context, id string, params *model.GetProductParams) (*model.Product, error)
+	BatchGetProducts(ctx context.Context, ids []string, params *model.GetProductParams) ([]*model.Product, []string, error)
 	UpdateProduct(ctx context.Context, id string, req *model.UpdateProductRequest) (*model.Product, error)
 	DeleteProduct(ctx context.Context, id string) error
-	ListProducts(ctx context.Context, page, limit int, search, category string) ([]*model.Product, int64, error)
+	ListProducts(ctx context.Context, params *model.ListProductsParams) ([]*model.Product, *pagination.PageInfo, error)
+	ExportProducts(ctx context.Context, params *model.ListProductsParams, fn func(*model.Product) error) error
+	WatchProducts(ctx context.Context, params *model.WatchProductsParams, fn func(*model.ProductChange) error) error
+	SearchProducts(ctx context.Context, params *model.SearchProductsParams) (*model.SearchResult, error)
+	SuggestProducts(ctx context.Context, prefix string, limit int) ([]*model.Suggestion, error)
+	ImportProducts(ctx context.Context, next ImportRowSource, dryRun bool) (*model.ImportResult, error)
+
+	// Lifecycle operations
+	PublishProduct(ctx context.Context, id string, at *time.Time) (*model.Product, error)
+	ArchiveProduct(ctx context.Context, id string, at *time.Time) (*model.Product, error)
+	ProcessScheduledTransitions(ctx context.Context) (int, error)
+
+	// Media operations
+	AddProductMedia(ctx context.Context, productID string, media *model.Media) (*model.Product, error)
+	DeleteProductMedia(ctx context.Context, productID, mediaID string) (*model.Product, error)
+	ProcessThumbnails(ctx context.Context) (int, error)
+
+	// Category operations
+	CreateCategory(ctx context.Context, req *model.CreateCategoryRequest) (*model.Category, error)
+	GetCategory(ctx context.Context, id string) (*model.Category, error)
+	UpdateCategory(ctx context.Context, id string, req *model.UpdateCategoryRequest) (*model.Category, error)
+	DeleteCategory(ctx context.Context, id string) error
+	ListCategories(ctx context.Context, params *model.ListCategoriesParams) ([]*model.Category, error)
+
+	// Inventory operations
+	AdjustStock(ctx context.Context, id string, req *model.AdjustStockRequest) (*model.Product, error)
+	ReserveStock(ctx context.Context, req *model.ReserveStockRequest) (*model.Reservation, error)
+	ReleaseStock(ctx context.Context, reservationID string) (*model.Reservation, error)
+	CommitReservation(ctx context.Context, reservationID string) (*model.Reservation, error)
+	ReleaseExpiredReservations(ctx context.Context) (int, error)
+	ListStockMovements(ctx context.Context, params *model.ListStockMovementsParams) ([]*model.StockMovement, string, error)
+	ReconcileStock(ctx context.Context) ([]*model.StockDrift, error)
+	ListLowStockProducts(ctx context.Context, page, limit int) ([]*model.Product, int64, error)
 }
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/notifier"
+	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/logger"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+)
+
+// expiryBatch bounds how many expired reservations are listed at once
+const expiryBatch = 100
+
+func (s *productService) AdjustStock(ctx context.Context, id string, req *model.AdjustStockRequest) (*model.Product, error) {
+	productID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput("invalid product id")
+	}
+	variantID, err := parseVariantID(req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Delta == 0 {
+		return nil, apperrors.ErrInvalidInput("delta must not be zero")
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = model.ReasonAdjustment
+	}
+	switch {
+	case !model.AdjustmentReasons[reason]:
+		return nil, apperrors.ErrInvalidInput("reason must be one of sale, restock or adjustment")
+	case reason == model.ReasonSale && req.Delta > 0:
+		return nil, apperrors.ErrInvalidInput("a sale must remove stock")
+	case reason == model.ReasonRestock && req.Delta < 0:
+		return nil, apperrors.ErrInvalidInput("a restock must add stock")
+	}
+
+	var product *model.Product
+	err = s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		var err error
+		product, err = s.inventory.AdjustStock(ctx, productID, variantID, req.Delta, reason, req.Note)
+		if err != nil {
+			return nil, fmt.Errorf("failed to adjust stock: %w", err)
+		}
+		return productEvent(ctx, events.ProductUpdated, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(ctx, fmt.Sprintf("product:%s", id), product, 3600)
+	s.checkStockLevel(ctx, product)
+
+	return product, nil
+}
+
+func (s *productService) ReserveStock(ctx context.Context, req *model.ReserveStockRequest) (*model.Reservation, error) {
+	if len(req.Items) == 0 {
+		return nil, apperrors.ErrInvalidInput("at least one item is required")
+	}
+
+	ttl := req.TTL
+	if ttl <= 0 {
+		ttl = model.DefaultReservationTTL
+	}
+	if ttl > model.MaxReservationTTL {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("reservation ttl cannot exceed %s", model.MaxReservationTTL))
+	}
+
+	// Merge repeated products and variants so each one is decremented
+	// exactly once
+	type stockKey struct {
+		product primitive.ObjectID
+		variant primitive.ObjectID
+	}
+	var items []model.StockItem
+	positions := make(map[stockKey]int)
+	for _, item := range req.Items {
+		productID, err := primitive.ObjectIDFromHex(item.ProductID)
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("invalid product id %q", item.ProductID))
+		}
+		variantID, err := parseVariantID(item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		if item.Quantity <= 0 {
+			return nil, apperrors.ErrInvalidInput("item quantity must be positive")
+		}
+
+		key := stockKey{product: productID}
+		if variantID != nil {
+			key.variant = *variantID
+		}
+		if i, ok := positions[key]; ok {
+			items[i].Quantity += item.Quantity
+			continue
+		}
+		positions[key] = len(items)
+		items = append(items, model.StockItem{ProductID: productID, VariantID: variantID, Quantity: item.Quantity})
+	}
+
+	reservation := &model.Reservation{
+		Items:     items,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if req.ID != "" {
+		id, err := primitive.ObjectIDFromHex(req.ID)
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput("invalid reservation id")
+		}
+		reservation.ID = id
+	}
+	var products []*model.Product
+	err := s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		var err error
+		products, err = s.inventory.Reserve(ctx, reservation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve stock: %w", err)
+		}
+		return stockEvents(ctx, products)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.stockChanged(ctx, products)
+
+	return reservation, nil
+}
+
+func (s *productService) ReleaseStock(ctx context.Context, reservationID string) (*model.Reservation, error) {
+	id, err := primitive.ObjectIDFromHex(reservationID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput("invalid reservation id")
+	}
+
+	return s.releaseReservation(ctx, id)
+}
+
+// releaseReservation hands the stock of a reservation back, with the events
+// of the products it restocked
+func (s *productService) releaseReservation(ctx context.Context, id primitive.ObjectID) (*model.Reservation, error) {
+	var reservation *model.Reservation
+	var products []*model.Product
+	err := s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		var err error
+		reservation, products, err = s.inventory.Release(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to release reservation: %w", err)
+		}
+		return stockEvents(ctx, products)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range reservation.Unreturned {
+		logger.Warnf("Reservation %s could not hand %d back to product %s, which no longer exists",
+			id.Hex(), item.Quantity, item.ProductID.Hex())
+	}
+	s.stockChanged(ctx, products)
+
+	return reservation, nil
+}
+
+func (s *productService) CommitReservation(ctx context.Context, reservationID string) (*model.Reservation, error) {
+	id, err := primitive.ObjectIDFromHex(reservationID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput("invalid reservation id")
+	}
+
+	reservation, err := s.inventory.Commit(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit reservation: %w", err)
+	}
+
+	return reservation, nil
+}
+
+func (s *productService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	now := time.Now()
+	released, failed := 0, 0
+	var after primitive.ObjectID
+	for {
+		expired, err := s.inventory.ListExpired(ctx, now, after, expiryBatch)
+		if err != nil {
+			return released, fmt.Errorf("failed to list expired reservations: %w", err)
+		}
+
+		// Every reservation is released in its own transaction, so one that
+		// fails is retried by the next sweep without holding up the others
+		for _, id := range expired {
+			_, err := s.releaseReservation(ctx, id)
+			switch {
+			case errors.Is(err, model.ErrReservationClosed):
+				// Released or committed since it was listed
+			case err != nil:
+				failed++
+				logger.Warnf("Failed to release expired reservation %s: %v", id.Hex(), err)
+			default:
+				released++
+			}
+		}
+
+		if len(expired) < expiryBatch {
+			break
+		}
+		after = expired[len(expired)-1]
+	}
+
+	if failed > 0 {
+		return released, fmt.Errorf("failed to release %d expired reservations", failed)
+	}
+	return released, nil
+}
+
+func (s *productService) ListStockMovements(ctx context.Context, params *model.ListStockMovementsParams) ([]*model.StockMovement, string, error) {
+	productID, err := primitive.ObjectIDFromHex(params.ProductID)
+	if err != nil {
+		return nil, "", apperrors.ErrInvalidInput("invalid product id")
+	}
+	variantID, err := parseVariantID(params.VariantID)
+	if err != nil {
+		return nil, "", err
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 50
+	}
+	if params.From != nil && params.To != nil && !params.From.Before(*params.To) {
+		return nil, "", apperrors.ErrInvalidInput("from must be before to")
+	}
+
+	movements, nextPageToken, err := s.inventory.ListMovements(ctx, productID, variantID, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list stock movements: %w", err)
+	}
+
+	return movements, nextPageToken, nil
+}
+
+func (s *productService) ReconcileStock(ctx context.Context) ([]*model.StockDrift, error) {
+	drifted, err := s.inventory.Reconcile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile stock: %w", err)
+	}
+
+	return drifted, nil
+}
+
+func (s *productService) ListLowStockProducts(ctx context.Context, page, limit int) ([]*model.Product, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	products, total, err := s.repo.ListLowStock(ctx, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list low stock products: %w", err)
+	}
+
+	return products, total, nil
+}
+
+// stockEvents records the state of products after their stock changed
+func stockEvents(ctx context.Context, products []*model.Product) ([]*events.Event, error) {
+	recorded := make([]*events.Event, 0, len(products))
+	for _, product := range products {
+		event, err := events.New(ctx, events.ProductUpdated, product.ID.Hex(), product)
+		if err != nil {
+			return nil, err
+		}
+		recorded = append(recorded, event)
+	}
+	return recorded, nil
+}
+
+// stockChanged drops cached copies of products whose quantity changed and
+// re-evaluates their stock level
+func (s *productService) stockChanged(ctx context.Context, products []*model.Product) {
+	for _, product := range products {
+		if err := s.cache.Delete(ctx, fmt.Sprintf("product:%s", product.ID.Hex())); err != nil {
+			logger.Warnf("Failed to invalidate cached product %s: %v", product.ID.Hex(), err)
+		}
+		s.checkStockLevel(ctx, product)
+	}
+}
+
+// checkStockLevel raises a low-stock alert when a product is below its
+// reorder threshold and resolves it once the stock is replenished. Open
+// alerts are de-duplicated, so a shortage is only reported once.
+func (s *productService) checkStockLevel(ctx context.Context, product *model.Product) {
+	if product.IsLowStock() {
+		alert := &model.StockAlert{
+			ProductID:        product.ID,
+			SKU:              product.SKU,
+			Name:             product.Name,
+			Quantity:         product.Quantity,
+			ReorderThreshold: product.ReorderThreshold,
+		}
+
+		opened, err := s.inventory.OpenAlert(ctx, alert)
+		if err != nil {
+			logger.Errorf("Failed to record low stock alert for product %s: %v", product.ID.Hex(), err)
+			return
+		}
+		if opened {
+			s.notify(model.EventStockLow, alert)
+		}
+		return
+	}
+
+	alert, err := s.inventory.ResolveAlert(ctx, product.ID, product.Quantity)
+	if err != nil {
+		logger.Errorf("Failed to resolve low stock alert for product %s: %v", product.ID.Hex(), err)
+		return
+	}
+	if alert != nil {
+		s.notify(model.EventStockReplenished, alert)
+	}
+}
+
+// notify delivers an event in the background so slow notifiers never hold
+// up stock changes
+func (s *productService) notify(eventType string, data interface{}) {
+	event := notifier.Event{
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.notifier.Notify(ctx, event); err != nil {
+			logger.Errorf("Failed to deliver %s notification: %v", eventType, err)
+		}
+	}()
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/logger"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+)
+
+// scheduledTransitionBatch bounds how many due products one sweep moves
+const scheduledTransitionBatch = 100
+
+// PublishProduct publishes a product now, or schedules a draft to be
+// published when at lies in the future
+func (s *productService) PublishProduct(ctx context.Context, id string, at *time.Time) (*model.Product, error) {
+	product, err := s.loadProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	current := product.CurrentStatus()
+
+	if at == nil || !at.After(time.Now()) {
+		if current == model.StatusPublished {
+			return product, nil
+		}
+		if err := s.setStatus(ctx, product, model.StatusPublished); err != nil {
+			return nil, err
+		}
+		return product, nil
+	}
+
+	if current != model.StatusDraft {
+		return nil, fmt.Errorf("%w: only drafts can be scheduled for publishing, product is %s", model.ErrInvalidTransition, current)
+	}
+	if product.UnpublishAt != nil && !product.UnpublishAt.After(*at) {
+		return nil, apperrors.ErrInvalidInput("publish time must be before the scheduled unpublish time")
+	}
+	product.PublishAt = at
+	if err := s.saveStatus(ctx, product, current); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// ArchiveProduct archives a product now, or schedules a published or draft
+// product to be unpublished when at lies in the future
+func (s *productService) ArchiveProduct(ctx context.Context, id string, at *time.Time) (*model.Product, error) {
+	product, err := s.loadProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	current := product.CurrentStatus()
+
+	if at == nil || !at.After(time.Now()) {
+		if current == model.StatusArchived {
+			return product, nil
+		}
+		if err := s.setStatus(ctx, product, model.StatusArchived); err != nil {
+			return nil, err
+		}
+		return product, nil
+	}
+
+	if current == model.StatusArchived {
+		return nil, fmt.Errorf("%w: product is already archived", model.ErrInvalidTransition)
+	}
+	if product.PublishAt != nil && !at.After(*product.PublishAt) {
+		return nil, apperrors.ErrInvalidInput("unpublish time must be after the scheduled publish time")
+	}
+	product.UnpublishAt = at
+	if err := s.saveStatus(ctx, product, current); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// ProcessScheduledTransitions publishes and archives the products whose
+// scheduled time has passed, returning how many moved
+func (s *productService) ProcessScheduledTransitions(ctx context.Context) (int, error) {
+	now := time.Now()
+	products, err := s.repo.ListDueTransitions(ctx, now, scheduledTransitionBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list scheduled transitions: %w", err)
+	}
+
+	moved := 0
+	for _, product := range products {
+		to := product.DueTransition(now)
+		if to == "" {
+			continue
+		}
+		err := s.setStatus(ctx, product, to)
+		switch {
+		case errors.Is(err, model.ErrProductChanged):
+			// Changed by someone else since it was listed, the next sweep
+			// picks it up again if it is still due
+			continue
+		case err != nil:
+			return moved, err
+		}
+		moved++
+		logger.Infof("Product %s moved to %s as scheduled", product.ID.Hex(), to)
+	}
+
+	return moved, nil
+}
+
+// setStatus moves a product to another lifecycle state right away,
+// dropping schedules that no longer apply
+func (s *productService) setStatus(ctx context.Context, product *model.Product, to string) error {
+	from := product.CurrentStatus()
+	if !model.CanTransition(from, to) {
+		return fmt.Errorf("%w: a %s product cannot become %s", model.ErrInvalidTransition, from, to)
+	}
+
+	product.Status = to
+	switch to {
+	case model.StatusPublished:
+		now := time.Now()
+		product.PublishedAt = &now
+		product.PublishAt = nil
+	case model.StatusArchived:
+		product.PublishAt = nil
+		product.UnpublishAt = nil
+	case model.StatusDraft:
+		product.UnpublishAt = nil
+	}
+
+	return s.saveStatus(ctx, product, from)
+}
+
+// saveStatus stores the lifecycle fields of a product, guarded by the status
+// it was read with, together with a product.updated event, and refreshes the
+// cache and suggestions
+func (s *productService) saveStatus(ctx context.Context, product *model.Product, from string) error {
+	err := s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		if err := s.repo.UpdateStatus(ctx, product, from); err != nil {
+			return nil, fmt.Errorf("failed to update product status: %w", err)
+		}
+		return productEvent(ctx, events.ProductUpdated, product)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.Set(ctx, fmt.Sprintf("product:%s", product.ID.Hex()), product, 3600)
+	s.indexSuggestions(ctx, product)
+
+	return nil
+}
+
+// loadProduct reads a product from the database, bypassing the cache, for
+// changes guarded by its current state
+func (s *productService) loadProduct(ctx context.Context, id string) (*model.Product, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, model.ErrProductNotFound
+	}
+	return product, nil
+}
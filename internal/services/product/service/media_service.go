@@ -0,0 +1,212 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/blobstore"
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/imaging"
+	"go-microservice-boilerplate/internal/services/product/model"
+	"go-microservice-boilerplate/internal/utils/logger"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+)
+
+// thumbnailBatch bounds how many products one thumbnail sweep looks at
+const thumbnailBatch = 20
+
+// errUnusableImage marks media whose thumbnail can never be generated
+var errUnusableImage = errors.New("unusable image")
+
+// AddProductMedia attaches an uploaded image to a product. The file must
+// already be stored under the media key prefix of the product; its thumbnail
+// is generated later by ProcessThumbnails.
+func (s *productService) AddProductMedia(ctx context.Context, productID string, media *model.Media) (*model.Product, error) {
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput("invalid product ID")
+	}
+	if err := validateMedia(productID, media); err != nil {
+		return nil, err
+	}
+
+	media.ID = primitive.NewObjectID()
+	media.URL = s.blobs.URL(media.Key)
+	media.ThumbnailKey = ""
+	media.ThumbnailURL = ""
+	media.ThumbnailStatus = model.ThumbnailPending
+	media.CreatedAt = time.Now()
+
+	var product *model.Product
+	err = s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		var err error
+		product, err = s.repo.AddMedia(ctx, objectID, media)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add product media: %w", err)
+		}
+		if product == nil {
+			return nil, model.ErrProductNotFound
+		}
+		return productEvent(ctx, events.ProductUpdated, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(ctx, fmt.Sprintf("product:%s", productID), product, 3600)
+	return product, nil
+}
+
+// DeleteProductMedia detaches an image from a product and deletes its files
+func (s *productService) DeleteProductMedia(ctx context.Context, productID, mediaID string) (*model.Product, error) {
+	product, err := s.loadProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	mediaObjectID, err := primitive.ObjectIDFromHex(mediaID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput("invalid media ID")
+	}
+	media := product.MediaItem(mediaObjectID)
+	if media == nil {
+		return nil, model.ErrMediaNotFound
+	}
+	removed := *media
+
+	err = s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		var err error
+		product, err = s.repo.RemoveMedia(ctx, product.ID, mediaObjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete product media: %w", err)
+		}
+		if product == nil {
+			// Deleted concurrently, the other request removes the files
+			return nil, model.ErrMediaNotFound
+		}
+		return productEvent(ctx, events.ProductUpdated, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Files are only deleted once the product no longer refers to them
+	s.cache.Set(ctx, fmt.Sprintf("product:%s", productID), product, 3600)
+	s.deleteMediaFiles(ctx, removed)
+
+	return product, nil
+}
+
+// ProcessThumbnails generates the thumbnails of media still waiting for one,
+// returning how many were generated
+func (s *productService) ProcessThumbnails(ctx context.Context) (int, error) {
+	products, err := s.repo.ListPendingThumbnails(ctx, thumbnailBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending thumbnails: %w", err)
+	}
+
+	generated := 0
+	for _, product := range products {
+		for i := range product.Media {
+			media := &product.Media[i]
+			if media.ThumbnailStatus != model.ThumbnailPending {
+				continue
+			}
+
+			err := s.generateThumbnail(ctx, media)
+			switch {
+			case errors.Is(err, errUnusableImage):
+				logger.Warnf("Thumbnail of media %s on product %s failed: %v", media.ID.Hex(), product.ID.Hex(), err)
+				media.ThumbnailStatus = model.ThumbnailFailed
+			case err != nil:
+				// The blob store may be unavailable, the next sweep retries
+				return generated, err
+			}
+
+			if err := s.repo.SetThumbnail(ctx, product.ID, media); err != nil {
+				return generated, fmt.Errorf("failed to save thumbnail: %w", err)
+			}
+			s.cache.Delete(ctx, fmt.Sprintf("product:%s", product.ID.Hex()))
+			if media.ThumbnailStatus == model.ThumbnailReady {
+				generated++
+			}
+		}
+	}
+
+	return generated, nil
+}
+
+// generateThumbnail stores a thumbnail next to the original file of media
+func (s *productService) generateThumbnail(ctx context.Context, media *model.Media) error {
+	original, err := s.blobs.Get(ctx, media.Key)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return fmt.Errorf("%w: original file is missing", errUnusableImage)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read original: %w", err)
+	}
+	defer original.Close()
+
+	thumbnail, size, err := imaging.JPEGThumbnail(original, s.thumbnailSize)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUnusableImage, err)
+	}
+
+	key := strings.TrimSuffix(media.Key, path.Ext(media.Key)) + "_thumb.jpg"
+	if err := s.blobs.Put(ctx, key, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+		return fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+
+	media.ThumbnailKey = key
+	media.ThumbnailURL = s.blobs.URL(key)
+	media.ThumbnailStatus = model.ThumbnailReady
+	media.Width = int32(size.X)
+	media.Height = int32(size.Y)
+	return nil
+}
+
+// deleteMediaFiles removes the original and thumbnail of media, failures
+// only leave orphaned files behind
+func (s *productService) deleteMediaFiles(ctx context.Context, media model.Media) {
+	for _, key := range []string{media.Key, media.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			logger.Warnf("Failed to delete media file %s: %v", key, err)
+		}
+	}
+}
+
+// validateMedia checks the metadata of an uploaded file
+func validateMedia(productID string, media *model.Media) error {
+	if media == nil {
+		return apperrors.ErrInvalidInput("media is required")
+	}
+	if _, ok := imaging.Extensions[media.ContentType]; !ok {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("unsupported media type %q", media.ContentType))
+	}
+	// Keys are confined to the product so media cannot point at files of
+	// another product
+	prefix := "products/" + productID + "/"
+	name := strings.TrimPrefix(media.Key, prefix)
+	if name == media.Key || name == "" || strings.Contains(name, "/") || path.Clean(media.Key) != media.Key {
+		return apperrors.ErrInvalidInput("media key must be a file below " + prefix)
+	}
+	if media.Size <= 0 {
+		return apperrors.ErrInvalidInput("media size must be positive")
+	}
+	if media.Width <= 0 || media.Height <= 0 {
+		return apperrors.ErrInvalidInput("media dimensions must be positive")
+	}
+	if len(media.Checksum) != 64 {
+		return apperrors.ErrInvalidInput("media checksum must be a hex encoded SHA-256")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go-microservice-boilerplate/internal/exchange"
+	"go-microservice-boilerplate/internal/services/product/model"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+	"go-microservice-boilerplate/pkg/money"
+)
+
+// priceListPattern restricts price list names to short lowercase slugs
+var priceListPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// validatePriceLists normalizes the price lists of a product and rejects
+// invalid names, invalid prices and more than one price per list and
+// currency. The base price counts as the retail price in its currency.
+func validatePriceLists(base money.Money, prices []model.ListPrice) error {
+	seen := map[string]bool{model.PriceListRetail + "/" + base.Currency: true}
+	for i := range prices {
+		price := &prices[i]
+		price.List = strings.ToLower(strings.TrimSpace(price.List))
+		if !priceListPattern.MatchString(price.List) {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("invalid price list name %q", price.List))
+		}
+		if err := validatePrice(&price.Price); err != nil {
+			return err
+		}
+
+		key := price.List + "/" + price.Price.Currency
+		if seen[key] {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("more than one %s %s price", price.Price.Currency, price.List))
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// validatePriceQuery normalizes a price query, defaulting to the retail list
+func validatePriceQuery(query *model.PriceQuery) error {
+	query.List = strings.ToLower(strings.TrimSpace(query.List))
+	if query.List == "" {
+		query.List = model.PriceListRetail
+	}
+	if !priceListPattern.MatchString(query.List) {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("invalid price list name %q", query.List))
+	}
+
+	query.Currency = money.NormalizeCurrency(query.Currency)
+	if query.Currency != "" && !money.Supported(query.Currency) {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("unsupported currency %q", query.Currency))
+	}
+	return nil
+}
+
+// priceProducts sets the display price of each product from the queried
+// list. A price stored in the requested currency is used as is, otherwise
+// the first price of the list is converted at the current exchange rate.
+// Products without a price in the list are left without a display price.
+func (s *productService) priceProducts(ctx context.Context, query *model.PriceQuery, products ...*model.Product) error {
+	rates := make(map[string]*exchange.Rate)
+	for _, product := range products {
+		prices := product.ListPrices(query.List)
+		if len(prices) == 0 {
+			continue
+		}
+
+		source := prices[0]
+		for _, price := range prices {
+			if price.Currency == query.Currency {
+				source = price
+				break
+			}
+		}
+
+		display := &model.DisplayPrice{
+			List:   query.List,
+			Price:  source,
+			Source: source,
+			Rate:   1,
+		}
+
+		if query.Currency != "" && source.Currency != query.Currency {
+			rate, ok := rates[source.Currency]
+			if !ok {
+				var err error
+				rate, err = s.rates.Rate(ctx, source.Currency, query.Currency)
+				if err != nil {
+					return fmt.Errorf("failed to convert %s prices to %s: %w", source.Currency, query.Currency, err)
+				}
+				rates[source.Currency] = rate
+			}
+
+			converted, err := source.Convert(query.Currency, rate.Value)
+			if err != nil {
+				return fmt.Errorf("failed to convert price of product %s: %w", product.ID.Hex(), err)
+			}
+
+			timestamp := rate.Timestamp
+			display.Price = converted
+			display.Converted = true
+			display.Rate = rate.Value
+			display.RateTimestamp = &timestamp
+		}
+
+		product.DisplayPrice = display
+	}
+	return nil
+}
@@ -3,28 +3,80 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/blobstore"
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/exchange"
+	"go-microservice-boilerplate/internal/notifier"
+	"go-microservice-boilerplate/internal/outbox"
 	"go-microservice-boilerplate/internal/services/product/model"
 	"go-microservice-boilerplate/internal/services/product/repository"
+	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+	"go-microservice-boilerplate/pkg/money"
 )
 
 type productService struct {
-	repo  repository.ProductRepository
-	cache repository.ProductCache
+	repo       repository.ProductRepository
+	cache      repository.ProductCache
+	suggest    repository.ProductSuggestIndex
+	inventory  repository.InventoryRepository
+	categories repository.CategoryRepository
+	notifier   notifier.Notifier
+	rates      exchange.Provider
+	blobs      blobstore.BlobStore
+	outbox     outbox.Outbox
+	// thumbnailSize is the longest side of generated thumbnails in pixels
+	thumbnailSize int
 }
 
-func NewProductService(repo repository.ProductRepository, cache repository.ProductCache) ProductService {
+func NewProductService(repo repository.ProductRepository, cache repository.ProductCache, suggest repository.ProductSuggestIndex, inventory repository.InventoryRepository, categories repository.CategoryRepository, notifier notifier.Notifier, rates exchange.Provider, blobs blobstore.BlobStore, outbox outbox.Outbox, thumbnailSize int) ProductService {
 	return &productService{
-		repo:  repo,
-		cache: cache,
+		repo:       repo,
+		cache:      cache,
+		suggest:    suggest,
+		inventory:  inventory,
+		categories: categories,
+		notifier:   notifier,
+		rates:      rates,
+		blobs:      blobs,
+		outbox:     outbox,
+
+		thumbnailSize: thumbnailSize,
 	}
 }
 
 func (s *productService) CreateProduct(ctx context.Context, req *model.CreateProductRequest) (*model.Product, error) {
-	// Check if product with same SKU already exists
-	existingProduct, err := s.repo.GetBySKU(ctx, req.SKU)
-	if err == nil && existingProduct != nil {
-		return nil, fmt.Errorf("product with SKU %s already exists", req.SKU)
+	if err := validatePrice(&req.Price); err != nil {
+		return nil, err
+	}
+	if err := validatePriceLists(req.Price, req.PriceLists); err != nil {
+		return nil, err
+	}
+
+	// New products are staged as drafts unless published straight away
+	status := req.Status
+	if status == "" {
+		status = model.StatusDraft
+	}
+	if status != model.StatusDraft && status != model.StatusPublished {
+		return nil, apperrors.ErrInvalidInput("a new product must be a draft or published")
+	}
+
+	category, err := s.resolveCategory(ctx, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := s.validateAttributes(ctx, category, req.Attributes)
+	if err != nil {
+		return nil, err
 	}
 
 	product := &model.Product{
@@ -32,22 +84,76 @@ func (s *productService) CreateProduct(ctx context.Context, req *model.CreatePro
 		Description: req.Description,
 		Price:       req.Price,
 		Quantity:    req.Quantity,
-		Category:    req.Category,
+		Category:    category,
 		SKU:         req.SKU,
+
+		ReorderThreshold: req.ReorderThreshold,
+		PriceLists:       req.PriceLists,
+		Attributes:       attributes,
+		Status:           status,
+	}
+	if status == model.StatusPublished {
+		now := time.Now()
+		product.PublishedAt = &now
+	}
+	if product.Variants, err = prepareVariants(product, req.Variants, nil); err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Create(ctx, product); err != nil {
-		return nil, fmt.Errorf("failed to create product: %w", err)
+	// SKUs are unique across products and their variants
+	if err := s.checkSKUs(ctx, product.SKUs(), nil); err != nil {
+		return nil, err
+	}
+
+	err = s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		if err := s.repo.Create(ctx, product); err != nil {
+			return nil, fmt.Errorf("failed to create product: %w", err)
+		}
+		if err := s.inventory.OpenLedger(ctx, product); err != nil {
+			return nil, fmt.Errorf("failed to open stock ledger: %w", err)
+		}
+		return productEvent(ctx, events.ProductCreated, product)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Cache the product
 	cacheKey := fmt.Sprintf("product:%s", product.ID.Hex())
 	s.cache.Set(ctx, cacheKey, product, 3600) // 1 hour
 
+	s.checkStockLevel(ctx, product)
+	s.indexSuggestions(ctx, product)
+
+	return product, nil
+}
+
+func (s *productService) GetProduct(ctx context.Context, id string, params *model.GetProductParams) (*model.Product, error) {
+	if params.Pricing != nil {
+		if err := validatePriceQuery(params.Pricing); err != nil {
+			return nil, err
+		}
+	}
+
+	product, err := s.getProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Unpublished products do not exist for the public
+	if !params.IncludeUnpublished && !product.IsPublished() {
+		return nil, model.ErrProductNotFound
+	}
+
+	if params.Pricing != nil {
+		if err := s.priceProducts(ctx, params.Pricing, product); err != nil {
+			return nil, err
+		}
+	}
+
 	return product, nil
 }
 
-func (s *productService) GetProduct(ctx context.Context, id string) (*model.Product, error) {
+func (s *productService) getProduct(ctx context.Context, id string) (*model.Product, error) {
 	// Try cache first
 	cacheKey := fmt.Sprintf("product:%s", id)
 	if product, err := s.cache.Get(ctx, cacheKey); err == nil && product != nil {
@@ -59,6 +165,9 @@ func (s *productService) GetProduct(ctx context.Context, id string) (*model.Prod
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
+	if product == nil {
+		return nil, model.ErrProductNotFound
+	}
 
 	// Cache the result
 	s.cache.Set(ctx, cacheKey, product, 3600)
@@ -67,64 +176,706 @@ func (s *productService) GetProduct(ctx context.Context, id string) (*model.Prod
 }
 
 func (s *productService) UpdateProduct(ctx context.Context, id string, req *model.UpdateProductRequest) (*model.Product, error) {
+	fields, err := productUpdateFields(req)
+	if err != nil {
+		return nil, err
+	}
+	update := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		update[field] = true
+	}
+
+	if update["quantity"] && *req.Quantity < 0 {
+		return nil, apperrors.ErrInvalidInput("quantity cannot be negative")
+	}
+	if update["reorder_threshold"] && *req.ReorderThreshold < 0 {
+		return nil, apperrors.ErrInvalidInput("reorder threshold cannot be negative")
+	}
+	if update["price"] {
+		if err := validatePrice(req.Price); err != nil {
+			return nil, err
+		}
+	}
+	if update["status"] && !model.ValidStatus(req.Status) {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("unsupported status %q", req.Status))
+	}
+
 	// Get existing product
-	product, err := s.GetProduct(ctx, id)
+	product, err := s.getProduct(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	categoryChanged := false
+	if update["category"] {
+		category, err := s.resolveCategory(ctx, req.Category)
+		if err != nil {
+			return nil, err
+		}
+		categoryChanged = category != product.Category
+		product.Category = category
+	}
+
+	// Attributes are checked against the schema of the new category, so
+	// moving a product revalidates the ones it keeps
+	attributes, attributesChanged := updatedAttributes(product.Attributes, req, fields)
+	if attributesChanged || categoryChanged {
+		if product.Attributes, err = s.validateAttributes(ctx, product.Category, attributes); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update fields
-	if req.Name != "" {
+	if update["name"] {
 		product.Name = req.Name
 	}
-	if req.Description != "" {
+	if update["description"] {
 		product.Description = req.Description
 	}
-	if req.Price > 0 {
-		product.Price = req.Price
+	if update["price"] {
+		product.Price = *req.Price
 	}
-	if req.Quantity >= 0 {
-		product.Quantity = req.Quantity
+	if update["reorder_threshold"] {
+		product.ReorderThreshold = *req.ReorderThreshold
 	}
-	if req.Category != "" {
-		product.Category = req.Category
+	if update["price_lists"] {
+		product.PriceLists = req.PriceLists
 	}
+	// A new base price can clash with a price list entry, so check them together
+	if err := validatePriceLists(product.Price, product.PriceLists); err != nil {
+		return nil, err
+	}
+	changeStatus := update["status"] && req.Status != product.CurrentStatus()
+	if changeStatus && !model.CanTransition(product.CurrentStatus(), req.Status) {
+		return nil, fmt.Errorf("%w: a %s product cannot become %s", model.ErrInvalidTransition, product.CurrentStatus(), req.Status)
+	}
+
+	// The writes of an update are applied together with their event
+	cacheKey := fmt.Sprintf("product:%s", id)
+	err = s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		if update["variants"] {
+			if err := s.replaceVariants(ctx, product, req.Variants); err != nil {
+				return nil, err
+			}
+		}
 
-	if err := s.repo.Update(ctx, id, product); err != nil {
-		return nil, fmt.Errorf("failed to update product: %w", err)
+		if err := s.repo.Update(ctx, id, product, storedFields(fields, categoryChanged)); err != nil {
+			return nil, fmt.Errorf("failed to update product: %w", err)
+		}
+
+		// Stock is set through the inventory so the change is recorded in the ledger
+		if update["quantity"] {
+			stocked, err := s.inventory.SetStock(ctx, product.ID, *req.Quantity, "product update")
+			if err != nil {
+				return nil, fmt.Errorf("failed to update product stock: %w", err)
+			}
+			product.Quantity = stocked.Quantity
+		}
+
+		// Status changes are guarded by the status the product was read
+		// with, and record the event of the whole update
+		if changeStatus {
+			return nil, s.setStatus(ctx, product, req.Status)
+		}
+		return productEvent(ctx, events.ProductUpdated, product)
+	})
+	if err != nil {
+		// A status change caches the product before the update is committed
+		s.cache.Delete(ctx, cacheKey)
+		return nil, err
 	}
 
 	// Update cache
-	cacheKey := fmt.Sprintf("product:%s", id)
 	s.cache.Set(ctx, cacheKey, product, 3600)
 
+	s.checkStockLevel(ctx, product)
+	s.indexSuggestions(ctx, product)
+
 	return product, nil
 }
 
+// productEvent records the state of a product after a change
+func productEvent(ctx context.Context, eventType string, product *model.Product) ([]*events.Event, error) {
+	event, err := events.New(ctx, eventType, product.ID.Hex(), product)
+	if err != nil {
+		return nil, err
+	}
+	return []*events.Event{event}, nil
+}
+
+// productUpdateFields returns the fields an update writes: those of its
+// mask, or without a mask the ones it sets. Masked fields without a value
+// are reset to their zero value.
+func productUpdateFields(req *model.UpdateProductRequest) ([]string, error) {
+	if len(req.Fields) == 0 {
+		var fields []string
+		add := func(field string, set bool) {
+			if set {
+				fields = append(fields, field)
+			}
+		}
+		add("name", req.Name != "")
+		add("description", req.Description != "")
+		add("price", req.Price != nil)
+		add("quantity", req.Quantity != nil)
+		add("category", req.Category != "")
+		add("reorder_threshold", req.ReorderThreshold != nil)
+		add("price_lists", req.PriceLists != nil)
+		add("variants", req.Variants != nil)
+		add("attributes", req.Attributes != nil)
+		add("status", req.Status != "")
+		return fields, nil
+	}
+
+	var zero int32
+	wholeAttributes, singleAttributes := false, false
+	for _, field := range req.Fields {
+		if name, ok := strings.CutPrefix(field, "attributes."); ok {
+			if name == "" {
+				return nil, apperrors.ErrInvalidInput(fmt.Sprintf("field %q cannot be updated", field))
+			}
+			singleAttributes = true
+			continue
+		}
+		if !slices.Contains(model.UpdatableFields, field) {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("field %q cannot be updated", field))
+		}
+
+		switch field {
+		case "name":
+			if strings.TrimSpace(req.Name) == "" {
+				return nil, apperrors.ErrInvalidInput("name cannot be empty")
+			}
+		case "price":
+			if req.Price == nil {
+				return nil, apperrors.ErrInvalidInput("price cannot be removed")
+			}
+		case "category":
+			if req.Category == "" {
+				return nil, apperrors.ErrInvalidInput("category cannot be empty")
+			}
+		case "status":
+			if req.Status == "" {
+				return nil, apperrors.ErrInvalidInput("status cannot be empty")
+			}
+		case "quantity":
+			if req.Quantity == nil {
+				req.Quantity = &zero
+			}
+		case "reorder_threshold":
+			if req.ReorderThreshold == nil {
+				req.ReorderThreshold = &zero
+			}
+		case "price_lists":
+			if req.PriceLists == nil {
+				req.PriceLists = []model.ListPrice{}
+			}
+		case "variants":
+			if req.Variants == nil {
+				req.Variants = []model.Variant{}
+			}
+		case "attributes":
+			wholeAttributes = true
+			if req.Attributes == nil {
+				req.Attributes = map[string]interface{}{}
+			}
+		}
+	}
+	if wholeAttributes && singleAttributes {
+		return nil, apperrors.ErrInvalidInput("attributes cannot be replaced and updated one by one at once")
+	}
+
+	return req.Fields, nil
+}
+
+// updatedAttributes applies the attribute fields of an update to current,
+// reporting whether they change anything
+func updatedAttributes(current map[string]interface{}, req *model.UpdateProductRequest, fields []string) (map[string]interface{}, bool) {
+	attributes, changed := current, false
+	for _, field := range fields {
+		if field == "attributes" {
+			return req.Attributes, true
+		}
+		name, ok := strings.CutPrefix(field, "attributes.")
+		if !ok {
+			continue
+		}
+		if !changed {
+			attributes = make(map[string]interface{}, len(current))
+			for key, value := range current {
+				attributes[key] = value
+			}
+			changed = true
+		}
+		if value, ok := req.Attributes[name]; ok {
+			attributes[name] = value
+		} else {
+			delete(attributes, name)
+		}
+	}
+	return attributes, changed
+}
+
+// storedFields narrows the fields of an update to the ones the product
+// document is written with. Stock, variants and status are written by their
+// own operations, and a new category revalidates every attribute.
+func storedFields(fields []string, categoryChanged bool) []string {
+	stored := make([]string, 0, len(fields)+1)
+	for _, field := range fields {
+		switch {
+		case field == "quantity", field == "variants", field == "status":
+		case categoryChanged && strings.HasPrefix(field, "attributes."):
+		default:
+			stored = append(stored, field)
+		}
+	}
+	if categoryChanged && !slices.Contains(stored, "attributes") {
+		stored = append(stored, "attributes")
+	}
+	return stored
+}
+
 func (s *productService) DeleteProduct(ctx context.Context, id string) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete product: %w", err)
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+
+	err = s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to delete product: %w", err)
+		}
+		if product == nil {
+			return nil, nil
+		}
+		event, err := events.New(ctx, events.ProductDeleted, id, events.Deleted{ID: id, SKU: product.SKU})
+		if err != nil {
+			return nil, err
+		}
+		return []*events.Event{event}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if product != nil {
+		for _, media := range product.Media {
+			s.deleteMediaFiles(ctx, media)
+		}
 	}
 
 	// Remove from cache
 	cacheKey := fmt.Sprintf("product:%s", id)
 	s.cache.Delete(ctx, cacheKey)
 
+	if err := s.suggest.Remove(ctx, id); err != nil {
+		logger.Warnf("Failed to remove product %s from suggestions: %v", id, err)
+	}
+
+	return nil
+}
+
+// BatchGetProducts returns the products with the given IDs in the order
+// requested, reading what it can from cache and the rest with a single
+// query, together with the IDs no product has. Unpublished products count
+// as missing unless params asks for them.
+func (s *productService) BatchGetProducts(ctx context.Context, ids []string, params *model.GetProductParams) ([]*model.Product, []string, error) {
+	ids, err := batchIDs(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	if params.Pricing != nil {
+		if err := validatePriceQuery(params.Pricing); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("product:%s", id)
+	}
+	cached, err := s.cache.GetMany(ctx, keys)
+	if err != nil {
+		cached = make([]*model.Product, len(ids))
+	}
+
+	found := make(map[string]*model.Product, len(ids))
+	var misses []string
+	for i, id := range ids {
+		if cached[i] != nil {
+			found[id] = cached[i]
+		} else {
+			misses = append(misses, id)
+		}
+	}
+
+	if len(misses) > 0 {
+		products, err := s.repo.GetByIDs(ctx, misses)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get products: %w", err)
+		}
+
+		// Backfill the cache with what the query found
+		backfill := make(map[string]*model.Product, len(products))
+		for _, product := range products {
+			found[product.ID.Hex()] = product
+			backfill[fmt.Sprintf("product:%s", product.ID.Hex())] = product
+		}
+		if len(backfill) > 0 {
+			s.cache.SetMany(ctx, backfill, 3600)
+		}
+	}
+
+	products := make([]*model.Product, 0, len(ids))
+	var missing []string
+	for _, id := range ids {
+		product, ok := found[id]
+		// Unpublished products do not exist for the public
+		if !ok || (!params.IncludeUnpublished && !product.IsPublished()) {
+			missing = append(missing, id)
+			continue
+		}
+		products = append(products, product)
+	}
+
+	if params.Pricing != nil {
+		if err := s.priceProducts(ctx, params.Pricing, products...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return products, missing, nil
+}
+
+// batchIDs validates the IDs of a batch get, dropping repeated ones
+func batchIDs(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, apperrors.ErrInvalidInput("at least one ID is required")
+	}
+
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		if !primitive.IsValidObjectID(id) {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("invalid ID %q", id))
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) > pagination.MaxBatchIDs {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("at most %d IDs can be fetched at once", pagination.MaxBatchIDs))
+	}
+
+	return unique, nil
+}
+
+func (s *productService) ListProducts(ctx context.Context, params *model.ListProductsParams) ([]*model.Product, *pagination.PageInfo, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.Limit > pagination.MaxLimit {
+		return nil, nil, apperrors.ErrInvalidInput(fmt.Sprintf("limit cannot exceed %d, export larger result sets instead", pagination.MaxLimit))
+	}
+	if err := prepareListParams(params); err != nil {
+		return nil, nil, err
+	}
+
+	products, pageInfo, err := s.repo.List(ctx, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list products: %w", err)
+	}
+
+	if params.Pricing != nil {
+		if err := s.priceProducts(ctx, params.Pricing, products...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return products, pageInfo, nil
+}
+
+// ExportProducts streams every product matching the list filters to fn, in
+// list order
+func (s *productService) ExportProducts(ctx context.Context, params *model.ListProductsParams, fn func(*model.Product) error) error {
+	if err := prepareListParams(params); err != nil {
+		return err
+	}
+
+	err := s.repo.Export(ctx, params, func(product *model.Product) error {
+		if params.Pricing != nil {
+			if err := s.priceProducts(ctx, params.Pricing, product); err != nil {
+				return err
+			}
+		}
+		return fn(product)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export products: %w", err)
+	}
+	return nil
+}
+
+// WatchProducts streams the changes of the products matching params to fn
+// until ctx is cancelled. A product that stops matching the filters, such
+// as one unpublished or moved to another category, is sent as a deletion.
+func (s *productService) WatchProducts(ctx context.Context, params *model.WatchProductsParams, fn func(*model.ProductChange) error) error {
+	if len(params.ProductIDs) > model.MaxWatchedProducts {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("at most %d products can be watched at once", model.MaxWatchedProducts))
+	}
+	for i, id := range params.ProductIDs {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return apperrors.ErrInvalidInput(fmt.Sprintf("invalid product ID %q", id))
+		}
+		params.ProductIDs[i] = objectID.Hex()
+	}
+	params.Category = model.NormalizeCategoryPath(params.Category)
+	params.CategoryTree = model.NormalizeCategoryPath(params.CategoryTree)
+
+	err := s.repo.Watch(ctx, params, func(change *model.ProductChange) error {
+		// Without the previous product, only watches that do not filter by
+		// its fields are known to have seen it
+		matchedBefore := !params.FiltersDocuments()
+		if change.Previous != nil {
+			matchedBefore = params.Matches(change.Previous)
+		}
+		change.Previous = nil
+		switch {
+		case change.Type == model.ChangeWatching:
+		case change.Product != nil && params.Matches(change.Product):
+		case matchedBefore && change.Type != model.ChangeCreated:
+			change.Type = model.ChangeDeleted
+			change.Product = nil
+			change.ChangedFields = nil
+		default:
+			return nil
+		}
+		return fn(change)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch products: %w", err)
+	}
 	return nil
 }
 
-func (s *productService) ListProducts(ctx context.Context, page, limit int, search, category string) ([]*model.Product, int64, error) {
-	if page <= 0 {
-		page = 1
+// prepareListParams normalizes and validates the filters shared by listing
+// and exporting products
+func prepareListParams(params *model.ListProductsParams) error {
+	params.Currency = resolveCurrency(params.Currency)
+	params.CategoryTree = model.NormalizeCategoryPath(params.CategoryTree)
+	if err := resolveVisibility(params); err != nil {
+		return err
+	}
+	params.SortBy, params.SortOrder = resolveSort(params.SortBy, params.SortOrder, params.Search)
+
+	if err := validateListParams(params); err != nil {
+		return err
 	}
-	if limit <= 0 {
+	if err := validateAttributeFilters(params.Attributes); err != nil {
+		return err
+	}
+	if params.Pricing != nil {
+		if err := validatePriceQuery(params.Pricing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *productService) SearchProducts(ctx context.Context, params *model.SearchProductsParams) (*model.SearchResult, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.Limit > pagination.MaxLimit {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("limit cannot exceed %d", pagination.MaxLimit))
+	}
+	if len(params.PriceBoundaries) == 0 {
+		params.PriceBoundaries = model.DefaultPriceBoundaries
+	}
+	params.Currency = resolveCurrency(params.Currency)
+	params.SortBy, params.SortOrder = resolveSort(params.SortBy, params.SortOrder, params.Query)
+
+	if err := validateSearchParams(params); err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.Search(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *productService) SuggestProducts(ctx context.Context, prefix string, limit int) ([]*model.Suggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, apperrors.ErrInvalidInput("a search prefix is required")
+	}
+	if limit <= 0 || limit > 20 {
 		limit = 10
 	}
 
-	products, total, err := s.repo.List(ctx, page, limit, search, category)
+	suggestions, err := s.suggest.Suggest(ctx, prefix, limit)
+	if err == nil && len(suggestions) > 0 {
+		return suggestions, nil
+	}
+	if err != nil {
+		logger.Warnf("Suggestion index unavailable, falling back to MongoDB: %v", err)
+	}
+
+	// Fall back to MongoDB and warm the index with whatever it finds
+	products, err := s.repo.SuggestByPrefix(ctx, prefix, limit)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list products: %w", err)
+		return nil, fmt.Errorf("failed to suggest products: %w", err)
 	}
 
-	return products, total, nil
+	suggestions = make([]*model.Suggestion, len(products))
+	for i, product := range products {
+		suggestions[i] = &model.Suggestion{ID: product.ID.Hex(), Name: product.Name, SKU: product.SKU}
+		s.indexSuggestions(ctx, product)
+	}
+
+	return suggestions, nil
+}
+
+// indexSuggestions refreshes the typeahead entry of a product. Failures only
+// degrade suggestions to the MongoDB fallback, so they are logged and ignored.
+func (s *productService) indexSuggestions(ctx context.Context, product *model.Product) {
+	// Suggestions are public, so unpublished products are taken out
+	if !product.IsPublished() {
+		if err := s.suggest.Remove(ctx, product.ID.Hex()); err != nil {
+			logger.Warnf("Failed to remove product %s from suggestions: %v", product.ID.Hex(), err)
+		}
+		return
+	}
+	if err := s.suggest.Index(ctx, product); err != nil {
+		logger.Warnf("Failed to index product %s for suggestions: %v", product.ID.Hex(), err)
+	}
+}
+
+// validatePrice normalizes the currency code of a price and rejects
+// unsupported currencies and negative amounts
+func validatePrice(price *money.Money) error {
+	price.Currency = money.NormalizeCurrency(price.Currency)
+	if err := price.Validate(); err != nil {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("invalid price: %v", err))
+	}
+	return nil
+}
+
+// resolveVisibility limits public listings to published products. Callers
+// that include unpublished products may filter by any status.
+func resolveVisibility(params *model.ListProductsParams) error {
+	if params.Status != "" && !model.ValidStatus(params.Status) {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("unsupported status %q", params.Status))
+	}
+	if params.IncludeUnpublished {
+		return nil
+	}
+	if params.Status != "" && params.Status != model.StatusPublished {
+		return apperrors.ErrInvalidInput("only published products can be listed")
+	}
+	params.Status = model.StatusPublished
+	return nil
+}
+
+// resolveCurrency defaults the currency that price filters are expressed in
+func resolveCurrency(currency string) string {
+	if currency = money.NormalizeCurrency(currency); currency == "" {
+		return money.DefaultCurrency
+	}
+	return currency
+}
+
+// resolveSort fills in the default ordering: relevance when searching and
+// newest first otherwise. Relevance and dates default to descending, every
+// other field to ascending.
+func resolveSort(sortBy, sortOrder, search string) (string, string) {
+	if sortBy == "" {
+		sortBy = "created_at"
+		if search != "" {
+			sortBy = "relevance"
+		}
+	}
+	if sortOrder == "" {
+		sortOrder = "asc"
+		if sortBy == "relevance" || sortBy == "created_at" || sortBy == "updated_at" {
+			sortOrder = "desc"
+		}
+	}
+	return sortBy, sortOrder
+}
+
+// validateListParams rejects sort keys outside the allowlist and empty ranges
+func validateListParams(params *model.ListProductsParams) error {
+	if _, ok := model.ProductSortFields[params.SortBy]; !ok {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("unsupported sort field %q", params.SortBy))
+	}
+	if params.SortOrder != "asc" && params.SortOrder != "desc" {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("unsupported sort order %q", params.SortOrder))
+	}
+	if params.SortBy == "relevance" {
+		if params.Search == "" {
+			return apperrors.ErrInvalidInput("sorting by relevance requires a search term")
+		}
+		if params.SortOrder != "desc" {
+			return apperrors.ErrInvalidInput("relevance can only be sorted in descending order")
+		}
+		if params.PageToken != "" {
+			return apperrors.ErrInvalidInput("relevance ordered results only support page-based pagination")
+		}
+	}
+	if !money.Supported(params.Currency) {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("unsupported currency %q", params.Currency))
+	}
+	if (params.MinPrice != nil && *params.MinPrice < 0) || (params.MaxPrice != nil && *params.MaxPrice < 0) {
+		return apperrors.ErrInvalidInput("price filters cannot be negative")
+	}
+	if params.MinPrice != nil && params.MaxPrice != nil && *params.MinPrice > *params.MaxPrice {
+		return apperrors.ErrInvalidInput("min_price must not exceed max_price")
+	}
+	if params.MinQuantity != nil && params.MaxQuantity != nil && *params.MinQuantity > *params.MaxQuantity {
+		return apperrors.ErrInvalidInput("min_quantity must not exceed max_quantity")
+	}
+	if params.CreatedAfter != nil && params.CreatedBefore != nil && !params.CreatedAfter.Before(*params.CreatedBefore) {
+		return apperrors.ErrInvalidInput("created_after must be before created_before")
+	}
+
+	return nil
+}
+
+// validateSearchParams applies the listing rules to a faceted search and
+// checks the price bucket boundaries
+func validateSearchParams(params *model.SearchProductsParams) error {
+	if err := validateListParams(&model.ListProductsParams{
+		Search:    params.Query,
+		SortBy:    params.SortBy,
+		SortOrder: params.SortOrder,
+		MinPrice:  params.MinPrice,
+		MaxPrice:  params.MaxPrice,
+		Currency:  params.Currency,
+	}); err != nil {
+		return err
+	}
+
+	if len(params.PriceBoundaries) < 2 {
+		return apperrors.ErrInvalidInput("at least two price boundaries are required")
+	}
+	for i := 1; i < len(params.PriceBoundaries); i++ {
+		if params.PriceBoundaries[i] <= params.PriceBoundaries[i-1] {
+			return apperrors.ErrInvalidInput("price boundaries must be strictly ascending")
+		}
+	}
+
+	return nil
 }
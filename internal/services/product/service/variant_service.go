@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/services/product/model"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+)
+
+// prepareVariants validates requested variants against the product and
+// assigns their IDs and stock. Variants matching an existing one by ID or
+// SKU keep its ID and stock, which only changes through stock adjustments.
+func prepareVariants(product *model.Product, requested []model.Variant, existing []model.Variant) ([]model.Variant, error) {
+	byID := make(map[primitive.ObjectID]model.Variant, len(existing))
+	bySKU := make(map[string]model.Variant, len(existing))
+	for _, variant := range existing {
+		byID[variant.ID] = variant
+		bySKU[variant.SKU] = variant
+	}
+
+	skus := map[string]bool{product.SKU: true}
+	optionSets := make(map[string]bool, len(requested))
+	variants := make([]model.Variant, len(requested))
+	for i, variant := range requested {
+		variant.SKU = strings.TrimSpace(variant.SKU)
+		if variant.SKU == "" {
+			return nil, apperrors.ErrInvalidInput("every variant needs a SKU")
+		}
+		if skus[variant.SKU] {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("SKU %s is used more than once", variant.SKU))
+		}
+		skus[variant.SKU] = true
+
+		if len(variant.Options) == 0 {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("variant %s needs at least one option", variant.SKU))
+		}
+		key, err := optionSetKey(variant.Options)
+		if err != nil {
+			return nil, err
+		}
+		if optionSets[key] {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("more than one variant has the options %s", key))
+		}
+		optionSets[key] = true
+
+		if variant.Price != nil {
+			if err := validatePrice(variant.Price); err != nil {
+				return nil, err
+			}
+		}
+		if variant.Quantity < 0 {
+			return nil, apperrors.ErrInvalidInput("variant quantity cannot be negative")
+		}
+
+		current, ok := byID[variant.ID]
+		if !ok {
+			current, ok = bySKU[variant.SKU]
+		}
+		if ok {
+			variant.ID = current.ID
+			variant.Quantity = current.Quantity
+		} else {
+			variant.ID = primitive.NewObjectID()
+		}
+
+		variants[i] = variant
+	}
+
+	return variants, nil
+}
+
+// optionSetKey renders variant options in a stable order, e.g.
+// "color=red, size=M", rejecting empty names and values
+func optionSetKey(options map[string]string) (string, error) {
+	names := make([]string, 0, len(options))
+	for name, value := range options {
+		if strings.TrimSpace(name) == "" || strings.TrimSpace(value) == "" {
+			return "", apperrors.ErrInvalidInput("variant options need a name and a value")
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + options[name]
+	}
+	return strings.Join(pairs, ", "), nil
+}
+
+// checkSKUs rejects SKUs already used by another product or variant
+func (s *productService) checkSKUs(ctx context.Context, skus []string, exclude *primitive.ObjectID) error {
+	taken, err := s.repo.SKUsInUse(ctx, skus, exclude)
+	if err != nil {
+		return fmt.Errorf("failed to check SKUs: %w", err)
+	}
+	if len(taken) > 0 {
+		return apperrors.ErrAlreadyExists(fmt.Sprintf("product with SKU %s", strings.Join(taken, ", ")))
+	}
+	return nil
+}
+
+// replaceVariants swaps the variants of a product, failing with
+// ErrProductChanged when its variants or their stock changed since the
+// product was read. It runs in the transaction of the update, whose reads
+// cannot see a newer product, so the conflict is returned rather than retried.
+func (s *productService) replaceVariants(ctx context.Context, product *model.Product, requested []model.Variant) error {
+	variants, err := prepareVariants(product, requested, product.Variants)
+	if err != nil {
+		return err
+	}
+
+	var added []model.Variant
+	var newSKUs []string
+	for _, variant := range variants {
+		if product.Variant(variant.ID) == nil {
+			added = append(added, variant)
+			newSKUs = append(newSKUs, variant.SKU)
+		}
+	}
+	if len(newSKUs) > 0 {
+		if err := s.checkSKUs(ctx, newSKUs, &product.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.ReplaceVariants(ctx, product.ID, product.Variants, variants); err != nil {
+		return fmt.Errorf("failed to update variants: %w", err)
+	}
+	if err := s.inventory.OpenVariantLedgers(ctx, product.ID, added); err != nil {
+		return fmt.Errorf("failed to open stock ledgers: %w", err)
+	}
+
+	product.Variants = variants
+	return nil
+}
+
+// parseVariantID parses an optional variant ID
+func parseVariantID(id string) (*primitive.ObjectID, error) {
+	if id == "" {
+		return nil, nil
+	}
+	variantID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("invalid variant id %q", id))
+	}
+	return &variantID, nil
+}
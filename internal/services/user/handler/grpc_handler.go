@@ -2,7 +2,10 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"net/http"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -10,6 +13,8 @@ import (
 	"go-microservice-boilerplate/internal/proto/user"
 	"go-microservice-boilerplate/internal/services/user/model"
 	"go-microservice-boilerplate/internal/services/user/service"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	apperrors "go-microservice-boilerplate/pkg/errors"
 )
 
 type UserGRPCHandler struct {
@@ -74,22 +79,53 @@ func (h *UserGRPCHandler) GetUser(ctx context.Context, req *user.GetUserRequest)
 	}, nil
 }
 
+func (h *UserGRPCHandler) BatchGetUsers(ctx context.Context, req *user.BatchGetUsersRequest) (*user.BatchGetUsersResponse, error) {
+	users, missing, err := h.userService.BatchGetUsers(ctx, req.Ids)
+	if err != nil {
+		code := grpcCode(err)
+		return &user.BatchGetUsersResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoUsers := make([]*user.User, len(users))
+	for i, u := range users {
+		protoUsers[i] = h.modelToProto(u)
+	}
+
+	return &user.BatchGetUsersResponse{
+		Users:      protoUsers,
+		MissingIds: missing,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Users retrieved successfully",
+			Success: true,
+		},
+	}, nil
+}
+
 func (h *UserGRPCHandler) UpdateUser(ctx context.Context, req *user.UpdateUserRequest) (*user.UserResponse, error) {
 	updateReq := &model.UpdateUserRequest{
-		Name:  req.Name,
-		Email: req.Email,
-		Phone: req.Phone,
+		Name:   req.Name,
+		Email:  req.Email,
+		Phone:  req.Phone,
+		Fields: req.GetUpdateMask().GetPaths(),
 	}
 
 	userModel, err := h.userService.UpdateUser(ctx, req.Id, updateReq)
 	if err != nil {
+		code := grpcCode(err)
 		return &user.UserResponse{
 			Status: &common.StatusResponse{
-				Code:    int32(codes.Internal),
+				Code:    int32(code),
 				Message: err.Error(),
 				Success: false,
 			},
-		}, status.Error(codes.Internal, err.Error())
+		}, status.Error(code, err.Error())
 	}
 
 	return &user.UserResponse{
@@ -120,19 +156,24 @@ func (h *UserGRPCHandler) DeleteUser(ctx context.Context, req *user.DeleteUserRe
 }
 
 func (h *UserGRPCHandler) ListUsers(ctx context.Context, req *user.ListUsersRequest) (*user.ListUsersResponse, error) {
-	page := int(req.Page)
-	limit := int(req.Limit)
-	search := req.Search
+	params := &model.ListUsersParams{
+		Page:      int(req.Page),
+		Limit:     int(req.Limit),
+		PageToken: req.PageToken,
+		TotalMode: pagination.TotalMode(req.TotalMode),
+		Search:    req.Search,
+	}
 
-	users, total, err := h.userService.ListUsers(ctx, page, limit, search)
+	users, pageInfo, err := h.userService.ListUsers(ctx, params)
 	if err != nil {
+		code := grpcCode(err)
 		return &user.ListUsersResponse{
 			Status: &common.StatusResponse{
-				Code:    int32(codes.Internal),
+				Code:    int32(code),
 				Message: err.Error(),
 				Success: false,
 			},
-		}, status.Error(codes.Internal, err.Error())
+		}, status.Error(code, err.Error())
 	}
 
 	protoUsers := make([]*user.User, len(users))
@@ -140,15 +181,35 @@ func (h *UserGRPCHandler) ListUsers(ctx context.Context, req *user.ListUsersRequ
 		protoUsers[i] = h.modelToProto(u)
 	}
 
-	return &user.ListUsersResponse{
-		Users: protoUsers,
-		Total: int32(total),
+	resp := &user.ListUsersResponse{
+		Users:          protoUsers,
+		NextPageToken:  pageInfo.NextPageToken,
+		TotalEstimated: pageInfo.TotalEstimated,
 		Status: &common.StatusResponse{
 			Code:    int32(codes.OK),
 			Message: "Users retrieved successfully",
 			Success: true,
 		},
-	}, nil
+	}
+	if pageInfo.Total != nil {
+		total := int32(*pageInfo.Total)
+		resp.Total = &total
+	}
+
+	return resp, nil
+}
+
+func (h *UserGRPCHandler) ExportUsers(req *user.ListUsersRequest, stream grpc.ServerStreamingServer[user.User]) error {
+	params := &model.ListUsersParams{Search: req.Search}
+
+	err := h.userService.ExportUsers(stream.Context(), params, func(u *model.User) error {
+		return stream.Send(h.modelToProto(u))
+	})
+	if err != nil {
+		return status.Error(grpcCode(err), err.Error())
+	}
+
+	return nil
 }
 
 func (h *UserGRPCHandler) modelToProto(u *model.User) *user.User {
@@ -159,5 +220,27 @@ func (h *UserGRPCHandler) modelToProto(u *model.User) *user.User {
 		Phone:     u.Phone,
 		CreatedAt: u.CreatedAt.Unix(),
 		UpdatedAt: u.UpdatedAt.Unix(),
+		Score:     u.Score,
+	}
+}
+
+// grpcCode maps service errors onto gRPC status codes
+func grpcCode(err error) codes.Code {
+	if errors.Is(err, pagination.ErrInvalidPageToken) {
+		return codes.InvalidArgument
 	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Code {
+		case http.StatusBadRequest:
+			return codes.InvalidArgument
+		case http.StatusNotFound:
+			return codes.NotFound
+		case http.StatusConflict:
+			return codes.AlreadyExists
+		}
+	}
+
+	return codes.Internal
 }
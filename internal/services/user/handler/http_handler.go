@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,7 +9,9 @@ import (
 
 	"go-microservice-boilerplate/internal/services/user/model"
 	"go-microservice-boilerplate/internal/services/user/service"
+	"go-microservice-boilerplate/internal/utils/pagination"
 	"go-microservice-boilerplate/internal/utils/response"
+	apperrors "go-microservice-boilerplate/pkg/errors"
 )
 
 type UserHTTPHandler struct {
@@ -93,23 +96,48 @@ func (h *UserHTTPHandler) DeleteUser(c *gin.Context) {
 func (h *UserHTTPHandler) ListUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	search := c.Query("search")
 
-	users, total, err := h.userService.ListUsers(c.Request.Context(), page, limit, search)
+	params := &model.ListUsersParams{
+		Page:      page,
+		Limit:     limit,
+		PageToken: c.Query("page_token"),
+		Search:    c.Query("search"),
+	}
+
+	users, pageInfo, err := h.userService.ListUsers(c.Request.Context(), params)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to list users", err.Error())
+		response.Error(c, listErrorStatus(err), "Failed to list users", err.Error())
 		return
 	}
 
+	paging := gin.H{
+		"page":            params.Page,
+		"limit":           params.Limit,
+		"total":           pageInfo.Total,
+		"total_estimated": pageInfo.TotalEstimated,
+		"next_page_token": pageInfo.NextPageToken,
+	}
+	if pageInfo.Total != nil && params.Limit > 0 {
+		paging["total_pages"] = (*pageInfo.Total + int64(params.Limit) - 1) / int64(params.Limit)
+	}
+
 	result := gin.H{
-		"users": users,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
+		"users":      users,
+		"pagination": paging,
 	}
 
 	response.Success(c, http.StatusOK, "Users retrieved successfully", result)
 }
+
+// listErrorStatus maps errors of listing users onto HTTP statuses, so bad
+// page tokens and filters are reported as the client's fault
+func listErrorStatus(err error) int {
+	if errors.Is(err, pagination.ErrInvalidPageToken) {
+		return http.StatusBadRequest
+	}
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) && appErr.Code == http.StatusBadRequest {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
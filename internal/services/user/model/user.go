@@ -3,6 +3,8 @@ package model
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"time"
+
+	"go-microservice-boilerplate/internal/utils/pagination"
 )
 
 type User struct {
@@ -13,6 +15,7 @@ type User struct {
 	Password  string             `bson:"password" json:"-"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	Score     float64            `bson:"score,omitempty" json:"score,omitempty"`
 }
 
 type CreateUserRequest struct {
@@ -22,8 +25,22 @@ type CreateUserRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+// UpdatableFields are the user fields an update mask can name
+var UpdatableFields = []string{"name", "email", "phone"}
+
 type UpdateUserRequest struct {
 	Name  string `json:"name"`
 	Email string `json:"email" binding:"omitempty,email"`
 	Phone string `json:"phone"`
+	// Fields lists the fields to write, taking their values as given so an
+	// empty phone clears it. Without fields only non-empty values are written.
+	Fields []string `json:"-"`
+}
+
+type ListUsersParams struct {
+	Page      int
+	Limit     int
+	PageToken string
+	TotalMode pagination.TotalMode
+	Search    string
 }
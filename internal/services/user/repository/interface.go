@@ -3,19 +3,29 @@ package repository
 import (
 	"context"
 	"go-microservice-boilerplate/internal/services/user/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *model.User) error
 	GetByID(ctx context.Context, id string) (*model.User, error)
+	// GetByIDs returns the users with any of the given IDs, in no order
+	GetByIDs(ctx context.Context, ids []string) ([]*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
-	Update(ctx context.Context, id string, user *model.User) error
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, page, limit int, search string) ([]*model.User, int64, error)
+	// Update writes the given fields of user, removing the empty ones
+	Update(ctx context.Context, id string, user *model.User, fields []string) error
+	// Delete removes a user, reporting whether it existed
+	Delete(ctx context.Context, id string) (bool, error)
+	List(ctx context.Context, params *model.ListUsersParams) ([]*model.User, *pagination.PageInfo, error)
+	// Export calls fn for every user matching the list filters, reading
+	// them from a cursor one at a time. Paging parameters are ignored.
+	Export(ctx context.Context, params *model.ListUsersParams, fn func(*model.User) error) error
 }
 
 type UserCache interface {
 	Set(ctx context.Context, key string, user *model.User, expiration int) error
 	Get(ctx context.Context, key string) (*model.User, error)
 	Delete(ctx context.Context, key string) error
+	GetMany(ctx context.Context, keys []string) ([]*model.User, error)
+	SetMany(ctx context.Context, users map[string]*model.User, expiration int) error
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,6 +12,7 @@ import (
 
 	"go-microservice-boilerplate/internal/database"
 	"go-microservice-boilerplate/internal/services/user/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
 )
 
 type mongoUserRepository struct {
@@ -47,6 +49,30 @@ func (r *mongoUserRepository) GetByID(ctx context.Context, id string) (*model.Us
 	return &user, nil
 }
 
+func (r *mongoUserRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	objectIDs := make([]primitive.ObjectID, len(ids))
+	for i, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs[i] = objectID
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*model.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (r *mongoUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
 	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
@@ -57,70 +83,158 @@ func (r *mongoUserRepository) GetByEmail(ctx context.Context, email string) (*mo
 	return &user, nil
 }
 
-func (r *mongoUserRepository) Update(ctx context.Context, id string, user *model.User) error {
+func (r *mongoUserRepository) Update(ctx context.Context, id string, user *model.User, fields []string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
 	user.UpdatedAt = time.Now()
-	update := bson.M{
-		"$set": bson.M{
-			"name":       user.Name,
-			"email":      user.Email,
-			"phone":      user.Phone,
-			"updated_at": user.UpdatedAt,
-		},
+	set := bson.M{"updated_at": user.UpdatedAt}
+	unset := bson.M{}
+	for _, field := range fields {
+		var value string
+		switch field {
+		case "name":
+			value = user.Name
+		case "email":
+			value = user.Email
+		case "phone":
+			value = user.Phone
+		default:
+			return fmt.Errorf("unknown user field %q", field)
+		}
+
+		// Cleared fields are removed rather than stored empty
+		if value == "" {
+			unset[field] = ""
+		} else {
+			set[field] = value
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
 	}
 
 	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	return err
 }
 
-func (r *mongoUserRepository) Delete(ctx context.Context, id string) error {
+func (r *mongoUserRepository) Delete(ctx context.Context, id string) (bool, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
-	return err
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
 }
 
-func (r *mongoUserRepository) List(ctx context.Context, page, limit int, search string) ([]*model.User, int64, error) {
+func (r *mongoUserRepository) List(ctx context.Context, params *model.ListUsersParams) ([]*model.User, *pagination.PageInfo, error) {
 	filter := bson.M{}
 
-	if search != "" {
-		filter["$or"] = []bson.M{
-			{"name": bson.M{"$regex": search, "$options": "i"}},
-			{"email": bson.M{"$regex": search, "$options": "i"}},
+	if params.Search != "" {
+		filter["$text"] = bson.M{"$search": params.Search}
+	}
+
+	var cursor *pagination.Cursor
+	if params.PageToken != "" {
+		var err error
+		if cursor, err = pagination.DecodeCursor(params.PageToken, "created_at:desc"); err != nil {
+			return nil, nil, err
 		}
 	}
 
 	// Count total documents
-	total, err := r.collection.CountDocuments(ctx, filter)
+	total, estimated, err := pagination.Count(ctx, r.collection, filter, params.TotalMode, cursor != nil)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
-	// Calculate skip
-	skip := (page - 1) * limit
+	// Fetch one extra document to learn whether another page follows
+	findOptions := options.Find().
+		SetLimit(int64(params.Limit + 1)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
 
-	// Find documents with pagination
-	cursor, err := r.collection.Find(ctx, filter, &options.FindOptions{
-		Skip:  &[]int64{int64(skip)}[0],
-		Limit: &[]int64{int64(limit)}[0],
-		Sort:  bson.M{"created_at": -1},
-	})
+	// Search results are ranked by relevance
+	if params.Search != "" {
+		textScore := bson.M{"$meta": "textScore"}
+		findOptions.SetProjection(bson.M{"score": textScore})
+		findOptions.SetSort(bson.D{{Key: "score", Value: textScore}, {Key: "_id", Value: 1}})
+	}
+
+	query := filter
+	if cursor != nil {
+		query = bson.M{"$and": []bson.M{filter, cursor.After("created_at", true)}}
+	} else {
+		findOptions.SetSkip(int64((params.Page - 1) * params.Limit))
+	}
+
+	dbCursor, err := r.collection.Find(ctx, query, findOptions)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
-	defer cursor.Close(ctx)
+	defer dbCursor.Close(ctx)
 
 	var users []*model.User
-	if err = cursor.All(ctx, &users); err != nil {
-		return nil, 0, err
+	if err = dbCursor.All(ctx, &users); err != nil {
+		return nil, nil, err
+	}
+
+	pageInfo := &pagination.PageInfo{
+		Total:          total,
+		TotalEstimated: estimated,
+	}
+
+	hasMore := len(users) > params.Limit
+	if hasMore {
+		users = users[:params.Limit]
+	}
+
+	// Text scores cannot be used in filters, so search results only page by offset
+	if hasMore && params.Search == "" {
+		last := users[len(users)-1]
+		if pageInfo.NextPageToken, err = pagination.EncodeCursor("created_at:desc", last.CreatedAt, last.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return users, pageInfo, nil
+}
+
+func (r *mongoUserRepository) Export(ctx context.Context, params *model.ListUsersParams, fn func(*model.User) error) error {
+	filter := bson.M{}
+	findOptions := options.Find().
+		SetBatchSize(pagination.ExportBatchSize).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	if params.Search != "" {
+		textScore := bson.M{"$meta": "textScore"}
+		filter["$text"] = bson.M{"$search": params.Search}
+		findOptions.SetProjection(bson.M{"score": textScore})
+		findOptions.SetSort(bson.D{{Key: "score", Value: textScore}, {Key: "_id", Value: 1}})
+	}
+
+	dbCursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return err
+	}
+	defer dbCursor.Close(ctx)
+
+	for dbCursor.Next(ctx) {
+		var user model.User
+		if err := dbCursor.Decode(&user); err != nil {
+			return err
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
 	}
 
-	return users, total, nil
+	return dbCursor.Err()
 }
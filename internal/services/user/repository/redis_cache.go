@@ -51,3 +51,46 @@ func (c *redisUserCache) Get(ctx context.Context, key string) (*model.User, erro
 func (c *redisUserCache) Delete(ctx context.Context, key string) error {
 	return c.client.Client.Del(ctx, key).Err()
 }
+
+// GetMany retrieves several users from cache in one round trip. The result
+// lines up with keys, holding nil for every key that missed.
+func (c *redisUserCache) GetMany(ctx context.Context, keys []string) ([]*model.User, error) {
+	values, err := c.client.Client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*model.User, len(keys))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var user model.User
+		// An entry that no longer decodes counts as a miss
+		if err := json.Unmarshal([]byte(data), &user); err == nil {
+			users[i] = &user
+		}
+	}
+
+	return users, nil
+}
+
+// SetMany stores several users in cache, keyed by cache key, in one round
+// trip
+func (c *redisUserCache) SetMany(ctx context.Context, users map[string]*model.User, expiration int) error {
+	pipe := c.client.Client.Pipeline()
+	for key, user := range users {
+		data, err := json.Marshal(user)
+		if err != nil {
+			return fmt.Errorf("failed to marshal user: %w", err)
+		}
+		pipe.Set(ctx, key, data, time.Duration(expiration)*time.Second)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return nil
+}
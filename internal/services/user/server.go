@@ -1,6 +1,7 @@
 package user
 
 import (
+	"context"
 	"fmt"
 	"net"
 
@@ -9,29 +10,39 @@ import (
 
 	"go-microservice-boilerplate/internal/config"
 	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/outbox"
 	"go-microservice-boilerplate/internal/proto/user"
 	"go-microservice-boilerplate/internal/services/user/handler"
 	"go-microservice-boilerplate/internal/services/user/repository"
 	"go-microservice-boilerplate/internal/services/user/service"
 	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
 )
 
 type Server struct {
 	config      *config.Config
 	grpcServer  *grpc.Server
 	userService service.UserService
+	outbox      outbox.Outbox
+	publisher   events.Publisher
+	stopWorkers context.CancelFunc
 }
 
 func NewServer(cfg *config.Config, mongodb *database.MongoDB, redis *database.Redis) *Server {
 	// Initialize repositories
 	userRepo := repository.NewMongoUserRepository(mongodb)
 	userCache := repository.NewRedisUserCache(redis)
+	userOutbox := outbox.NewMongoOutbox(mongodb)
 
 	// Initialize service
-	userService := service.NewUserService(userRepo, userCache)
+	userService := service.NewUserService(userRepo, userCache, userOutbox)
 
 	// Initialize gRPC server
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestmeta.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(requestmeta.StreamServerInterceptor()),
+	)
 
 	// Register handlers
 	userHandler := handler.NewUserGRPCHandler(userService)
@@ -44,6 +55,8 @@ func NewServer(cfg *config.Config, mongodb *database.MongoDB, redis *database.Re
 		config:      cfg,
 		grpcServer:  grpcServer,
 		userService: userService,
+		outbox:      userOutbox,
+		publisher:   events.NewBus(cfg.Events, redis),
 	}
 }
 
@@ -56,6 +69,10 @@ func (s *Server) Start() error {
 
 	logger.Infof("User service starting on port %s", port)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWorkers = cancel
+	go outbox.Run(ctx, s.outbox, s.publisher)
+
 	if err := s.grpcServer.Serve(listener); err != nil {
 		return fmt.Errorf("failed to serve gRPC server: %w", err)
 	}
@@ -65,5 +82,8 @@ func (s *Server) Start() error {
 
 func (s *Server) Stop() {
 	logger.Info("Shutting down User service...")
+	if s.stopWorkers != nil {
+		s.stopWorkers()
+	}
 	s.grpcServer.GracefulStop()
 }
@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"go-microservice-boilerplate/internal/services/user/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
 )
 
 type UserService interface {
 	CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.User, error)
 	GetUser(ctx context.Context, id string) (*model.User, error)
+	BatchGetUsers(ctx context.Context, ids []string) ([]*model.User, []string, error)
 	UpdateUser(ctx context.Context, id string, req *model.UpdateUserRequest) (*model.User, error)
 	DeleteUser(ctx context.Context, id string) error
-	ListUsers(ctx context.Context, page, limit int, search string) ([]*model.User, int64, error)
+	ListUsers(ctx context.Context, params *model.ListUsersParams) ([]*model.User, *pagination.PageInfo, error)
+	ExportUsers(ctx context.Context, params *model.ListUsersParams, fn func(*model.User) error) error
 }
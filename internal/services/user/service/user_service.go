@@ -3,22 +3,34 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/outbox"
 	"go-microservice-boilerplate/internal/services/user/model"
 	"go-microservice-boilerplate/internal/services/user/repository"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	"go-microservice-boilerplate/internal/utils/validator"
+	apperrors "go-microservice-boilerplate/pkg/errors"
 )
 
 type userService struct {
-	repo  repository.UserRepository
-	cache repository.UserCache
+	repo   repository.UserRepository
+	cache  repository.UserCache
+	outbox outbox.Outbox
 }
 
-func NewUserService(repo repository.UserRepository, cache repository.UserCache) UserService {
+// NewUserService creates a user service that records every change of a
+// user as a domain event in the outbox
+func NewUserService(repo repository.UserRepository, cache repository.UserCache, outbox outbox.Outbox) UserService {
 	return &userService{
-		repo:  repo,
-		cache: cache,
+		repo:   repo,
+		cache:  cache,
+		outbox: outbox,
 	}
 }
 
@@ -42,8 +54,14 @@ func (s *userService) CreateUser(ctx context.Context, req *model.CreateUserReque
 		Password: string(hashedPassword),
 	}
 
-	if err := s.repo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	err = s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		if err := s.repo.Create(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+		return userEvent(ctx, events.UserCreated, user)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Cache the user
@@ -73,6 +91,11 @@ func (s *userService) GetUser(ctx context.Context, id string) (*model.User, erro
 }
 
 func (s *userService) UpdateUser(ctx context.Context, id string, req *model.UpdateUserRequest) (*model.User, error) {
+	fields, err := userUpdateFields(req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get existing user
 	user, err := s.GetUser(ctx, id)
 	if err != nil {
@@ -80,18 +103,25 @@ func (s *userService) UpdateUser(ctx context.Context, id string, req *model.Upda
 	}
 
 	// Update fields
-	if req.Name != "" {
-		user.Name = req.Name
-	}
-	if req.Email != "" {
-		user.Email = req.Email
-	}
-	if req.Phone != "" {
-		user.Phone = req.Phone
+	for _, field := range fields {
+		switch field {
+		case "name":
+			user.Name = req.Name
+		case "email":
+			user.Email = req.Email
+		case "phone":
+			user.Phone = req.Phone
+		}
 	}
 
-	if err := s.repo.Update(ctx, id, user); err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+	err = s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		if err := s.repo.Update(ctx, id, user, fields); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+		return userEvent(ctx, events.UserUpdated, user)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Update cache
@@ -102,8 +132,23 @@ func (s *userService) UpdateUser(ctx context.Context, id string, req *model.Upda
 }
 
 func (s *userService) DeleteUser(ctx context.Context, id string) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+	err := s.outbox.Write(ctx, func(ctx context.Context) ([]*events.Event, error) {
+		deleted, err := s.repo.Delete(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete user: %w", err)
+		}
+		// Deleting a user that does not exist changes nothing to tell
+		if !deleted {
+			return nil, nil
+		}
+		event, err := events.New(ctx, events.UserDeleted, id, events.Deleted{ID: id})
+		if err != nil {
+			return nil, err
+		}
+		return []*events.Event{event}, nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// Remove from cache
@@ -113,18 +158,160 @@ func (s *userService) DeleteUser(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *userService) ListUsers(ctx context.Context, page, limit int, search string) ([]*model.User, int64, error) {
-	if page <= 0 {
-		page = 1
+// userEvent records the state of a user after a change
+func userEvent(ctx context.Context, eventType string, user *model.User) ([]*events.Event, error) {
+	event, err := events.New(ctx, eventType, user.ID.Hex(), user)
+	if err != nil {
+		return nil, err
+	}
+	return []*events.Event{event}, nil
+}
+
+// userUpdateFields returns the fields an update writes: those of its mask,
+// or without a mask the ones it gives a value
+func userUpdateFields(req *model.UpdateUserRequest) ([]string, error) {
+	if len(req.Fields) == 0 {
+		var fields []string
+		if req.Name != "" {
+			fields = append(fields, "name")
+		}
+		if req.Email != "" {
+			fields = append(fields, "email")
+		}
+		if req.Phone != "" {
+			fields = append(fields, "phone")
+		}
+		return fields, nil
 	}
-	if limit <= 0 {
-		limit = 10
+
+	for _, field := range req.Fields {
+		if !slices.Contains(model.UpdatableFields, field) {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("field %q cannot be updated", field))
+		}
+		switch field {
+		case "name":
+			if strings.TrimSpace(req.Name) == "" {
+				return nil, apperrors.ErrInvalidInput("name cannot be empty")
+			}
+		case "email":
+			if err := validator.ValidateEmail(req.Email); err != nil {
+				return nil, apperrors.ErrInvalidInput(err.Error())
+			}
+		}
 	}
 
-	users, total, err := s.repo.List(ctx, page, limit, search)
+	return req.Fields, nil
+}
+
+// BatchGetUsers returns the users with the given IDs in the order requested,
+// reading what it can from cache and the rest with a single query, together
+// with the IDs no user has
+func (s *userService) BatchGetUsers(ctx context.Context, ids []string) ([]*model.User, []string, error) {
+	ids, err := batchIDs(ids)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+		return nil, nil, err
 	}
 
-	return users, total, nil
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("user:%s", id)
+	}
+	cached, err := s.cache.GetMany(ctx, keys)
+	if err != nil {
+		cached = make([]*model.User, len(ids))
+	}
+
+	found := make(map[string]*model.User, len(ids))
+	var misses []string
+	for i, id := range ids {
+		if cached[i] != nil {
+			found[id] = cached[i]
+		} else {
+			misses = append(misses, id)
+		}
+	}
+
+	if len(misses) > 0 {
+		users, err := s.repo.GetByIDs(ctx, misses)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get users: %w", err)
+		}
+
+		// Backfill the cache with what the query found
+		backfill := make(map[string]*model.User, len(users))
+		for _, user := range users {
+			found[user.ID.Hex()] = user
+			backfill[fmt.Sprintf("user:%s", user.ID.Hex())] = user
+		}
+		if len(backfill) > 0 {
+			s.cache.SetMany(ctx, backfill, 3600)
+		}
+	}
+
+	users := make([]*model.User, 0, len(ids))
+	var missing []string
+	for _, id := range ids {
+		if user, ok := found[id]; ok {
+			users = append(users, user)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	return users, missing, nil
+}
+
+// batchIDs validates the IDs of a batch get, dropping repeated ones
+func batchIDs(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, apperrors.ErrInvalidInput("at least one ID is required")
+	}
+
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		if !primitive.IsValidObjectID(id) {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("invalid ID %q", id))
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) > pagination.MaxBatchIDs {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("at most %d IDs can be fetched at once", pagination.MaxBatchIDs))
+	}
+
+	return unique, nil
+}
+
+func (s *userService) ListUsers(ctx context.Context, params *model.ListUsersParams) ([]*model.User, *pagination.PageInfo, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.Limit > pagination.MaxLimit {
+		return nil, nil, apperrors.ErrInvalidInput(fmt.Sprintf("limit cannot exceed %d, export larger result sets instead", pagination.MaxLimit))
+	}
+	if params.Search != "" && params.PageToken != "" {
+		return nil, nil, apperrors.ErrInvalidInput("search results only support page-based pagination")
+	}
+
+	users, pageInfo, err := s.repo.List(ctx, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, pageInfo, nil
+}
+
+// ExportUsers streams every user matching the list filters to fn
+func (s *userService) ExportUsers(ctx context.Context, params *model.ListUsersParams, fn func(*model.User) error) error {
+	if err := s.repo.Export(ctx, params, fn); err != nil {
+		return fmt.Errorf("failed to export users: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go-microservice-boilerplate/internal/proto/common"
+	"go-microservice-boilerplate/internal/proto/webhook"
+	"go-microservice-boilerplate/internal/services/webhook/model"
+	"go-microservice-boilerplate/internal/services/webhook/service"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+)
+
+type WebhookGRPCHandler struct {
+	webhook.UnimplementedWebhookServiceServer
+	webhookService service.WebhookService
+}
+
+func NewWebhookGRPCHandler(webhookService service.WebhookService) *WebhookGRPCHandler {
+	return &WebhookGRPCHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookGRPCHandler) CreateEndpoint(ctx context.Context, req *webhook.CreateEndpointRequest) (*webhook.EndpointResponse, error) {
+	endpoint, err := h.webhookService.CreateEndpoint(ctx, &model.CreateEndpointRequest{
+		URL:         req.Url,
+		Secret:      req.Secret,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+	})
+	resp, err := endpointResponse(endpoint, err, "Webhook endpoint created successfully")
+	// The secret is only ever shown to whoever created the endpoint
+	if err == nil {
+		resp.Endpoint.Secret = endpoint.Secret
+	}
+	return resp, err
+}
+
+func (h *WebhookGRPCHandler) GetEndpoint(ctx context.Context, req *webhook.GetEndpointRequest) (*webhook.EndpointResponse, error) {
+	endpoint, err := h.webhookService.GetEndpoint(ctx, req.Id)
+	return endpointResponse(endpoint, err, "Webhook endpoint retrieved successfully")
+}
+
+func (h *WebhookGRPCHandler) ListEndpoints(ctx context.Context, req *webhook.ListEndpointsRequest) (*webhook.ListEndpointsResponse, error) {
+	endpoints, err := h.webhookService.ListEndpoints(ctx)
+	if err != nil {
+		code := grpcCode(err)
+		return &webhook.ListEndpointsResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoEndpoints := make([]*webhook.Endpoint, len(endpoints))
+	for i, endpoint := range endpoints {
+		protoEndpoints[i] = endpointToProto(endpoint)
+	}
+
+	return &webhook.ListEndpointsResponse{
+		Endpoints: protoEndpoints,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Webhook endpoints retrieved successfully",
+			Success: true,
+		},
+	}, nil
+}
+
+func (h *WebhookGRPCHandler) UpdateEndpoint(ctx context.Context, req *webhook.UpdateEndpointRequest) (*webhook.EndpointResponse, error) {
+	endpoint, err := h.webhookService.UpdateEndpoint(ctx, req.Id, &model.UpdateEndpointRequest{
+		URL:         req.Url,
+		Secret:      req.Secret,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+	})
+	return endpointResponse(endpoint, err, "Webhook endpoint updated successfully")
+}
+
+func (h *WebhookGRPCHandler) DeleteEndpoint(ctx context.Context, req *webhook.DeleteEndpointRequest) (*common.StatusResponse, error) {
+	if err := h.webhookService.DeleteEndpoint(ctx, req.Id); err != nil {
+		code := grpcCode(err)
+		return &common.StatusResponse{
+			Code:    int32(code),
+			Message: err.Error(),
+			Success: false,
+		}, status.Error(code, err.Error())
+	}
+
+	return &common.StatusResponse{
+		Code:    int32(codes.OK),
+		Message: "Webhook endpoint deleted successfully",
+		Success: true,
+	}, nil
+}
+
+func (h *WebhookGRPCHandler) ListDeliveries(ctx context.Context, req *webhook.ListDeliveriesRequest) (*webhook.ListDeliveriesResponse, error) {
+	deliveries, pageInfo, err := h.webhookService.ListDeliveries(ctx, &model.ListDeliveriesParams{
+		EndpointID: req.EndpointId,
+		Status:     req.Status,
+		Page:       int(req.Page),
+		Limit:      int(req.Limit),
+		PageToken:  req.PageToken,
+		TotalMode:  pagination.TotalMode(req.TotalMode),
+	})
+	if err != nil {
+		code := grpcCode(err)
+		return &webhook.ListDeliveriesResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	protoDeliveries := make([]*webhook.Delivery, len(deliveries))
+	for i, delivery := range deliveries {
+		protoDeliveries[i] = deliveryToProto(delivery)
+	}
+
+	resp := &webhook.ListDeliveriesResponse{
+		Deliveries:     protoDeliveries,
+		NextPageToken:  pageInfo.NextPageToken,
+		TotalEstimated: pageInfo.TotalEstimated,
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Webhook deliveries retrieved successfully",
+			Success: true,
+		},
+	}
+	if pageInfo.Total != nil {
+		total := int32(*pageInfo.Total)
+		resp.Total = &total
+	}
+
+	return resp, nil
+}
+
+func (h *WebhookGRPCHandler) ReplayDelivery(ctx context.Context, req *webhook.ReplayDeliveryRequest) (*webhook.DeliveryResponse, error) {
+	delivery, err := h.webhookService.ReplayDelivery(ctx, req.EndpointId, req.DeliveryId)
+	if err != nil {
+		code := grpcCode(err)
+		return &webhook.DeliveryResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &webhook.DeliveryResponse{
+		Delivery: deliveryToProto(delivery),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: "Webhook delivery scheduled for replay",
+			Success: true,
+		},
+	}, nil
+}
+
+// endpointResponse builds the response of the RPCs that return an endpoint
+func endpointResponse(endpoint *model.Endpoint, err error, message string) (*webhook.EndpointResponse, error) {
+	if err != nil {
+		code := grpcCode(err)
+		return &webhook.EndpointResponse{
+			Status: &common.StatusResponse{
+				Code:    int32(code),
+				Message: err.Error(),
+				Success: false,
+			},
+		}, status.Error(code, err.Error())
+	}
+
+	return &webhook.EndpointResponse{
+		Endpoint: endpointToProto(endpoint),
+		Status: &common.StatusResponse{
+			Code:    int32(codes.OK),
+			Message: message,
+			Success: true,
+		},
+	}, nil
+}
+
+func endpointToProto(e *model.Endpoint) *webhook.Endpoint {
+	protoEndpoint := &webhook.Endpoint{
+		Id:                  e.ID.Hex(),
+		Url:                 e.URL,
+		EventTypes:          e.EventTypes,
+		Description:         e.Description,
+		Enabled:             e.Enabled,
+		DisabledReason:      e.DisabledReason,
+		ConsecutiveFailures: int32(e.ConsecutiveFailures),
+		CreatedAt:           e.CreatedAt.Unix(),
+		UpdatedAt:           e.UpdatedAt.Unix(),
+	}
+	if e.DisabledAt != nil {
+		protoEndpoint.DisabledAt = e.DisabledAt.Unix()
+	}
+
+	return protoEndpoint
+}
+
+func deliveryToProto(d *model.Delivery) *webhook.Delivery {
+	protoDelivery := &webhook.Delivery{
+		Id:         d.ID.Hex(),
+		EndpointId: d.EndpointID,
+		EventId:    d.Event.ID,
+		EventType:  d.Event.Type,
+		Status:     d.Status,
+		Attempts:   make([]*webhook.DeliveryAttempt, len(d.Attempts)),
+		ReplayOf:   d.ReplayOf,
+		CreatedAt:  d.CreatedAt.Unix(),
+		UpdatedAt:  d.UpdatedAt.Unix(),
+	}
+	for i, attempt := range d.Attempts {
+		protoDelivery.Attempts[i] = &webhook.DeliveryAttempt{
+			AttemptedAt: attempt.AttemptedAt.Unix(),
+			StatusCode:  int32(attempt.StatusCode),
+			Error:       attempt.Error,
+			DurationMs:  attempt.Duration.Milliseconds(),
+		}
+	}
+	if d.NextAttemptAt != nil {
+		protoDelivery.NextAttemptAt = d.NextAttemptAt.Unix()
+	}
+	if d.DeliveredAt != nil {
+		protoDelivery.DeliveredAt = d.DeliveredAt.Unix()
+	}
+
+	return protoDelivery
+}
+
+// grpcCode maps service errors onto gRPC status codes
+func grpcCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, pagination.ErrInvalidPageToken):
+		return codes.InvalidArgument
+	case errors.Is(err, model.ErrEndpointNotFound), errors.Is(err, model.ErrDeliveryNotFound):
+		return codes.NotFound
+	case errors.Is(err, model.ErrEndpointDisabled), errors.Is(err, model.ErrDeliveryPending):
+		return codes.FailedPrecondition
+	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Code {
+		case http.StatusBadRequest:
+			return codes.InvalidArgument
+		case http.StatusNotFound:
+			return codes.NotFound
+		case http.StatusConflict:
+			return codes.AlreadyExists
+		}
+	}
+
+	return codes.Internal
+}
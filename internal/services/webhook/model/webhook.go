@@ -0,0 +1,150 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/utils/pagination"
+)
+
+// Delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// MaxEventTypes bounds how many event types one endpoint subscribes to
+const MaxEventTypes = 20
+
+var (
+	// ErrEndpointNotFound is returned for unknown endpoint IDs
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+	// ErrDeliveryNotFound is returned for unknown delivery IDs, and for
+	// deliveries of another endpoint
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrEndpointDisabled is returned when a delivery to a disabled endpoint
+	// is replayed
+	ErrEndpointDisabled = errors.New("webhook endpoint is disabled")
+	// ErrDeliveryExists is returned when an event is delivered to an
+	// endpoint a second time, other than by a replay
+	ErrDeliveryExists = errors.New("webhook delivery already exists")
+	// ErrDeliveryPending is returned when a delivery that is still being
+	// attempted is replayed
+	ErrDeliveryPending = errors.New("webhook delivery is still pending")
+)
+
+// ValidDeliveryStatus reports whether status is a delivery status
+func ValidDeliveryStatus(status string) bool {
+	return status == DeliveryPending || status == DeliveryDelivered || status == DeliveryFailed
+}
+
+// deliverableEvents are the event types endpoints can subscribe to
+var deliverableEvents = []string{
+	events.UserCreated, events.UserUpdated, events.UserDeleted,
+	events.ProductCreated, events.ProductUpdated, events.ProductDeleted,
+}
+
+// ValidEventType reports whether an endpoint can subscribe to eventType: a
+// deliverable event type, "user.*", "product.*" or "*"
+func ValidEventType(eventType string) bool {
+	switch eventType {
+	case "*", "user.*", "product.*":
+		return true
+	}
+	for _, deliverable := range deliverableEvents {
+		if eventType == deliverable {
+			return true
+		}
+	}
+	return false
+}
+
+// Deliverable reports whether events of the type are pushed to endpoints
+func Deliverable(eventType string) bool {
+	return strings.HasPrefix(eventType, "user.") || strings.HasPrefix(eventType, "product.")
+}
+
+// Subscriptions returns the subscribed event types that match eventType
+func Subscriptions(eventType string) []string {
+	matches := []string{eventType, "*"}
+	if aggregate, _, ok := strings.Cut(eventType, "."); ok {
+		matches = append(matches, aggregate+".*")
+	}
+	return matches
+}
+
+// Endpoint is an HTTP endpoint of an integrator that receives events
+type Endpoint struct {
+	ID  primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	URL string             `bson:"url" json:"url"`
+	// Secret signs the requests to the endpoint
+	Secret      string   `bson:"secret" json:"-"`
+	EventTypes  []string `bson:"event_types" json:"event_types"`
+	Description string   `bson:"description,omitempty" json:"description,omitempty"`
+	Enabled     bool     `bson:"enabled" json:"enabled"`
+	// DisabledReason tells why the endpoint was disabled automatically
+	DisabledReason string `bson:"disabled_reason,omitempty" json:"disabled_reason,omitempty"`
+	// ConsecutiveFailures counts the deliveries in a row that failed every
+	// attempt
+	ConsecutiveFailures int        `bson:"consecutive_failures" json:"consecutive_failures"`
+	CreatedAt           time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt           time.Time  `bson:"updated_at" json:"updated_at"`
+	DisabledAt          *time.Time `bson:"disabled_at,omitempty" json:"disabled_at,omitempty"`
+}
+
+// Delivery is the log of pushing one event to one endpoint
+type Delivery struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	EndpointID string             `bson:"endpoint_id" json:"endpoint_id"`
+	Event      *events.Event      `bson:"event" json:"event"`
+	Status     string             `bson:"status" json:"status"`
+	Attempts   []DeliveryAttempt  `bson:"attempts" json:"attempts"`
+	// ReplayOf is set on replays, the delivery that was replayed
+	ReplayOf string `bson:"replay_of,omitempty" json:"replay_of,omitempty"`
+	// NextAttemptAt is when a pending delivery is attempted next
+	NextAttemptAt *time.Time `bson:"next_attempt_at,omitempty" json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `bson:"updated_at" json:"updated_at"`
+	DeliveredAt   *time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+}
+
+// DeliveryAttempt is one request of a delivery
+type DeliveryAttempt struct {
+	AttemptedAt time.Time `bson:"attempted_at" json:"attempted_at"`
+	// StatusCode of the response, zero when there was none
+	StatusCode int           `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Error      string        `bson:"error,omitempty" json:"error,omitempty"`
+	Duration   time.Duration `bson:"duration" json:"duration"`
+}
+
+type CreateEndpointRequest struct {
+	URL string `json:"url" binding:"required"`
+	// Secret is generated when empty
+	Secret      string   `json:"secret"`
+	EventTypes  []string `json:"event_types" binding:"required"`
+	Description string   `json:"description"`
+}
+
+// UpdateEndpointRequest changes the fields that are set
+type UpdateEndpointRequest struct {
+	URL         *string  `json:"url"`
+	Secret      *string  `json:"secret"`
+	EventTypes  []string `json:"event_types"`
+	Description *string  `json:"description"`
+	// Enabled re-enables an endpoint, clearing its failures, or disables it
+	Enabled *bool `json:"enabled"`
+}
+
+type ListDeliveriesParams struct {
+	EndpointID string
+	Status     string
+	Page       int
+	Limit      int
+	PageToken  string
+	TotalMode  pagination.TotalMode
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+)
+
+// EnsureIndexes creates the indexes the webhook repositories rely on for
+// correctness rather than speed. deployments/mongodb/init-mongo.js creates
+// them too, but only runs on an empty data directory.
+func EnsureIndexes(ctx context.Context, db *database.MongoDB) error {
+	// An event is delivered to an endpoint once; replays are extra
+	// deliveries of the same event
+	return db.EnsureIndexes(ctx, "webhook_deliveries", mongo.IndexModel{
+		Keys:    bson.D{{Key: "endpoint_id", Value: 1}, {Key: "event.id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"replay_of": bson.M{"$exists": false}}),
+	})
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-microservice-boilerplate/internal/services/webhook/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
+)
+
+type EndpointRepository interface {
+	Create(ctx context.Context, endpoint *model.Endpoint) error
+	// GetByID returns nil when no endpoint has the ID
+	GetByID(ctx context.Context, id string) (*model.Endpoint, error)
+	List(ctx context.Context) ([]*model.Endpoint, error)
+	// ListSubscribed returns the enabled endpoints subscribed to any of the
+	// event types
+	ListSubscribed(ctx context.Context, eventTypes []string) ([]*model.Endpoint, error)
+	// Update stores the state of an endpoint
+	Update(ctx context.Context, endpoint *model.Endpoint) error
+	// Delete reports whether an endpoint had the ID
+	Delete(ctx context.Context, id string) (bool, error)
+	// RecordSuccess clears the failures of an endpoint
+	RecordSuccess(ctx context.Context, id string) error
+	// RecordFailure counts a failed delivery and disables the endpoint once
+	// disableAfter deliveries in a row failed, reporting whether this call
+	// disabled it
+	RecordFailure(ctx context.Context, id string, disableAfter int) (bool, error)
+}
+
+type DeliveryRepository interface {
+	// Create stores a new delivery, returning model.ErrDeliveryExists when
+	// the event was delivered to the endpoint already, unless it is a replay
+	Create(ctx context.Context, delivery *model.Delivery) error
+	// GetByID returns nil when no delivery has the ID
+	GetByID(ctx context.Context, id string) (*model.Delivery, error)
+	List(ctx context.Context, params *model.ListDeliveriesParams) ([]*model.Delivery, *pagination.PageInfo, error)
+	// ClaimDue returns a pending delivery whose next attempt is due and
+	// postpones that attempt by lease, so no one else claims it meanwhile.
+	// It returns nil when no delivery is due.
+	ClaimDue(ctx context.Context, lease time.Duration) (*model.Delivery, error)
+	// Save stores the state of a delivery
+	Save(ctx context.Context, delivery *model.Delivery) error
+}
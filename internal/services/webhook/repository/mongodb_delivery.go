@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/webhook/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
+)
+
+type mongoDeliveryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDeliveryRepository(db *database.MongoDB) DeliveryRepository {
+	return &mongoDeliveryRepository{
+		collection: db.Collection("webhook_deliveries"),
+	}
+}
+
+func (r *mongoDeliveryRepository) Create(ctx context.Context, delivery *model.Delivery) error {
+	delivery.ID = primitive.NewObjectID()
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+
+	// A unique index on endpoint_id and event.id, for deliveries that are
+	// not replays, drops events the bus hands over again
+	_, err := r.collection.InsertOne(ctx, delivery)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrDeliveryExists
+	}
+	return err
+}
+
+func (r *mongoDeliveryRepository) GetByID(ctx context.Context, id string) (*model.Delivery, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var delivery model.Delivery
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *mongoDeliveryRepository) List(ctx context.Context, params *model.ListDeliveriesParams) ([]*model.Delivery, *pagination.PageInfo, error) {
+	filter := bson.M{"endpoint_id": params.EndpointID}
+	if params.Status != "" {
+		filter["status"] = params.Status
+	}
+
+	var cursor *pagination.Cursor
+	if params.PageToken != "" {
+		var err error
+		if cursor, err = pagination.DecodeCursor(params.PageToken, "created_at:desc"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	total, estimated, err := pagination.Count(ctx, r.collection, filter, params.TotalMode, cursor != nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Fetch one extra document to learn whether another page follows
+	findOptions := options.Find().
+		SetLimit(int64(params.Limit + 1)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	query := filter
+	if cursor != nil {
+		query = bson.M{"$and": []bson.M{filter, cursor.After("created_at", true)}}
+	} else {
+		findOptions.SetSkip(int64((params.Page - 1) * params.Limit))
+	}
+
+	dbCursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer dbCursor.Close(ctx)
+
+	var deliveries []*model.Delivery
+	if err = dbCursor.All(ctx, &deliveries); err != nil {
+		return nil, nil, err
+	}
+
+	pageInfo := &pagination.PageInfo{
+		Total:          total,
+		TotalEstimated: estimated,
+	}
+
+	if len(deliveries) > params.Limit {
+		deliveries = deliveries[:params.Limit]
+		last := deliveries[len(deliveries)-1]
+		if pageInfo.NextPageToken, err = pagination.EncodeCursor("created_at:desc", last.CreatedAt, last.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return deliveries, pageInfo, nil
+}
+
+func (r *mongoDeliveryRepository) ClaimDue(ctx context.Context, lease time.Duration) (*model.Delivery, error) {
+	now := time.Now()
+	filter := bson.M{
+		"status":          model.DeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery model.Delivery
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *mongoDeliveryRepository) Save(ctx context.Context, delivery *model.Delivery) error {
+	delivery.UpdatedAt = time.Now()
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery)
+	return err
+}
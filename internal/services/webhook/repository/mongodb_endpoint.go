@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/services/webhook/model"
+)
+
+type mongoEndpointRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoEndpointRepository(db *database.MongoDB) EndpointRepository {
+	return &mongoEndpointRepository{
+		collection: db.Collection("webhook_endpoints"),
+	}
+}
+
+func (r *mongoEndpointRepository) Create(ctx context.Context, endpoint *model.Endpoint) error {
+	endpoint.ID = primitive.NewObjectID()
+	endpoint.CreatedAt = time.Now()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, endpoint)
+	return err
+}
+
+func (r *mongoEndpointRepository) GetByID(ctx context.Context, id string) (*model.Endpoint, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoint model.Endpoint
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&endpoint)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+func (r *mongoEndpointRepository) List(ctx context.Context) ([]*model.Endpoint, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *mongoEndpointRepository) ListSubscribed(ctx context.Context, eventTypes []string) ([]*model.Endpoint, error) {
+	return r.find(ctx, bson.M{
+		"enabled":     true,
+		"event_types": bson.M{"$in": eventTypes},
+	})
+}
+
+func (r *mongoEndpointRepository) find(ctx context.Context, filter bson.M) ([]*model.Endpoint, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var endpoints []*model.Endpoint
+	if err = cursor.All(ctx, &endpoints); err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *mongoEndpointRepository) Update(ctx context.Context, endpoint *model.Endpoint) error {
+	endpoint.UpdatedAt = time.Now()
+
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": endpoint.ID}, endpoint)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrEndpointNotFound
+	}
+
+	return nil
+}
+
+func (r *mongoEndpointRepository) Delete(ctx context.Context, id string) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount > 0, nil
+}
+
+func (r *mongoEndpointRepository) RecordSuccess(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	// Most deliveries succeed, so endpoints without failures are not written
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "consecutive_failures": bson.M{"$gt": 0}},
+		bson.M{"$set": bson.M{"consecutive_failures": 0, "updated_at": time.Now()}},
+	)
+	return err
+}
+
+func (r *mongoEndpointRepository) RecordFailure(ctx context.Context, id string, disableAfter int) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	var endpoint model.Endpoint
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$inc": bson.M{"consecutive_failures": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&endpoint)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if disableAfter <= 0 || endpoint.ConsecutiveFailures < disableAfter {
+		return false, nil
+	}
+
+	// Only the failure that reaches the limit disables an enabled endpoint
+	now := time.Now()
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "enabled": true},
+		bson.M{"$set": bson.M{
+			"enabled":         false,
+			"disabled_reason": fmt.Sprintf("%d deliveries in a row failed", endpoint.ConsecutiveFailures),
+			"disabled_at":     now,
+			"updated_at":      now,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
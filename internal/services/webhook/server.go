@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"go-microservice-boilerplate/internal/config"
+	"go-microservice-boilerplate/internal/database"
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/proto/webhook"
+	"go-microservice-boilerplate/internal/services/webhook/handler"
+	"go-microservice-boilerplate/internal/services/webhook/repository"
+	"go-microservice-boilerplate/internal/services/webhook/service"
+	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/requestmeta"
+)
+
+const (
+	// dispatchInterval is how often due deliveries are attempted
+	dispatchInterval = time.Second
+	// dispatchBatch bounds how many deliveries are attempted at once
+	dispatchBatch = 20
+	// retryBackoff is the delay before the first retry of a delivery,
+	// doubling with every further attempt up to maxRetryBackoff
+	retryBackoff    = 30 * time.Second
+	maxRetryBackoff = time.Hour
+	// eventGroup is the consumer group the webhook service reads events in
+	eventGroup = "webhook-service"
+)
+
+type Server struct {
+	config         *config.Config
+	grpcServer     *grpc.Server
+	webhookService service.WebhookService
+	bus            events.Bus
+	stopWorkers    context.CancelFunc
+}
+
+func NewServer(cfg *config.Config, mongodb *database.MongoDB, redis *database.Redis) *Server {
+	indexCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.MongoDB.Timeout)*time.Second)
+	defer cancel()
+	if err := repository.EnsureIndexes(indexCtx, mongodb); err != nil {
+		logger.Fatalf("Failed to create webhook indexes: %v", err)
+	}
+
+	// Initialize repositories
+	endpointRepo := repository.NewMongoEndpointRepository(mongodb)
+	deliveryRepo := repository.NewMongoDeliveryRepository(mongodb)
+
+	// Initialize service
+	retry := events.RetryPolicy{
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+		Backoff:     retryBackoff,
+		MaxBackoff:  maxRetryBackoff,
+	}
+	webhookService := service.NewWebhookService(endpointRepo, deliveryRepo, time.Duration(cfg.Webhook.Timeout)*time.Second, retry, cfg.Webhook.DisableAfter)
+
+	// Initialize gRPC server
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestmeta.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(requestmeta.StreamServerInterceptor()),
+	)
+
+	// Register handlers
+	webhookHandler := handler.NewWebhookGRPCHandler(webhookService)
+	webhook.RegisterWebhookServiceServer(grpcServer, webhookHandler)
+
+	// Enable reflection for grpcurl/grpc clients
+	reflection.Register(grpcServer)
+
+	return &Server{
+		config:         cfg,
+		grpcServer:     grpcServer,
+		webhookService: webhookService,
+		bus:            events.NewBus(cfg.Events, redis),
+	}
+}
+
+func (s *Server) Start() error {
+	port := s.config.Services.Webhook.Port
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", port, err)
+	}
+
+	logger.Infof("Webhook service starting on port %s", port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWorkers = cancel
+	go s.dispatchDeliveries(ctx)
+	go events.Run(requestmeta.WithActor(ctx, "system:events"), s.bus, s.subscriptions()...)
+
+	if err := s.grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("failed to serve gRPC server: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) Stop() {
+	logger.Info("Shutting down Webhook service...")
+	if s.stopWorkers != nil {
+		s.stopWorkers()
+	}
+	s.grpcServer.GracefulStop()
+}
+
+// subscriptions declares the domain events the webhook service delivers
+func (s *Server) subscriptions() []events.Subscription {
+	enqueue := func(ctx context.Context, event *events.Event) error {
+		_, err := s.webhookService.Enqueue(ctx, event)
+		return err
+	}
+
+	return []events.Subscription{
+		{
+			Group: eventGroup,
+			Handlers: map[string]events.Handler{
+				"user.*":    enqueue,
+				"product.*": enqueue,
+			},
+		},
+	}
+}
+
+// dispatchDeliveries attempts the deliveries that are due until ctx is
+// cancelled
+func (s *Server) dispatchDeliveries(ctx context.Context) {
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep going while full batches are due, so a backlog drains
+			// faster than one batch per tick
+			for ctx.Err() == nil {
+				attempted, err := s.webhookService.DispatchDeliveries(ctx, dispatchBatch)
+				if err != nil {
+					logger.Errorf("Failed to dispatch webhook deliveries: %v", err)
+				}
+				if attempted < dispatchBatch {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/services/webhook/model"
+	"go-microservice-boilerplate/internal/utils/pagination"
+)
+
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, req *model.CreateEndpointRequest) (*model.Endpoint, error)
+	GetEndpoint(ctx context.Context, id string) (*model.Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]*model.Endpoint, error)
+	UpdateEndpoint(ctx context.Context, id string, req *model.UpdateEndpointRequest) (*model.Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, params *model.ListDeliveriesParams) ([]*model.Delivery, *pagination.PageInfo, error)
+	// ReplayDelivery delivers the event of a finished delivery again
+	ReplayDelivery(ctx context.Context, endpointID, deliveryID string) (*model.Delivery, error)
+	// Enqueue schedules the delivery of an event to every endpoint
+	// subscribed to it, returning how many deliveries it scheduled
+	Enqueue(ctx context.Context, event *events.Event) (int, error)
+	// DispatchDeliveries attempts up to limit deliveries that are due,
+	// returning how many it attempted
+	DispatchDeliveries(ctx context.Context, limit int) (int, error)
+}
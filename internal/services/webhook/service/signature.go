@@ -0,0 +1,30 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers of webhook requests
+const (
+	// SignatureHeader carries "sha256=" and the hex encoded HMAC-SHA256 of
+	// the timestamp, a dot and the body, keyed with the endpoint secret
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader carries the Unix time the request was signed at.
+	// Receivers should reject requests signed long ago.
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Sign returns the signature header value of a request body signed at the
+// given Unix time
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go-microservice-boilerplate/internal/events"
+	"go-microservice-boilerplate/internal/services/webhook/model"
+	"go-microservice-boilerplate/internal/services/webhook/repository"
+	"go-microservice-boilerplate/internal/utils/logger"
+	"go-microservice-boilerplate/internal/utils/pagination"
+	apperrors "go-microservice-boilerplate/pkg/errors"
+)
+
+const (
+	// minSecretLength is the shortest secret an endpoint may be given
+	minSecretLength = 16
+	// maxErrorLength bounds the error text kept per attempt
+	maxErrorLength = 512
+)
+
+type webhookService struct {
+	endpoints    repository.EndpointRepository
+	deliveries   repository.DeliveryRepository
+	client       *http.Client
+	retry        events.RetryPolicy
+	disableAfter int
+}
+
+// NewWebhookService creates a webhook service. Requests time out after
+// timeout, failed deliveries are retried following retry, and endpoints are
+// disabled once disableAfter deliveries in a row failed, never when it is
+// zero.
+func NewWebhookService(endpoints repository.EndpointRepository, deliveries repository.DeliveryRepository, timeout time.Duration, retry events.RetryPolicy, disableAfter int) WebhookService {
+	return &webhookService{
+		endpoints:  endpoints,
+		deliveries: deliveries,
+		client: &http.Client{
+			Timeout: timeout,
+			// A signed event is never forwarded to another URL
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		retry:        retry,
+		disableAfter: disableAfter,
+	}
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, req *model.CreateEndpointRequest) (*model.Endpoint, error) {
+	endpoint := &model.Endpoint{
+		URL:         strings.TrimSpace(req.URL),
+		Secret:      req.Secret,
+		Description: strings.TrimSpace(req.Description),
+		Enabled:     true,
+	}
+	if err := validateURL(endpoint.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.EventTypes = eventTypes
+
+	if endpoint.Secret == "" {
+		if endpoint.Secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	} else if len(endpoint.Secret) < minSecretLength {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("secret must have at least %d characters", minSecretLength))
+	}
+
+	if err := s.endpoints.Create(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+func (s *webhookService) GetEndpoint(ctx context.Context, id string) (*model.Endpoint, error) {
+	if !primitive.IsValidObjectID(id) {
+		return nil, model.ErrEndpointNotFound
+	}
+
+	endpoint, err := s.endpoints.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	if endpoint == nil {
+		return nil, model.ErrEndpointNotFound
+	}
+
+	return endpoint, nil
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context) ([]*model.Endpoint, error) {
+	endpoints, err := s.endpoints.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+func (s *webhookService) UpdateEndpoint(ctx context.Context, id string, req *model.UpdateEndpointRequest) (*model.Endpoint, error) {
+	endpoint, err := s.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		endpoint.URL = strings.TrimSpace(*req.URL)
+		if err := validateURL(endpoint.URL); err != nil {
+			return nil, err
+		}
+	}
+	if req.Secret != nil {
+		if len(*req.Secret) < minSecretLength {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("secret must have at least %d characters", minSecretLength))
+		}
+		endpoint.Secret = *req.Secret
+	}
+	if req.EventTypes != nil {
+		if endpoint.EventTypes, err = normalizeEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		endpoint.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Enabled != nil && *req.Enabled != endpoint.Enabled {
+		endpoint.Enabled = *req.Enabled
+		endpoint.DisabledReason = ""
+		if endpoint.Enabled {
+			endpoint.DisabledAt = nil
+			endpoint.ConsecutiveFailures = 0
+		} else {
+			now := time.Now()
+			endpoint.DisabledAt = &now
+		}
+	}
+
+	if err := s.endpoints.Update(ctx, endpoint); err != nil {
+		if errors.Is(err, model.ErrEndpointNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// DeleteEndpoint removes an endpoint. Its delivery log is kept until it
+// expires; pending deliveries fail on their next attempt.
+func (s *webhookService) DeleteEndpoint(ctx context.Context, id string) error {
+	if !primitive.IsValidObjectID(id) {
+		return model.ErrEndpointNotFound
+	}
+
+	deleted, err := s.endpoints.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if !deleted {
+		return model.ErrEndpointNotFound
+	}
+
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, params *model.ListDeliveriesParams) ([]*model.Delivery, *pagination.PageInfo, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.Limit > pagination.MaxLimit {
+		return nil, nil, apperrors.ErrInvalidInput(fmt.Sprintf("limit cannot exceed %d", pagination.MaxLimit))
+	}
+	if params.Status != "" && !model.ValidDeliveryStatus(params.Status) {
+		return nil, nil, apperrors.ErrInvalidInput(fmt.Sprintf("unsupported status %q", params.Status))
+	}
+	if _, err := s.GetEndpoint(ctx, params.EndpointID); err != nil {
+		return nil, nil, err
+	}
+
+	deliveries, pageInfo, err := s.deliveries.List(ctx, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, pageInfo, nil
+}
+
+func (s *webhookService) ReplayDelivery(ctx context.Context, endpointID, deliveryID string) (*model.Delivery, error) {
+	endpoint, err := s.GetEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.Enabled {
+		return nil, model.ErrEndpointDisabled
+	}
+
+	if !primitive.IsValidObjectID(deliveryID) {
+		return nil, model.ErrDeliveryNotFound
+	}
+	original, err := s.deliveries.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if original == nil || original.EndpointID != endpointID {
+		return nil, model.ErrDeliveryNotFound
+	}
+	if original.Status == model.DeliveryPending {
+		return nil, model.ErrDeliveryPending
+	}
+
+	now := time.Now()
+	replay := &model.Delivery{
+		EndpointID:    endpointID,
+		Event:         original.Event,
+		Status:        model.DeliveryPending,
+		Attempts:      []model.DeliveryAttempt{},
+		ReplayOf:      original.ID.Hex(),
+		NextAttemptAt: &now,
+	}
+	if err := s.deliveries.Create(ctx, replay); err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return replay, nil
+}
+
+func (s *webhookService) Enqueue(ctx context.Context, event *events.Event) (int, error) {
+	if !model.Deliverable(event.Type) {
+		return 0, nil
+	}
+
+	endpoints, err := s.endpoints.ListSubscribed(ctx, model.Subscriptions(event.Type))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list subscribed webhook endpoints: %w", err)
+	}
+
+	scheduled := 0
+	now := time.Now()
+	for _, endpoint := range endpoints {
+		err := s.deliveries.Create(ctx, &model.Delivery{
+			EndpointID:    endpoint.ID.Hex(),
+			Event:         event,
+			Status:        model.DeliveryPending,
+			Attempts:      []model.DeliveryAttempt{},
+			NextAttemptAt: &now,
+		})
+		// The event was handed over before and is scheduled already
+		if errors.Is(err, model.ErrDeliveryExists) {
+			continue
+		}
+		if err != nil {
+			return scheduled, fmt.Errorf("failed to schedule delivery of event %s: %w", event.ID, err)
+		}
+		scheduled++
+	}
+
+	return scheduled, nil
+}
+
+func (s *webhookService) DispatchDeliveries(ctx context.Context, limit int) (int, error) {
+	// A claimed delivery is not claimed again before its request timed out
+	lease := s.client.Timeout + time.Minute
+
+	var wg sync.WaitGroup
+	claimed := 0
+	for claimed < limit {
+		delivery, err := s.deliveries.ClaimDue(ctx, lease)
+		if err != nil {
+			wg.Wait()
+			return claimed, fmt.Errorf("failed to claim webhook delivery: %w", err)
+		}
+		if delivery == nil {
+			break
+		}
+		claimed++
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.deliver(ctx, delivery); err != nil {
+				logger.Errorf("Failed to record webhook delivery %s: %v", delivery.ID.Hex(), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return claimed, nil
+}
+
+// deliver makes one attempt of a delivery and records its outcome
+func (s *webhookService) deliver(ctx context.Context, delivery *model.Delivery) error {
+	endpoint, err := s.endpoints.GetByID(ctx, delivery.EndpointID)
+	if err != nil {
+		return err
+	}
+
+	// Deliveries of endpoints gone or disabled meanwhile fail at once,
+	// without counting against the endpoint
+	now := time.Now()
+	if endpoint == nil || !endpoint.Enabled {
+		reason := "endpoint was deleted"
+		if endpoint != nil {
+			reason = "endpoint is disabled"
+		}
+		delivery.Attempts = append(delivery.Attempts, model.DeliveryAttempt{AttemptedAt: now, Error: reason})
+		delivery.Status = model.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		return s.deliveries.Save(ctx, delivery)
+	}
+
+	attempt := s.send(ctx, endpoint, delivery)
+	delivery.Attempts = append(delivery.Attempts, attempt)
+
+	if attempt.Error == "" {
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		if err := s.deliveries.Save(ctx, delivery); err != nil {
+			return err
+		}
+		return s.endpoints.RecordSuccess(ctx, delivery.EndpointID)
+	}
+
+	if len(delivery.Attempts) < s.retry.MaxAttempts {
+		next := time.Now().Add(s.retry.Delay(len(delivery.Attempts)))
+		delivery.NextAttemptAt = &next
+		return s.deliveries.Save(ctx, delivery)
+	}
+
+	logger.Warnf("Webhook delivery %s to %s failed after %d attempts: %s", delivery.ID.Hex(), endpoint.URL, len(delivery.Attempts), attempt.Error)
+	delivery.Status = model.DeliveryFailed
+	delivery.NextAttemptAt = nil
+	if err := s.deliveries.Save(ctx, delivery); err != nil {
+		return err
+	}
+
+	disabled, err := s.endpoints.RecordFailure(ctx, delivery.EndpointID, s.disableAfter)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		logger.Warnf("Disabled webhook endpoint %s after %d failed deliveries in a row", endpoint.ID.Hex(), s.disableAfter)
+	}
+
+	return nil
+}
+
+// send POSTs the event of a delivery to the endpoint, signed with its secret
+func (s *webhookService) send(ctx context.Context, endpoint *model.Endpoint, delivery *model.Delivery) model.DeliveryAttempt {
+	attempt := model.DeliveryAttempt{AttemptedAt: time.Now()}
+	fail := func(err error) model.DeliveryAttempt {
+		attempt.Error = err.Error()
+		if len(attempt.Error) > maxErrorLength {
+			attempt.Error = attempt.Error[:maxErrorLength]
+		}
+		attempt.Duration = time.Since(attempt.AttemptedAt)
+		return attempt
+	}
+
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return fail(fmt.Errorf("failed to marshal event: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return fail(fmt.Errorf("failed to create request: %w", err))
+	}
+	timestamp := attempt.AttemptedAt.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event.Type)
+	req.Header.Set(DeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+	// Reading some of the body lets the connection be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fail(fmt.Errorf("endpoint responded with status %d", resp.StatusCode))
+	}
+
+	attempt.Duration = time.Since(attempt.AttemptedAt)
+	return attempt
+}
+
+// validateURL accepts absolute http and https URLs
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return apperrors.ErrInvalidInput(fmt.Sprintf("invalid webhook URL %q, expected an http or https URL", raw))
+	}
+	return nil
+}
+
+// normalizeEventTypes validates the event types of an endpoint, dropping
+// duplicates
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, apperrors.ErrInvalidInput("an endpoint needs at least one event type")
+	}
+	if len(eventTypes) > model.MaxEventTypes {
+		return nil, apperrors.ErrInvalidInput(fmt.Sprintf("an endpoint can have at most %d event types", model.MaxEventTypes))
+	}
+
+	normalized := make([]string, 0, len(eventTypes))
+	seen := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !model.ValidEventType(eventType) {
+			return nil, apperrors.ErrInvalidInput(fmt.Sprintf("unsupported event type %q", eventType))
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			normalized = append(normalized, eventType)
+		}
+	}
+
+	return normalized, nil
+}
+
+// generateSecret returns a random signing secret
+func generateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,137 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EstimateCap bounds the number of documents counted for an estimated total
+// on a filtered query. Totals at the cap are reported as estimates.
+const EstimateCap = 10000
+
+// MaxLimit caps the page size of list endpoints. Larger result sets are
+// exported instead, which streams them without holding a page in memory.
+const MaxLimit = 100
+
+// MaxBatchIDs caps how many IDs one batch get may ask for
+const MaxBatchIDs = 100
+
+// ExportBatchSize is how many documents an export fetches per round trip
+const ExportBatchSize = 500
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// TotalMode controls how the total number of matching documents is computed.
+// Its values mirror common.TotalMode so the proto enum converts directly.
+type TotalMode int
+
+const (
+	// TotalAuto counts exactly for offset pages and skips the count for cursor pages
+	TotalAuto TotalMode = iota
+	// TotalExact always runs an exact count
+	TotalExact
+	// TotalEstimated uses collection metadata or a capped count
+	TotalEstimated
+	// TotalNone skips the count entirely
+	TotalNone
+)
+
+// PageInfo describes where a page sits within the full result set
+type PageInfo struct {
+	Total          *int64
+	TotalEstimated bool
+	NextPageToken  string
+}
+
+// Cursor identifies the last document of a page for keyset pagination.
+// Sort names the ordering the token was issued for, Value holds the sort key
+// of that document and ID breaks ties between documents sharing the same key.
+type Cursor struct {
+	Sort  string             `bson:"s"`
+	Value interface{}        `bson:"v"`
+	ID    primitive.ObjectID `bson:"i"`
+}
+
+// EncodeCursor builds an opaque page token from a sort key and document ID
+func EncodeCursor(sort string, value interface{}, id primitive.ObjectID) (string, error) {
+	data, err := bson.Marshal(Cursor{Sort: sort, Value: value, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor parses a page token produced by EncodeCursor. Tokens issued
+// for a different ordering are rejected.
+func DecodeCursor(token, sort string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	var cursor Cursor
+	if err := bson.Unmarshal(data, &cursor); err != nil || cursor.ID.IsZero() || cursor.Sort != sort {
+		return nil, ErrInvalidPageToken
+	}
+
+	return &cursor, nil
+}
+
+// After returns a filter matching documents that come after the cursor when
+// sorting by field and then by _id in the same direction.
+func (c *Cursor) After(field string, descending bool) bson.M {
+	op := "$gt"
+	if descending {
+		op = "$lt"
+	}
+
+	return bson.M{
+		"$or": []bson.M{
+			{field: bson.M{op: c.Value}},
+			{field: c.Value, "_id": bson.M{op: c.ID}},
+		},
+	}
+}
+
+// Count computes the total for a listing according to mode. The returned
+// total is nil when the count was skipped.
+func Count(ctx context.Context, collection *mongo.Collection, filter bson.M, mode TotalMode, cursorPage bool) (*int64, bool, error) {
+	if mode == TotalAuto {
+		mode = TotalExact
+		if cursorPage {
+			mode = TotalNone
+		}
+	}
+
+	switch mode {
+	case TotalNone:
+		return nil, false, nil
+	case TotalEstimated:
+		if len(filter) == 0 {
+			total, err := collection.EstimatedDocumentCount(ctx)
+			if err != nil {
+				return nil, false, err
+			}
+			return &total, true, nil
+		}
+
+		total, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(EstimateCap))
+		if err != nil {
+			return nil, false, err
+		}
+		return &total, total >= EstimateCap, nil
+	default:
+		total, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, false, err
+		}
+		return &total, false, nil
+	}
+}
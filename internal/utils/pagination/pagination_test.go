@@ -0,0 +1,112 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := primitive.NewObjectID()
+	createdAt := primitive.NewDateTimeFromTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name  string
+		sort  string
+		value interface{}
+	}{
+		{name: "string", sort: "name", value: "Keyboard"},
+		{name: "int64", sort: "price", value: int64(1999)},
+		{name: "date", sort: "created_at", value: createdAt},
+		{name: "nil", sort: "category", value: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := EncodeCursor(tt.sort, tt.value, id)
+			if err != nil {
+				t.Fatalf("EncodeCursor() error = %v", err)
+			}
+
+			cursor, err := DecodeCursor(token, tt.sort)
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			if cursor.ID != id {
+				t.Errorf("ID = %v, want %v", cursor.ID, id)
+			}
+			if cursor.Sort != tt.sort {
+				t.Errorf("Sort = %q, want %q", cursor.Sort, tt.sort)
+			}
+			if cursor.Value != tt.value {
+				t.Errorf("Value = %#v, want %#v", cursor.Value, tt.value)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsInvalidTokens(t *testing.T) {
+	valid, err := EncodeCursor("name", "Keyboard", primitive.NewObjectID())
+	if err != nil {
+		t.Fatalf("EncodeCursor() error = %v", err)
+	}
+
+	withoutID, err := bson.Marshal(Cursor{Sort: "name", Value: "Keyboard"})
+	if err != nil {
+		t.Fatalf("bson.Marshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		sort  string
+	}{
+		{name: "not base64", token: "not a token!", sort: "name"},
+		{name: "not bson", token: base64.RawURLEncoding.EncodeToString([]byte("garbage")), sort: "name"},
+		{name: "missing id", token: base64.RawURLEncoding.EncodeToString(withoutID), sort: "name"},
+		{name: "other sort", token: valid, sort: "price"},
+		{name: "empty", token: "", sort: "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.token, tt.sort); !errors.Is(err, ErrInvalidPageToken) {
+				t.Errorf("DecodeCursor() error = %v, want %v", err, ErrInvalidPageToken)
+			}
+		})
+	}
+}
+
+func TestCursorAfter(t *testing.T) {
+	id := primitive.NewObjectID()
+	cursor := &Cursor{Sort: "name", Value: "Keyboard", ID: id}
+
+	tests := []struct {
+		name       string
+		descending bool
+		op         string
+	}{
+		{name: "ascending", descending: false, op: "$gt"},
+		{name: "descending", descending: true, op: "$lt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := cursor.After("name", tt.descending)
+			or, ok := filter["$or"].([]bson.M)
+			if !ok || len(or) != 2 {
+				t.Fatalf("After() = %v, want an $or of two conditions", filter)
+			}
+			if got := or[0]["name"].(bson.M)[tt.op]; got != "Keyboard" {
+				t.Errorf("first condition = %v, want name %s Keyboard", or[0], tt.op)
+			}
+			if or[1]["name"] != "Keyboard" || or[1]["_id"].(bson.M)[tt.op] != id {
+				t.Errorf("tie-breaker = %v, want name = Keyboard and _id %s %v", or[1], tt.op, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package requestmeta
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// HTTP headers accepted and returned by the gateway
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	ActorHeader         = "X-Actor"
+)
+
+// gRPC metadata keys carrying the same values between services
+const (
+	correlationIDKey = "x-correlation-id"
+	actorKey         = "x-actor"
+)
+
+type contextKey int
+
+const (
+	correlationIDContextKey contextKey = iota
+	actorContextKey
+)
+
+// WithCorrelationID returns a context carrying the ID that ties together
+// every change made while serving one request
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey, id)
+}
+
+// CorrelationID returns the correlation ID of ctx, if any
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDContextKey).(string)
+	return id
+}
+
+// WithActor returns a context carrying who or what initiated the request
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey, actor)
+}
+
+// Actor returns the actor of ctx, if any
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey).(string)
+	return actor
+}
+
+// UnaryClientInterceptor forwards the request metadata of ctx to the called service
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor forwards the request metadata of ctx to the called service
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerInterceptor restores the request metadata sent by the caller
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(incoming(ctx), req)
+	}
+}
+
+// StreamServerInterceptor restores the request metadata sent by the caller
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: incoming(ss.Context())})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func outgoing(ctx context.Context) context.Context {
+	if id := CorrelationID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, correlationIDKey, id)
+	}
+	if actor := Actor(ctx); actor != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, actorKey, actor)
+	}
+	return ctx
+}
+
+func incoming(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	if values := md.Get(correlationIDKey); len(values) > 0 {
+		ctx = WithCorrelationID(ctx, values[0])
+	}
+	if values := md.Get(actorKey); len(values) > 0 {
+		ctx = WithActor(ctx, values[0])
+	}
+	return ctx
+}
@@ -56,3 +56,13 @@ func ValidatePassword(password string) error {
 	}
 	return nil
 }
+
+func ValidateSKU(sku string) error {
+	if sku == "" {
+		return fmt.Errorf("sku is required")
+	}
+	if !skuRegex.MatchString(sku) {
+		return fmt.Errorf("sku must be 3 to 20 uppercase letters or digits")
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is assumed wherever a currency is not given
+const DefaultCurrency = "USD"
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrNegativeAmount      = errors.New("amount cannot be negative")
+	ErrCurrencyMismatch    = errors.New("currencies do not match")
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrOverflow            = errors.New("amount out of range")
+	ErrInvalidRate         = errors.New("invalid exchange rate")
+)
+
+// currencies maps the supported ISO 4217 codes to the number of minor units
+// in one major unit, expressed as a power of ten
+var currencies = map[string]int{
+	"AUD": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"INR": 2,
+	"JPY": 0,
+	"SGD": 2,
+	"USD": 2,
+}
+
+// Money is an amount in the minor units of its currency, e.g. cents for USD.
+// Integer amounts keep totals exact where floating point prices would drift.
+type Money struct {
+	Amount   int64  `bson:"amount" json:"amount"`
+	Currency string `bson:"currency" json:"currency"`
+}
+
+// New returns an amount of minor units in the given currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: NormalizeCurrency(currency)}
+}
+
+// NormalizeCurrency upper-cases and trims a currency code
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// Supported reports whether currency is one of the accepted ISO codes
+func Supported(currency string) bool {
+	_, ok := currencies[NormalizeCurrency(currency)]
+	return ok
+}
+
+// Exponent returns the number of decimal places of currency
+func Exponent(currency string) (int, error) {
+	exponent, ok := currencies[NormalizeCurrency(currency)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, currency)
+	}
+	return exponent, nil
+}
+
+// FromFloat converts a major unit amount, rounding half away from zero to the
+// nearest minor unit. It exists to migrate legacy float prices.
+func FromFloat(amount float64, currency string) (Money, error) {
+	exponent, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return round(amount*math.Pow10(exponent), currency)
+}
+
+// Parse reads a decimal major unit amount such as "12.34" without going
+// through floating point. More decimals than the currency allows are rejected.
+func Parse(value, currency string) (Money, error) {
+	exponent, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" || len(fraction) > exponent || !digits(whole) || !digits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return New(amount, currency), nil
+}
+
+// Validate rejects unsupported currencies and negative amounts
+func (m Money) Validate() error {
+	if !Supported(m.Currency) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedCurrency, m.Currency)
+	}
+	if m.Amount < 0 {
+		return ErrNegativeAmount
+	}
+	return nil
+}
+
+// Add returns the sum of two amounts in the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	sum := m.Amount + other.Amount
+	// Adding two amounts of the same sign cannot change it
+	if (m.Amount >= 0) == (other.Amount >= 0) && (sum >= 0) != (m.Amount >= 0) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, other)
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Multiply returns the amount times a whole quantity, failing when the
+// product does not fit in an int64
+func (m Money) Multiply(quantity int64) (Money, error) {
+	product := m.Amount * quantity
+	if quantity != 0 && (product/quantity != m.Amount || (quantity == -1 && m.Amount == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: %s * %d", ErrOverflow, m, quantity)
+	}
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// Convert returns the amount in another currency, given how many units of
+// that currency one unit of m's currency buys. The result is rounded half
+// away from zero to the nearest minor unit.
+func (m Money) Convert(currency string, rate float64) (Money, error) {
+	from, err := Exponent(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	to, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return Money{}, fmt.Errorf("%w %v", ErrInvalidRate, rate)
+	}
+
+	return round(float64(m.Amount)*rate*math.Pow10(to-from), currency)
+}
+
+// round rounds an amount of minor units half away from zero, failing with
+// ErrOverflow when it does not fit
+func round(amount float64, currency string) (Money, error) {
+	rounded := math.Round(amount)
+	// float64(math.MaxInt64) is 2^63, one past the largest amount
+	if math.IsNaN(rounded) || rounded >= math.MaxInt64 || rounded < math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %v %s in minor units", ErrOverflow, amount, currency)
+	}
+	return New(int64(rounded), currency), nil
+}
+
+// digits reports whether s holds only decimal digits
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsZero reports whether the money is unset
+func (m Money) IsZero() bool {
+	return m.Amount == 0 && m.Currency == ""
+}
+
+// String formats the amount in major units followed by the currency code,
+// e.g. "12.34 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Decimal formats the amount in major units without the currency code, e.g.
+// "12.34", the inverse of Parse
+func (m Money) Decimal() string {
+	exponent, err := Exponent(m.Currency)
+	if err != nil || exponent == 0 {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	unit := int64(math.Pow10(exponent))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, exponent, amount%unit)
+}
@@ -0,0 +1,216 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{name: "whole", value: "12", currency: "USD", want: New(1200, "USD")},
+		{name: "cents", value: "12.34", currency: "USD", want: New(1234, "USD")},
+		{name: "one decimal", value: "12.5", currency: "EUR", want: New(1250, "EUR")},
+		{name: "trailing dot", value: "12.", currency: "USD", want: New(1200, "USD")},
+		{name: "negative", value: "-0.05", currency: "USD", want: New(-5, "USD")},
+		{name: "spaces and lower case currency", value: " 7.10 ", currency: "gbp", want: New(710, "GBP")},
+		{name: "no minor units", value: "500", currency: "JPY", want: New(500, "JPY")},
+		{name: "too many decimals", value: "1.234", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "decimals without minor units", value: "1.5", currency: "JPY", wantErr: ErrInvalidAmount},
+		{name: "missing whole part", value: ".50", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "not a number", value: "ten", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "two signs", value: "--5", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "plus sign", value: "+5", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "sign after the minus", value: "-+5", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "signed fraction", value: "1.-5", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "digit separators", value: "1_000", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "empty", value: "", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "out of range", value: "999999999999999999", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "unsupported currency", value: "1.00", currency: "XYZ", wantErr: ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q, %q) error = %v, want %v", tt.value, tt.currency, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q, %q) = %v, want %v", tt.value, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: New(1234, "USD"), want: "12.34"},
+		{money: New(5, "USD"), want: "0.05"},
+		{money: New(-1205, "EUR"), want: "-12.05"},
+		{money: New(500, "JPY"), want: "500"},
+		{money: New(0, "USD"), want: "0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.Decimal(); got != tt.want {
+				t.Errorf("Decimal() = %q, want %q", got, tt.want)
+			}
+			// Decimal is the inverse of Parse
+			parsed, err := Parse(tt.money.Decimal(), tt.money.Currency)
+			if err != nil || parsed != tt.money {
+				t.Errorf("Parse(Decimal()) = %v, %v, want %v", parsed, err, tt.money)
+			}
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   float64
+		currency string
+		want     Money
+	}{
+		{name: "exact", amount: 19.99, currency: "USD", want: New(1999, "USD")},
+		{name: "half rounds up", amount: 0.125, currency: "USD", want: New(13, "USD")},
+		{name: "negative half rounds away from zero", amount: -0.125, currency: "USD", want: New(-13, "USD")},
+		{name: "below half rounds down", amount: 2.344, currency: "EUR", want: New(234, "EUR")},
+		{name: "no minor units", amount: 99.5, currency: "JPY", want: New(100, "JPY")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromFloat(tt.amount, tt.currency)
+			if err != nil {
+				t.Fatalf("FromFloat(%v, %q) error = %v", tt.amount, tt.currency, err)
+			}
+			if got != tt.want {
+				t.Errorf("FromFloat(%v, %q) = %v, want %v", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		currency string
+		rate     float64
+		want     Money
+		wantErr  error
+	}{
+		{name: "same exponent", money: New(1000, "USD"), currency: "EUR", rate: 0.92, want: New(920, "EUR")},
+		{name: "rounds half away from zero", money: New(1, "USD"), currency: "EUR", rate: 0.5, want: New(1, "EUR")},
+		{name: "to no minor units", money: New(1000, "USD"), currency: "JPY", rate: 150.456, want: New(1505, "JPY")},
+		{name: "from no minor units", money: New(1500, "JPY"), currency: "USD", rate: 0.0067, want: New(1005, "USD")},
+		{name: "zero rate", money: New(1000, "USD"), currency: "EUR", rate: 0, wantErr: ErrInvalidRate},
+		{name: "infinite rate", money: New(1000, "USD"), currency: "EUR", rate: math.Inf(1), wantErr: ErrInvalidRate},
+		{name: "unsupported currency", money: New(1000, "USD"), currency: "XYZ", rate: 1, wantErr: ErrUnsupportedCurrency},
+		{name: "overflow", money: New(math.MaxInt64/2, "USD"), currency: "EUR", rate: 4, wantErr: ErrOverflow},
+		{name: "overflow to more minor units", money: New(math.MaxInt64/10, "JPY"), currency: "USD", rate: 1000, wantErr: ErrOverflow},
+		{name: "negative overflow", money: New(math.MinInt64, "USD"), currency: "EUR", rate: 2, wantErr: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.money.Convert(tt.currency, tt.rate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Convert() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		want    Money
+		wantErr error
+	}{
+		{name: "sum", a: New(1250, "USD"), b: New(99, "USD"), want: New(1349, "USD")},
+		{name: "negative", a: New(100, "USD"), b: New(-250, "USD"), want: New(-150, "USD")},
+		{name: "currency mismatch", a: New(100, "USD"), b: New(100, "EUR"), wantErr: ErrCurrencyMismatch},
+		{name: "overflow", a: New(math.MaxInt64, "USD"), b: New(1, "USD"), wantErr: ErrOverflow},
+		{name: "negative overflow", a: New(math.MinInt64, "USD"), b: New(-1, "USD"), wantErr: ErrOverflow},
+		{name: "largest amount", a: New(math.MaxInt64-1, "USD"), b: New(1, "USD"), want: New(math.MaxInt64, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiply(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		quantity int64
+		want     Money
+		wantErr  error
+	}{
+		{name: "quantity", money: New(1999, "USD"), quantity: 3, want: New(5997, "USD")},
+		{name: "zero quantity", money: New(1999, "USD"), quantity: 0, want: New(0, "USD")},
+		{name: "negative quantity", money: New(1999, "USD"), quantity: -2, want: New(-3998, "USD")},
+		{name: "overflow", money: New(math.MaxInt64/2+1, "USD"), quantity: 2, wantErr: ErrOverflow},
+		{name: "large quantity overflow", money: New(1000, "USD"), quantity: math.MaxInt64 / 10, wantErr: ErrOverflow},
+		{name: "negating the smallest amount", money: New(math.MinInt64, "USD"), quantity: -1, wantErr: ErrOverflow},
+		{name: "largest product", money: New(math.MaxInt64/2, "USD"), quantity: 2, want: New(math.MaxInt64-1, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.money.Multiply(tt.quantity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Multiply(%d) error = %v, want %v", tt.quantity, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Multiply(%d) = %v, want %v", tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		wantErr error
+	}{
+		{name: "valid", money: New(100, "usd")},
+		{name: "zero", money: New(0, "JPY")},
+		{name: "negative", money: New(-1, "USD"), wantErr: ErrNegativeAmount},
+		{name: "unsupported currency", money: New(100, "XYZ"), wantErr: ErrUnsupportedCurrency},
+		{name: "missing currency", money: Money{Amount: 100}, wantErr: ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.money.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -42,4 +42,20 @@ protoc --go_out=. --go-grpc_out=. \
   -I. \
   internal/proto/product/product.proto
 
+# Generate order proto files
+echo "Generating order protobuf files..."
+protoc --go_out=. --go-grpc_out=. \
+  --go_opt=paths=source_relative \
+  --go-grpc_opt=paths=source_relative \
+  -I. \
+  internal/proto/order/order.proto
+
+# Generate webhook proto files
+echo "Generating webhook protobuf files..."
+protoc --go_out=. --go-grpc_out=. \
+  --go_opt=paths=source_relative \
+  --go-grpc_opt=paths=source_relative \
+  -I. \
+  internal/proto/webhook/webhook.proto
+
 echo "Protobuf generation complete!"
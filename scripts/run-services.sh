@@ -20,6 +20,10 @@ run_service "user" "50051"
 sleep 2
 run_service "product" "50052"
 sleep 2
+run_service "order" "50053"
+sleep 2
+run_service "webhook" "50054"
+sleep 2
 run_service "web" "8080"
 
 echo "All services started!"
@@ -27,5 +31,7 @@ echo "Gateway API: http://localhost:8080"
 echo "Gateway Swagger: http://localhost:8080/swagger/index.html#"
 echo "User gRPC: localhost:50051"
 echo "Product gRPC: localhost:50052"
+echo "Order gRPC: localhost:50053"
+echo "Webhook gRPC: localhost:50054"
 
 echo "To stop services, run: scripts/stop-services.sh"
\ No newline at end of file
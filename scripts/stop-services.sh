@@ -3,7 +3,7 @@
 echo "Stopping microservices..."
 
 # Stop services using PID files
-for service in user product web; do
+for service in user product order webhook web; do
     if [ -f "${service}_service.pid" ]; then
         pid=$(cat "${service}_service.pid")
         if kill -0 $pid 2>/dev/null; then